@@ -4,7 +4,20 @@
 //go:generate godocdown -output=hue/README.md hue
 //go:generate godocdown -output=hue/client/README.md hue/client
 //go:generate godocdown -output=hue/lights/README.md hue/lights
+//go:generate godocdown -output=hue/groups/README.md hue/groups
+//go:generate godocdown -output=hue/scenes/README.md hue/scenes
+//go:generate godocdown -output=hue/schedules/README.md hue/schedules
+//go:generate godocdown -output=hue/sensors/README.md hue/sensors
+//go:generate godocdown -output=hue/rules/README.md hue/rules
 //go:generate godocdown -output=hue/message/README.md hue/message
+//go:generate godocdown -output=hue/ratelimit/README.md hue/ratelimit
+//go:generate godocdown -output=hue/clip/README.md hue/clip
+//go:generate godocdown -output=hue/retry/README.md hue/retry
+//go:generate godocdown -output=hue/entertainment/README.md hue/entertainment
+//go:generate godocdown -output=hue/middleware/README.md hue/middleware
+//go:generate godocdown -output=hue/cassette/README.md hue/cassette
+//go:generate godocdown -output=hue/dryrun/README.md hue/dryrun
+//go:generate godocdown -output=hue/logging/README.md hue/logging
 
 // Generate mocks.
 //go:generate go get github.com/golang/mock/mockgen
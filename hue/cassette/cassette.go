@@ -0,0 +1,112 @@
+// Package cassette records the requests sent through a hue.Client to a cassette, a JSON file, and replays them, so
+// tests can run against sessions recorded from a real bridge instead of the bridge itself. Bodies are stored as JSON
+// values and cassettes are indented, so changes to recorded traffic show up clearly in diffs.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+
+	"github.com/drombosky/disco-dance-party/hue/client"
+	"github.com/drombosky/disco-dance-party/hue/message"
+)
+
+// Cassette represents a recorded session.
+type Cassette struct {
+	// The requests in the order they were sent.
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction represents a request and its response.
+type Interaction struct {
+	// The HTTP method, e.g. PUT.
+	Method string `json:"method"`
+	// The address of the resource, e.g. /api/<username>/lights/1.
+	Address string `json:"address"`
+	// The body of the request, or null. Bodies that are not JSON are stored as a string.
+	Request json.RawMessage `json:"request"`
+	// The body of the response, or null if there was none.
+	Response json.RawMessage `json:"response"`
+	// The error returned for the request, or nil.
+	Error *Error `json:"error,omitempty"`
+}
+
+// Error represents a recorded error. Errors reported by the bridge are recorded with their type so they replay as the
+// same type; other errors replay with the same message.
+type Error struct {
+	// The text of the error.
+	Message string `json:"message"`
+	// The error, if it was a *message.APIError.
+	API *message.APIError `json:"api,omitempty"`
+	// The error, if it was a *client.ServiceError.
+	Service *client.ServiceError `json:"service,omitempty"`
+}
+
+// newError records err.
+func newError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	e := &Error{Message: err.Error()}
+	switch err := err.(type) {
+	case *message.APIError:
+		e.API = err
+	case *client.ServiceError:
+		e.Service = err
+	}
+	return e
+}
+
+// err returns the recorded error.
+func (e *Error) err() error {
+	switch {
+	case e == nil:
+		return nil
+	case e.API != nil:
+		return e.API
+	case e.Service != nil:
+		return e.Service
+	}
+	return errors.New(e.Message)
+}
+
+// encodeBody returns body as a JSON value, a string if it is not JSON itself.
+func encodeBody(body []byte) (raw json.RawMessage) {
+	if len(body) == 0 {
+		return nil
+	}
+	var value interface{}
+	if json.Unmarshal(body, &value) == nil {
+		return append(json.RawMessage{}, body...)
+	}
+	raw, _ = json.Marshal(string(body))
+	return raw
+}
+
+// Load reads a cassette from path.
+func Load(path string) (cassette *Cassette, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cassette = &Cassette{}
+	if err = json.Unmarshal(data, cassette); err != nil {
+		return nil, err
+	}
+	return cassette, nil
+}
+
+// Save writes the cassette to path as indented JSON.
+func (c *Cassette) Save(path string) (err error) {
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	// Addresses hold <username>, which would otherwise be escaped.
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err = encoder.Encode(c); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, buf.Bytes(), 0644)
+}
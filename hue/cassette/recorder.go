@@ -0,0 +1,56 @@
+package cassette
+
+import (
+	"sync"
+
+	"github.com/drombosky/disco-dance-party/hue"
+	"github.com/drombosky/disco-dance-party/hue/middleware"
+)
+
+// Recorder represents a recording of the requests sent through a hue.Client.
+type Recorder struct {
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder returns an empty recording.
+func NewRecorder() *Recorder {
+	return &Recorder{cassette: Cassette{Interactions: []Interaction{}}}
+}
+
+// Middleware returns a middleware that records every request passed through it.
+func (r *Recorder) Middleware() middleware.Middleware {
+	return middleware.Func(func(req *middleware.Request, next middleware.RoundTripper) (resp *middleware.Response) {
+		resp = next.RoundTrip(req)
+		i := Interaction{
+			Method:  req.Method,
+			Address: req.Address,
+			Request: encodeBody(req.Message),
+			// The body is recorded even when the request failed, since it may hold the results of a partially applied
+			// change.
+			Response: resp.Body,
+			Error:    newError(resp.Err),
+		}
+		r.mu.Lock()
+		r.cassette.Interactions = append(r.cassette.Interactions, i)
+		r.mu.Unlock()
+		return resp
+	})
+}
+
+// Record returns client wrapped in the middleware of the recorder.
+func (r *Recorder) Record(client hue.Client) hue.Client {
+	return r.Middleware()(client)
+}
+
+// Cassette returns a copy of what has been recorded so far.
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Cassette{Interactions: append([]Interaction{}, r.cassette.Interactions...)}
+}
+
+// Save writes what has been recorded so far to path.
+func (r *Recorder) Save(path string) (err error) {
+	return r.Cassette().Save(path)
+}
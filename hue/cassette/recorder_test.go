@@ -0,0 +1,39 @@
+package cassette_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/drombosky/disco-dance-party/hue/cassette"
+	"github.com/drombosky/disco-dance-party/hue/middleware"
+)
+
+func TestRecorderRecordsUndecodedResponses(t *testing.T) {
+	results := `[{"success":{"/lights/1/state/on":true}}]`
+	bridge := middleware.RoundTripperFunc(func(req *middleware.Request) *middleware.Response {
+		return &middleware.Response{Err: json.Unmarshal([]byte(results), req.Resp)}
+	})
+	recorder := cassette.NewRecorder()
+	client := recorder.Record(bridge)
+	if err := client.Do("PUT", "/api/<username>/lights/1/state", []byte(`{"on":true}`), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	interactions := recorder.Cassette().Interactions
+	if len(interactions) != 1 {
+		t.Fatalf("recorded %v interactions, want 1", len(interactions))
+	}
+	if string(interactions[0].Response) != results {
+		t.Errorf("recorded response %s, want %s", interactions[0].Response, results)
+	}
+
+	// The recorded response replays to callers that decode it.
+	replayer := cassette.NewReplayer(recorder.Cassette())
+	resp := []map[string]interface{}{}
+	if err := replayer.Do("PUT", "/api/<username>/lights/1/state", []byte(`{"on":true}`), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp) != 1 {
+		t.Errorf("Do() decoded %v, want one result", resp)
+	}
+}
@@ -0,0 +1,143 @@
+package cassette
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/drombosky/disco-dance-party/hue/middleware"
+)
+
+// NoMatchError represents an error when no recorded interaction matches a request.
+type NoMatchError struct {
+	Method  string
+	Address string
+	Message string
+}
+
+// Error satisfies the error interface.
+func (e *NoMatchError) Error() string {
+	return fmt.Sprintf("No recorded interaction matches %v %v %v", e.Method, e.Address, e.Message)
+}
+
+// Matcher represents a rule deciding whether a recorded interaction answers a request.
+type Matcher func(req *middleware.Request, i Interaction) bool
+
+// MatchMethod matches interactions with the same method.
+func MatchMethod(req *middleware.Request, i Interaction) bool {
+	return req.Method == i.Method
+}
+
+// MatchAddress matches interactions with the same address.
+func MatchAddress(req *middleware.Request, i Interaction) bool {
+	return req.Address == i.Address
+}
+
+// MatchBody matches interactions with an equal body. JSON bodies are compared by value, so formatting and the order
+// of object keys do not matter.
+func MatchBody(req *middleware.Request, i Interaction) bool {
+	recorded, sent := decodeBody(i.Request), decodeBody(encodeBody(req.Message))
+	return reflect.DeepEqual(recorded, sent)
+}
+
+// MatchAll returns a matcher that matches interactions matched by every one of matchers.
+func MatchAll(matchers ...Matcher) Matcher {
+	return func(req *middleware.Request, i Interaction) bool {
+		for _, m := range matchers {
+			if !m(req, i) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// DefaultMatcher matches interactions with the same method, address and body.
+var DefaultMatcher = MatchAll(MatchMethod, MatchAddress, MatchBody)
+
+// decodeBody decodes a recorded body for comparison.
+func decodeBody(raw json.RawMessage) (value interface{}) {
+	if len(raw) == 0 {
+		return nil
+	}
+	json.Unmarshal(raw, &value)
+	return value
+}
+
+// Replayer represents a hue.Client that answers requests from a cassette instead of a bridge.
+type Replayer struct {
+	// Cassette holds the interactions to replay.
+	Cassette *Cassette
+	// Match decides which interactions answer a request. Defaults to DefaultMatcher.
+	Match Matcher
+	// Repeat allows an interaction to answer more than one request. Otherwise each interaction is used once, in the
+	// order recorded, so a session that reads the same resource several times replays each read.
+	Repeat bool
+
+	mu   sync.Mutex
+	used map[int]bool
+}
+
+// NewReplayer returns a client replaying cassette with the default matcher, using each interaction once.
+func NewReplayer(cassette *Cassette) *Replayer {
+	return &Replayer{Cassette: cassette}
+}
+
+// Do answers a request with the first matching interaction that has not been used. The recorded response is decoded
+// into resp and the recorded error is returned. If no interaction matches a *NoMatchError is returned.
+func (r *Replayer) Do(method string, address string, message []byte, resp interface{}) (err error) {
+	return r.DoContext(context.Background(), method, address, message, resp)
+}
+
+// DoContext is like Do, but returns the error of ctx if it is done.
+func (r *Replayer) DoContext(ctx context.Context, method string, address string, message []byte,
+	resp interface{}) (err error) {
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+	i, ok := r.next(&middleware.Request{Context: ctx, Method: method, Address: address, Message: message, Resp: resp})
+	if !ok {
+		return &NoMatchError{Method: method, Address: address, Message: string(message)}
+	}
+	if resp != nil && len(i.Response) > 0 {
+		if err = json.Unmarshal(i.Response, resp); err != nil {
+			return err
+		}
+	}
+	return i.Error.err()
+}
+
+// Unused returns the interactions that have not answered a request, so tests can check that a session was replayed
+// completely.
+func (r *Replayer) Unused() (unused []Interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for n, i := range r.Cassette.Interactions {
+		if !r.used[n] {
+			unused = append(unused, i)
+		}
+	}
+	return unused
+}
+
+// next finds the interaction answering req and marks it used.
+func (r *Replayer) next(req *middleware.Request) (i Interaction, ok bool) {
+	match := r.Match
+	if match == nil {
+		match = DefaultMatcher
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.used == nil {
+		r.used = map[int]bool{}
+	}
+	for n, i := range r.Cassette.Interactions {
+		if (r.Repeat || !r.used[n]) && match(req, i) {
+			r.used[n] = true
+			return i, true
+		}
+	}
+	return Interaction{}, false
+}
@@ -0,0 +1,136 @@
+package cassette_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/drombosky/disco-dance-party/hue/cassette"
+	"github.com/drombosky/disco-dance-party/hue/message"
+	"github.com/drombosky/disco-dance-party/hue/middleware"
+)
+
+// notFound is the error the bridge returns for a light that does not exist.
+var notFound = &message.APIError{Type: 3, Address: "/lights/9", Description: "resource, /lights/9, not available"}
+
+// record records a session against a bridge that names the light differently each time it is read, and replays it
+// after saving and loading it again.
+func record(t *testing.T) *cassette.Replayer {
+	reads := 0
+	bridge := middleware.RoundTripperFunc(func(req *middleware.Request) *middleware.Response {
+		switch {
+		case req.Method == "GET":
+			reads++
+			lights := map[string]message.Light{"1": {Name: "Lamp " + strconv.Itoa(reads)}}
+			return &middleware.Response{Err: remarshal(lights, req.Resp)}
+		case req.Method == "DELETE":
+			return &middleware.Response{Err: notFound}
+		}
+		return &middleware.Response{Err: remarshal([]message.Result{{}}, req.Resp)}
+	})
+	recorder := cassette.NewRecorder()
+	client := recorder.Record(bridge)
+	client.Do("GET", "/api/<username>/lights", nil, &map[string]message.Light{})
+	client.Do("PUT", "/api/<username>/lights/1/state", []byte(`{"on":true,"bri":100}`), nil)
+	client.Do("GET", "/api/<username>/lights", nil, &map[string]message.Light{})
+	client.Do("DELETE", "/api/<username>/lights/9", nil, nil)
+
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "session.json")
+	if err = recorder.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := cassette.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cassette.NewReplayer(loaded)
+}
+
+// remarshal decodes v into resp as client.Client decodes a response.
+func remarshal(v interface{}, resp interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, resp)
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	replayer := record(t)
+
+	// Bodies are matched by value, so the order of keys does not matter.
+	err := replayer.Do("PUT", "/api/<username>/lights/1/state", []byte(`{"bri": 100, "on": true}`), nil)
+	if err != nil {
+		t.Errorf("Do(PUT) = %v", err)
+	}
+	// Each read replays the response recorded for it, in order.
+	for _, name := range []string{"Lamp 1", "Lamp 2"} {
+		lights := map[string]message.Light{}
+		if err := replayer.Do("GET", "/api/<username>/lights", nil, &lights); err != nil {
+			t.Fatal(err)
+		}
+		if lights["1"].Name != name {
+			t.Errorf("Do(GET) = %+v, want light 1 named %v", lights, name)
+		}
+	}
+	// Errors of the bridge replay with their type.
+	err = replayer.Do("DELETE", "/api/<username>/lights/9", nil, nil)
+	if e, ok := err.(*message.APIError); !ok || !reflect.DeepEqual(e, notFound) {
+		t.Errorf("Do(DELETE) = %#v, want %#v", err, notFound)
+	}
+	if unused := replayer.Unused(); len(unused) != 0 {
+		t.Errorf("Unused() = %+v, want none", unused)
+	}
+
+	// Every read was replayed, so another one matches nothing.
+	err = replayer.Do("GET", "/api/<username>/lights", nil, &map[string]message.Light{})
+	if _, ok := err.(*cassette.NoMatchError); !ok {
+		t.Errorf("Do(GET) = %v, want a *cassette.NoMatchError", err)
+	}
+}
+
+func TestReplayRepeat(t *testing.T) {
+	replayer := record(t)
+	replayer.Repeat = true
+	for i := 0; i < 3; i++ {
+		lights := map[string]message.Light{}
+		if err := replayer.Do("GET", "/api/<username>/lights", nil, &lights); err != nil {
+			t.Fatal(err)
+		}
+		// The first matching interaction answers every request.
+		if lights["1"].Name != "Lamp 1" {
+			t.Errorf("Do(GET) %v = %+v, want light 1 named Lamp 1", i, lights)
+		}
+	}
+	if unused := replayer.Unused(); len(unused) != 3 {
+		t.Errorf("Unused() = %+v, want the other 3 interactions", unused)
+	}
+}
+
+func TestReplayNoMatch(t *testing.T) {
+	replayer := record(t)
+	err := replayer.Do("PUT", "/api/<username>/lights/1/state", []byte(`{"on":false}`), nil)
+	want := &cassette.NoMatchError{Method: "PUT", Address: "/api/<username>/lights/1/state", Message: `{"on":false}`}
+	if !reflect.DeepEqual(err, want) {
+		t.Errorf("Do() = %#v, want %#v", err, want)
+	}
+
+	// A matcher that ignores bodies accepts the same request.
+	replayer.Match = cassette.MatchAll(cassette.MatchMethod, cassette.MatchAddress)
+	if err = replayer.Do("PUT", "/api/<username>/lights/1/state", []byte(`{"on":false}`), nil); err != nil {
+		t.Errorf("Do() without matching bodies = %v", err)
+	}
+	unused := replayer.Unused()
+	if len(unused) != 3 || unused[0].Method != "GET" || unused[1].Method != "GET" || unused[2].Method != "DELETE" {
+		t.Errorf("Unused() = %+v, want the reads and the delete", unused)
+	}
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue/logging"
+)
+
+// BridgeNotFoundError represents an error when a requested Philips Hue bridge was not discovered.
+type BridgeNotFoundError struct {
+	ID string
+}
+
+// Error satisfies the error interface.
+func (e *BridgeNotFoundError) Error() string {
+	return fmt.Sprintf("Bridge %v not found", e.ID)
+}
+
+// Bridge represents a Philips Hue bridge found on the local network.
+type Bridge struct {
+	// Unique ID of the bridge.
+	ID string
+	// IP address of the bridge.
+	IP string
+	// Name of the bridge, as set by its owner.
+	Name string
+	// The hardware model of the bridge, e.g. BSB002.
+	ModelID string
+}
+
+// listTimeout limits how long ListBridges waits for the config of each bridge when WithTimeout is not given.
+const listTimeout = 5 * time.Second
+
+// ListBridges returns every bridge found by discoverer, or the meethue.com cloud service if discoverer is nil. The name
+// and model of each bridge are read from its config endpoint, concurrently; they are left empty if the bridge cannot be
+// reached. WithTimeout, WithTransport and WithLogger apply to these requests, which time out after 5 seconds if no
+// timeout is given.
+func ListBridges(discoverer Discoverer, opts ...Option) (bridges []Bridge, err error) {
+	o := newOptions(opts)
+	if discoverer == nil {
+		discoverer = &NUPnP{HTTPClient: o.httpClient(), Logger: o.logger}
+	}
+	resp, err := discoverer.Discover()
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := o.httpClient()
+	if httpClient.Timeout <= 0 {
+		httpClient.Timeout = listTimeout
+	}
+	resp = dedupe(resp)
+	bridges = make([]Bridge, len(resp))
+	var wg sync.WaitGroup
+	for i, r := range resp {
+		bridges[i] = Bridge{ID: strings.ToLower(r.ID), IP: r.InternalIP}
+		wg.Add(1)
+		go func(bridge *Bridge) {
+			defer wg.Done()
+			config, err := GetBridgeConfig(httpClient, bridge.IP)
+			if err != nil {
+				o.logger.Debug(fmt.Sprintf("Cannot read the config of bridge %v: %v", bridge.ID, err), logging.Fields{
+					logging.Package:   "github.com/drombosky/disco-dance-party/hue/client",
+					logging.Operation: "ListBridges",
+					logging.Bridge:    bridge.ID,
+					logging.Host:      bridge.IP,
+					logging.Error:     err,
+				})
+				return
+			}
+			bridge.Name = config.Name
+			bridge.ModelID = config.ModelID
+		}(&bridges[i])
+	}
+	wg.Wait()
+	return bridges, nil
+}
+
+// NewClientForBridge returns a client to the Philips Hue bridge with the given ID. The bridge is found using
+// discoverer, or the meethue.com cloud service if discoverer is nil. If username is empty, the username saved for the
+// bridge by Pair is used. It is the same as NewClient with WithUsername, WithBridgeID and WithDiscoverer.
+func NewClientForBridge(username, id string, discoverer Discoverer) (client *Client, err error) {
+	return NewClient(WithUsername(username), WithBridgeID(id), WithDiscoverer(discoverer))
+}
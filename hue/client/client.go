@@ -4,6 +4,7 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -11,8 +12,10 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
+	"sync"
 
-	log "github.com/Sirupsen/logrus"
+	"github.com/drombosky/disco-dance-party/hue/logging"
+	"github.com/drombosky/disco-dance-party/hue/message"
 )
 
 // InvalidIPError represents an error that occurs when an IP address is invalid.
@@ -47,11 +50,23 @@ func (e *ServiceError) Error() string {
 	return fmt.Sprintf("Received %v %v: %v", e.StatusCode, e.Status, e.Message)
 }
 
-// Client represents a client to a Philips Hue bridge.
+// Client represents a client to a Philips Hue bridge. It is safe for concurrent use by multiple goroutines.
 type Client struct {
 	client   *http.Client
-	endpoint *url.URL
 	username string
+	bridgeID string
+	logger   logging.Logger
+
+	// Re-discovery of the bridge when it cannot be reached.
+	discoverer      Discoverer
+	rediscoverAfter int
+	onReconnect     func(bridgeID, oldIP, newIP string)
+	rediscoverMu    sync.Mutex
+
+	// mu guards the endpoint, which is replaced rather than modified, and the count of failed connections.
+	mu       sync.RWMutex
+	endpoint *url.URL
+	failures int
 }
 
 // ipRegexp is a regular expression for verifying IP addresses.
@@ -63,76 +78,81 @@ type MeetHueResp struct {
 	InternalIP string `json:"internalipaddress"`
 }
 
-// NewClient returns a client to a Philips Hue bridge given a username.
-func NewClient(username string) (client *Client, err error) {
-	httpClient := &http.Client{}
-	r, err := httpClient.Get("https://www.meethue.com/api/nupnp")
-	if err != nil {
-		return nil, err
-	}
-	defer r.Body.Close()
-
-	body, err := ioutil.ReadAll(r.Body)
+// NewClient returns a client to a Philips Hue bridge configured by opts. Without options the bridge is found using the
+// meethue.com cloud service and the username saved for it by Pair is used. If more than one bridge is found and
+// WithBridgeID is not given a *MultipleBridgesError is returned; use ListBridges to choose between them.
+func NewClient(opts ...Option) (client *Client, err error) {
+	o := newOptions(opts)
+	bridge, err := o.bridge()
 	if err != nil {
 		return nil, err
 	}
-	log.WithFields(log.Fields{
-		"package":  "github.com/drombosky/disco-dance-party/hue",
-		"function": "NewClient",
-		"host":     "https://www.meethue.com/api/nupnp",
-		"method":   "GET",
-		"request":  string(body),
-	}).Debugf("Recieved %v from %v", string(body), "https://www.meethue.com/api/nupnp")
+	return newBridgeClient(o, bridge)
+}
 
-	resp := []MeetHueResp{}
-	if err = json.Unmarshal(body, &resp); err != nil {
-		return nil, err
+// newBridgeClient returns a client to the given bridge, loading the username from the credential file if it is not
+// set.
+func newBridgeClient(o *options, bridge MeetHueResp) (client *Client, err error) {
+	if o.https && o.fingerprint == "" && bridge.ID == "" {
+		return nil, ErrNoPin
 	}
-	if len(resp) != 1 {
-		return nil, &MultipleBridgesError{NumberOfBridges: len(resp)}
+	username := o.username
+	if username == "" {
+		if username, err = o.credentials.Load(bridge.ID); err != nil {
+			return nil, err
+		}
 	}
-	bridge := resp[0]
 
 	re := regexp.MustCompile(ipRegexp)
 	if !re.MatchString(bridge.InternalIP) {
 		return nil, &InvalidIPError{IP: bridge.InternalIP}
 	}
-	log.WithFields(log.Fields{
-		"package":  "github.com/drombosky/disco-dance-party/hue",
-		"function": "NewClient",
-	}).Debugf("Found bridge %v with IP %v", bridge.ID, bridge.InternalIP)
-
-	endpoint, err := url.Parse("http://" + bridge.InternalIP)
+	o.logger.Debug(fmt.Sprintf("Found bridge %v with IP %v", bridge.ID, bridge.InternalIP), logging.Fields{
+		logging.Package:   "github.com/drombosky/disco-dance-party/hue/client",
+		logging.Operation: "NewClient",
+		logging.Bridge:    bridge.ID,
+		logging.Host:      bridge.InternalIP,
+	})
+
+	endpoint, err := url.Parse(o.scheme() + "://" + bridge.InternalIP)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Client{client: &http.Client{}, endpoint: endpoint, username: username}, nil
+	client = &Client{
+		client:          o.bridgeClient(bridge.ID),
+		endpoint:        endpoint,
+		username:        username,
+		bridgeID:        bridge.ID,
+		logger:          o.logger,
+		discoverer:      o.discoverer,
+		rediscoverAfter: o.rediscoverAfter,
+		onReconnect:     o.onReconnect,
+	}
+	if client.discoverer == nil {
+		client.discoverer = &NUPnP{HTTPClient: o.httpClient(), Logger: o.logger}
+	}
+	return client, nil
 }
 
-// Do sends a command to the to the Philips Hue bridge on behalf of the configured user.
+// Do sends a command to the to the Philips Hue bridge on behalf of the configured user. If the bridge returns an array
+// of results containing errors, the first is returned as a *message.APIError. Requests are not logged; wrap the client
+// with middleware.Logging to log them.
 func (c *Client) Do(method string, address string, message []byte, resp interface{}) (err error) {
-	// Get the URL for the resource.
-	url := c.endpoint
-	url.Path = strings.Replace(address, "<username>", c.username, -1)
-	log.WithFields(log.Fields{
-		"package":  "github.com/drombosky/disco-dance-party/hue",
-		"function": "NewClient",
-		"host":     url.String(),
-		"method":   method,
-		"request":  string(message),
-	}).Debugf("%v %v %v", method, url.String(), string(message))
-
-	// Create the http request.
-	req, err := http.NewRequest(method, url.String(), bytes.NewBuffer(message))
-	if err != nil {
-		return err
-	}
-	req.Header.Set("Content-Type", "application/json")
+	return c.DoContext(context.Background(), method, address, message, resp)
+}
 
-	// Send the http request.
-	r, err := c.client.Do(req)
+// DoContext is like Do, but the request is canceled if ctx is done before it completes, in which case the error of ctx
+// is returned.
+func (c *Client) DoContext(ctx context.Context, method string, address string, message []byte,
+	resp interface{}) (err error) {
+	// Send the http request. A request that failed because ctx is done returns the error of ctx, e.g.
+	// context.Canceled, rather than the error of the transport wrapping it.
+	r, err := c.send(ctx, method, address, message)
 	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		return err
 	}
 	defer r.Body.Close()
@@ -140,21 +160,70 @@ func (c *Client) Do(method string, address string, message []byte, resp interfac
 	// Get the body content.
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		return err
 	}
-	log.WithFields(log.Fields{
-		"package":  "github.com/drombosky/disco-dance-party/hue",
-		"function": "NewClient",
-		"host":     url.String(),
-		"method":   method,
-		"response": string(body),
-	}).Debugf("%v %v %v", method, url.String(), string(body))
 
 	// Check for errors.
 	if r.StatusCode != 200 {
 		return &ServiceError{StatusCode: r.StatusCode, Status: r.Status, Message: string(body)}
 	}
 
+	// The bridge reports failures in the body of 200 responses, so check for an array of results containing errors. The
+	// results are still returned when resp can hold them so callers can tell which changes were applied.
+	if err = checkResults(body); err != nil {
+		if resp != nil {
+			json.Unmarshal(body, resp)
+		}
+		return err
+	}
+
 	// Return the result.
+	if resp == nil {
+		return nil
+	}
 	return json.Unmarshal(body, resp)
 }
+
+// Username returns the username the client talks to the bridge as. Commands stored on the bridge, such as those of
+// schedules, address resources with it.
+func (c *Client) Username() string {
+	return c.username
+}
+
+// sendTo sends a request to the bridge at endpoint.
+func (c *Client) sendTo(ctx context.Context, endpoint *url.URL, method string, address string,
+	message []byte) (r *http.Response, err error) {
+	// Get the URL for the resource.
+	url := *endpoint
+	url.Path = strings.Replace(address, "<username>", c.username, -1)
+
+	// Create the http request.
+	req, err := http.NewRequest(method, url.String(), bytes.NewBuffer(message))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	return c.client.Do(req)
+}
+
+// checkResults returns the first error in body if it is an array of results.
+func checkResults(body []byte) (err error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		return nil
+	}
+	results := []message.Result{}
+	if json.Unmarshal(trimmed, &results) != nil {
+		return nil
+	}
+	for _, result := range results {
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
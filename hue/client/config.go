@@ -0,0 +1,154 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Discovery methods that can be configured.
+const (
+	DiscoveryNUPnP = "nupnp"
+	DiscoverySSDP  = "ssdp"
+	DiscoveryMDNS  = "mdns"
+	// DiscoveryAll runs every other method concurrently.
+	DiscoveryAll = "all"
+)
+
+// InvalidConfigError represents an error when a setting in a config file or the environment is invalid.
+type InvalidConfigError struct {
+	Setting string
+	Value   string
+	Reason  string
+}
+
+// Error satisfies the error interface.
+func (e *InvalidConfigError) Error() string {
+	return fmt.Sprintf("Invalid %v %q: %v", e.Setting, e.Value, e.Reason)
+}
+
+// Config represents the settings of a client read by LoadConfig.
+type Config struct {
+	// IP address of the bridge. Set by address or HUE_ADDRESS.
+	Address string
+	// Username used to talk to the bridge. Set by username or HUE_USERNAME.
+	Username string
+	// ID of the bridge to use when more than one is found. Set by bridge_id or HUE_BRIDGE_ID.
+	BridgeID string
+	// Limit on each request, e.g. 5s. Set by timeout or HUE_TIMEOUT.
+	Timeout time.Duration
+	// How to find the bridge: nupnp, ssdp, mdns or all. Set by discovery or HUE_DISCOVERY.
+	Discovery string
+	// Path of the credential file. Set by credentials or HUE_CREDENTIALS.
+	Credentials string
+	// SHA-256 fingerprint of the certificate of the bridge. If set, the bridge is talked to over HTTPS. Set by
+	// fingerprint or HUE_FINGERPRINT.
+	Fingerprint string
+}
+
+// DefaultConfigPath returns the config file in the user's home directory, ~/.hue/config.json.
+func DefaultConfigPath() string {
+	return filepath.Join(os.Getenv("HOME"), ".hue", "config.json")
+}
+
+// LoadConfig reads the settings of a client from the JSON config file at path and then from HUE_* environment
+// variables, which take precedence. A missing file is not an error, so the environment alone can configure a client.
+// For example:
+//
+//	{"address": "192.168.1.2", "timeout": "5s", "discovery": "all"}
+func LoadConfig(path string) (config *Config, err error) {
+	file := struct {
+		Address     string `json:"address"`
+		Username    string `json:"username"`
+		BridgeID    string `json:"bridge_id"`
+		Timeout     string `json:"timeout"`
+		Discovery   string `json:"discovery"`
+		Credentials string `json:"credentials"`
+		Fingerprint string `json:"fingerprint"`
+	}{}
+	body, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return nil, err
+	default:
+		if err = json.Unmarshal(body, &file); err != nil {
+			return nil, err
+		}
+	}
+
+	for variable, setting := range map[string]*string{
+		"HUE_ADDRESS":     &file.Address,
+		"HUE_USERNAME":    &file.Username,
+		"HUE_BRIDGE_ID":   &file.BridgeID,
+		"HUE_TIMEOUT":     &file.Timeout,
+		"HUE_DISCOVERY":   &file.Discovery,
+		"HUE_CREDENTIALS": &file.Credentials,
+		"HUE_FINGERPRINT": &file.Fingerprint,
+	} {
+		if value := os.Getenv(variable); value != "" {
+			*setting = value
+		}
+	}
+
+	config = &Config{
+		Address:     file.Address,
+		Username:    file.Username,
+		BridgeID:    file.BridgeID,
+		Discovery:   file.Discovery,
+		Credentials: file.Credentials,
+		Fingerprint: file.Fingerprint,
+	}
+	if file.Timeout != "" {
+		if config.Timeout, err = time.ParseDuration(file.Timeout); err != nil {
+			return nil, &InvalidConfigError{Setting: "timeout", Value: file.Timeout, Reason: err.Error()}
+		}
+	}
+	switch config.Discovery {
+	case "", DiscoveryNUPnP, DiscoverySSDP, DiscoveryMDNS, DiscoveryAll:
+	default:
+		return nil, &InvalidConfigError{Setting: "discovery", Value: config.Discovery,
+			Reason: "expected nupnp, ssdp, mdns or all"}
+	}
+	return config, nil
+}
+
+// Options returns the options that apply the settings. The discoverers use the logger and transport of the client, so
+// they apply to discovery too when given to NewClient after these options.
+func (c *Config) Options() (opts []Option) {
+	if c.Address != "" {
+		opts = append(opts, WithAddress(c.Address))
+	}
+	if c.Username != "" {
+		opts = append(opts, WithUsername(c.Username))
+	}
+	if c.BridgeID != "" {
+		opts = append(opts, WithBridgeID(c.BridgeID))
+	}
+	if c.Timeout > 0 {
+		opts = append(opts, WithTimeout(c.Timeout))
+	}
+	if c.Credentials != "" {
+		opts = append(opts, WithCredentials(&Credentials{Path: c.Credentials}))
+	}
+	if c.Discovery != "" {
+		opts = append(opts, WithDiscovery(c.Discovery))
+	}
+	if c.Fingerprint != "" {
+		opts = append(opts, WithHTTPS(c.Fingerprint))
+	}
+	return opts
+}
+
+// NewClientFromConfig is like NewClient, but applies the settings loaded from the config file at path and the
+// environment before opts, so opts take precedence.
+func NewClientFromConfig(path string, opts ...Option) (client *Client, err error) {
+	config, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(append(config.Options(), opts...)...)
+}
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue/logging"
+)
+
+// writeConfig writes a config file to a temporary directory and returns its path and a function removing it.
+func writeConfig(t *testing.T, body string) (path string, remove func()) {
+	dir, err := ioutil.TempDir("", "hue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path = filepath.Join(dir, "config.json")
+	if err = ioutil.WriteFile(path, []byte(body), 0600); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+// setenv sets an environment variable and returns a function restoring its previous value.
+func setenv(t *testing.T, name, value string) (restore func()) {
+	previous, ok := os.LookupEnv(name)
+	if err := os.Setenv(name, value); err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		if ok {
+			os.Setenv(name, previous)
+		} else {
+			os.Unsetenv(name)
+		}
+	}
+}
+
+func TestConfigEnvironmentOverridesFile(t *testing.T) {
+	path, remove := writeConfig(t, `{"address":"10.0.0.2","username":"file","timeout":"2s","discovery":"nupnp"}`)
+	defer remove()
+	defer setenv(t, "HUE_ADDRESS", "10.0.0.3")()
+	defer setenv(t, "HUE_TIMEOUT", "3s")()
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Config{Address: "10.0.0.3", Username: "file", Timeout: 3 * time.Second, Discovery: DiscoveryNUPnP}
+	if *config != *want {
+		t.Errorf("LoadConfig() = %+v, want %+v", config, want)
+	}
+
+	// Without a file the environment alone configures the client.
+	if config, err = LoadConfig(filepath.Join(filepath.Dir(path), "missing.json")); err != nil {
+		t.Fatal(err)
+	}
+	want = &Config{Address: "10.0.0.3", Timeout: 3 * time.Second}
+	if *config != *want {
+		t.Errorf("LoadConfig() without a file = %+v, want %+v", config, want)
+	}
+
+	// Settings from the environment are validated like those from the file.
+	defer setenv(t, "HUE_DISCOVERY", "carrier pigeon")()
+	if _, err = LoadConfig(path); err == nil {
+		t.Error("LoadConfig() = nil error, want the discovery method to be rejected")
+	} else if e, ok := err.(*InvalidConfigError); !ok || e.Setting != "discovery" {
+		t.Errorf("LoadConfig() = %v, want an *InvalidConfigError for discovery", err)
+	}
+}
+
+func TestNewClientFromConfigOptionsOverride(t *testing.T) {
+	path, remove := writeConfig(t, `{"address":"10.0.0.2","username":"file"}`)
+	defer remove()
+	defer setenv(t, "HUE_USERNAME", "environment")()
+
+	c, err := NewClientFromConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.endpoint.String() != "http://10.0.0.2" || c.username != "environment" {
+		t.Errorf("client = %v as %v, want http://10.0.0.2 as environment", c.endpoint, c.username)
+	}
+
+	// Options take precedence over both the file and the environment.
+	if c, err = NewClientFromConfig(path, WithAddress("10.0.0.4"), WithUsername("option")); err != nil {
+		t.Fatal(err)
+	}
+	if c.endpoint.String() != "http://10.0.0.4" || c.username != "option" {
+		t.Errorf("client = %v as %v, want http://10.0.0.4 as option", c.endpoint, c.username)
+	}
+}
+
+func TestConfigFingerprintSetsHTTPS(t *testing.T) {
+	fingerprint := strings.Repeat("ab", 32)
+	path, remove := writeConfig(t, `{"address":"10.0.0.2","username":"user","bridge_id":"001788fffeaabbcc",`+
+		`"timeout":"2s","fingerprint":"`+fingerprint+`"}`)
+	defer remove()
+
+	c, err := NewClientFromConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.endpoint.String() != "https://10.0.0.2" {
+		t.Errorf("endpoint = %v, want https://10.0.0.2", c.endpoint)
+	}
+	if c.client.Timeout != 2*time.Second {
+		t.Errorf("timeout = %v, want 2s", c.client.Timeout)
+	}
+	transport, ok := c.client.Transport.(*http.Transport)
+	if !ok || transport.TLSClientConfig == nil || transport.TLSClientConfig.VerifyPeerCertificate == nil {
+		t.Errorf("transport = %#v, want PinnedTransport", c.client.Transport)
+	}
+
+	// Without a fingerprint the bridge is talked to over HTTP.
+	path, remove = writeConfig(t, `{"address":"10.0.0.2","username":"user"}`)
+	defer remove()
+	if c, err = NewClientFromConfig(path); err != nil {
+		t.Fatal(err)
+	}
+	if c.endpoint.String() != "http://10.0.0.2" {
+		t.Errorf("endpoint = %v, want http://10.0.0.2", c.endpoint)
+	}
+}
+
+func TestWithHTTPSRequiresPin(t *testing.T) {
+	if _, err := NewClient(WithAddress("10.0.0.2"), WithUsername("user"), WithHTTPS("")); err != ErrNoPin {
+		t.Errorf("NewClient() = %v, want %v", err, ErrNoPin)
+	}
+}
+
+func TestConfigDiscoveryUsesClientSettings(t *testing.T) {
+	path, remove := writeConfig(t, `{"discovery":"all","timeout":"2s"}`)
+	defer remove()
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := &http.Transport{}
+	logger := logging.Std(nil, false)
+	o := newOptions(append(config.Options(), WithTransport(transport), WithLogger(logger)))
+
+	composite, ok := o.discoverer.(*Composite)
+	if !ok || len(composite.Discoverers) != 3 {
+		t.Fatalf("discoverer = %#v, want a composite of every method", o.discoverer)
+	}
+	nupnp := composite.Discoverers[0].(*NUPnP)
+	ssdp := composite.Discoverers[1].(*SSDP)
+	mdns := composite.Discoverers[2].(*MDNS)
+	for _, c := range []*http.Client{nupnp.HTTPClient, ssdp.HTTPClient} {
+		if c.Transport != transport || c.Timeout != 2*time.Second {
+			t.Errorf("discovery HTTP client = %#v, want the client transport and timeout", c)
+		}
+	}
+	for _, l := range []logging.Logger{nupnp.Logger, ssdp.Logger, mdns.Logger} {
+		if l != logger {
+			t.Errorf("discovery logger = %#v, want the client logger", l)
+		}
+	}
+}
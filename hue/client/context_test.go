@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDoContextCanceled(t *testing.T) {
+	received := make(chan struct{}, 1)
+	hang := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+		<-hang
+	}))
+	defer server.Close()
+	defer close(hang)
+	// The bridge is at 10.0.0.2, which the transport connects to the server instead.
+	transport := &http.Transport{DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, strings.TrimPrefix(server.URL, "http://"))
+	}}
+	c, err := NewClient(WithAddress("10.0.0.2"), WithUsername("user"), WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-received
+		cancel()
+	}()
+	start := time.Now()
+	err = c.DoContext(ctx, "GET", "/api/<username>/lights", nil, nil)
+	if err != context.Canceled {
+		t.Errorf("DoContext() = %v, want %v", err, context.Canceled)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("DoContext() returned after %v, want it to return once canceled", elapsed)
+	}
+}
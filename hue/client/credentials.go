@@ -0,0 +1,86 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// NoCredentialsError represents an error when no username has been stored for a Philips Hue bridge.
+type NoCredentialsError struct {
+	ID   string
+	Path string
+}
+
+// Error satisfies the error interface.
+func (e *NoCredentialsError) Error() string {
+	return fmt.Sprintf("No username for bridge %v in %v, pair with the bridge first", e.ID, e.Path)
+}
+
+// Credentials represents a file holding the usernames that have been whitelisted on Philips Hue bridges, keyed by
+// bridge ID.
+type Credentials struct {
+	// Path of the credential file.
+	Path string
+}
+
+// DefaultCredentials returns the credential file in the user's home directory, ~/.hue/credentials.json.
+func DefaultCredentials() *Credentials {
+	return &Credentials{Path: filepath.Join(os.Getenv("HOME"), ".hue", "credentials.json")}
+}
+
+// Load returns the username stored for a bridge.
+func (c *Credentials) Load(id string) (username string, err error) {
+	usernames, err := c.read()
+	if err != nil {
+		return "", err
+	}
+	username, ok := usernames[strings.ToLower(id)]
+	if !ok {
+		return "", &NoCredentialsError{ID: id, Path: c.Path}
+	}
+	return username, nil
+}
+
+// Save stores the username for a bridge, replacing any username previously stored for it.
+func (c *Credentials) Save(id, username string) (err error) {
+	usernames, err := c.read()
+	if err != nil {
+		return err
+	}
+	usernames[strings.ToLower(id)] = username
+
+	body, err := json.MarshalIndent(usernames, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(c.Path), 0700); err != nil {
+		return err
+	}
+
+	// Write to a temporary file and rename it so a failed write cannot lose the existing usernames.
+	tmp := c.Path + ".tmp"
+	if err = ioutil.WriteFile(tmp, body, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.Path)
+}
+
+// read returns the usernames in the credential file, or an empty map if it does not exist.
+func (c *Credentials) read() (usernames map[string]string, err error) {
+	usernames = map[string]string{}
+	body, err := ioutil.ReadFile(c.Path)
+	if os.IsNotExist(err) {
+		return usernames, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(body, &usernames); err != nil {
+		return nil, err
+	}
+	return usernames, nil
+}
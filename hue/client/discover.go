@@ -0,0 +1,187 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue/logging"
+)
+
+// nupnpURL is the meethue.com cloud service that lists the bridges on the caller's network.
+const nupnpURL = "https://www.meethue.com/api/nupnp"
+
+// NoBridgesError represents an error when none of the discoverers found a Philips Hue bridge.
+type NoBridgesError struct {
+	Errors []error
+}
+
+// Error satisfies the error interface.
+func (e *NoBridgesError) Error() string {
+	if len(e.Errors) == 0 {
+		return "No bridges found"
+	}
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("No bridges found: %v", strings.Join(messages, "; "))
+}
+
+// Discoverer represents a method of finding the Philips Hue bridges on the local network.
+type Discoverer interface {
+	// Discover returns the bridges that were found.
+	Discover() (resp []MeetHueResp, err error)
+}
+
+// BridgeConfig represents the configuration a Philips Hue bridge returns from /api/config without authentication.
+type BridgeConfig struct {
+	Name       string `json:"name"`
+	BridgeID   string `json:"bridgeid"`
+	ModelID    string `json:"modelid"`
+	APIVersion string `json:"apiversion"`
+	SwVersion  string `json:"swversion"`
+	MAC        string `json:"mac"`
+}
+
+// NUPnP discovers Philips Hue bridges using the meethue.com cloud service. The zero value is ready to use.
+type NUPnP struct {
+	// URL of the service. Defaults to https://www.meethue.com/api/nupnp.
+	URL string
+	// HTTPClient is used to query the service. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// Logger is logged to. Defaults to logging nothing.
+	Logger logging.Logger
+}
+
+// Discover returns the bridges the cloud service has seen on the caller's network.
+func (n *NUPnP) Discover() (resp []MeetHueResp, err error) {
+	address := n.URL
+	if address == "" {
+		address = nupnpURL
+	}
+	start := time.Now()
+	resp = []MeetHueResp{}
+	if err = get(n.HTTPClient, address, &resp); err != nil {
+		return nil, err
+	}
+	logging.OrNop(n.Logger).Debug(fmt.Sprintf("Found %v bridges", len(resp)), logging.Fields{
+		logging.Package:   "github.com/drombosky/disco-dance-party/hue/client",
+		logging.Operation: "(n *NUPnP) Discover",
+		logging.Host:      address,
+		logging.Latency:   time.Since(start),
+	})
+	return resp, nil
+}
+
+// Static "discovers" a Philips Hue bridge at a known IP address by asking it for its bridge ID.
+type Static struct {
+	// IP address of the bridge.
+	IP string
+	// HTTPClient is used to query the bridge. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Discover returns the bridge at the configured IP address.
+func (s *Static) Discover() (resp []MeetHueResp, err error) {
+	config, err := GetBridgeConfig(s.HTTPClient, s.IP)
+	if err != nil {
+		return nil, err
+	}
+	return []MeetHueResp{{ID: strings.ToLower(config.BridgeID), InternalIP: s.IP}}, nil
+}
+
+// Composite runs several discoverers concurrently and returns the first non-empty result.
+type Composite struct {
+	// Discoverers to run.
+	Discoverers []Discoverer
+	// Timeout is how long to wait for a result. Zero means wait for every discoverer to finish.
+	Timeout time.Duration
+}
+
+// Discover returns the bridges found by the first discoverer to find any, with duplicate bridge IDs removed. If no
+// discoverer finds a bridge, a *NoBridgesError holding the errors of the failed discoverers is returned.
+func (c *Composite) Discover() (resp []MeetHueResp, err error) {
+	type result struct {
+		resp []MeetHueResp
+		err  error
+	}
+	// The channel is buffered so discoverers still running after a result is returned do not leak.
+	results := make(chan result, len(c.Discoverers))
+	for _, discoverer := range c.Discoverers {
+		go func(discoverer Discoverer) {
+			resp, err := discoverer.Discover()
+			results <- result{resp: resp, err: err}
+		}(discoverer)
+	}
+
+	var timeout <-chan time.Time
+	if c.Timeout > 0 {
+		timeout = time.After(c.Timeout)
+	}
+
+	errs := []error{}
+	for range c.Discoverers {
+		select {
+		case r := <-results:
+			if r.err != nil {
+				errs = append(errs, r.err)
+				continue
+			}
+			if len(r.resp) > 0 {
+				return dedupe(r.resp), nil
+			}
+		case <-timeout:
+			return nil, &NoBridgesError{Errors: errs}
+		}
+	}
+	return nil, &NoBridgesError{Errors: errs}
+}
+
+// GetBridgeConfig gets the unauthenticated configuration of the bridge at ip.
+func GetBridgeConfig(httpClient *http.Client, ip string) (config *BridgeConfig, err error) {
+	config = &BridgeConfig{}
+	if err = get(httpClient, fmt.Sprintf("http://%v/api/config", ip), config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// dedupe removes bridges with duplicate IDs, keeping the first.
+func dedupe(resp []MeetHueResp) []MeetHueResp {
+	seen := map[string]bool{}
+	unique := []MeetHueResp{}
+	for _, bridge := range resp {
+		id := strings.ToLower(bridge.ID)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, bridge)
+	}
+	return unique
+}
+
+// get performs an HTTP GET of address and decodes the JSON response into resp.
+func get(httpClient *http.Client, address string, resp interface{}) (err error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	r, err := httpClient.Get(address)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if r.StatusCode != 200 {
+		return &ServiceError{StatusCode: r.StatusCode, Status: r.Status, Message: string(body)}
+	}
+	return json.Unmarshal(body, resp)
+}
@@ -0,0 +1,292 @@
+package client
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// description is the description.xml served by a fake bridge.
+const description = `<?xml version="1.0" encoding="UTF-8" ?>
+<root xmlns="urn:schemas-upnp-org:device-1-0">
+<URLBase>http://%v/</URLBase>
+<device>
+<modelName>Philips hue bridge 2015</modelName>
+<serialNumber>001788aabbcc</serialNumber>
+</device>
+</root>`
+
+// responder answers every packet it reads on a loopback UDP port with reply.
+func responder(t *testing.T, reply []byte) (address string, stop func()) {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		buf := make([]byte, 2048)
+		for {
+			_, from, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			conn.WriteTo(reply, from)
+		}
+	}()
+	return conn.LocalAddr().String(), func() { conn.Close() }
+}
+
+// listenLoopback opens the packet connection a discoverer sends from.
+func listenLoopback() (net.PacketConn, error) {
+	return net.ListenPacket("udp4", "127.0.0.1:0")
+}
+
+func TestSSDPDiscover(t *testing.T) {
+	bridge := httptest.NewServer(nil)
+	defer bridge.Close()
+	host := strings.TrimPrefix(bridge.URL, "http://")
+	bridge.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/description.xml" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, description, host)
+	})
+
+	replies := []struct {
+		name  string
+		reply string
+		want  []MeetHueResp
+	}{
+		{
+			name: "bridge",
+			reply: "HTTP/1.1 200 OK\r\nSERVER: Linux/3.14.0 UPnP/1.0 IpBridge/1.17.0\r\n" +
+				"hue-bridgeid: 001788FFFEAABBCC\r\nLOCATION: " + bridge.URL + "/description.xml\r\n\r\n",
+			want: []MeetHueResp{{ID: "001788fffeaabbcc", InternalIP: "127.0.0.1"}},
+		},
+		{
+			name:  "other device",
+			reply: "HTTP/1.1 200 OK\r\nSERVER: Linux UPnP/1.0 Sonos/1.0\r\nLOCATION: http://127.0.0.1:1/\r\n\r\n",
+			want:  []MeetHueResp{},
+		},
+	}
+	for _, r := range replies {
+		t.Run(r.name, func(t *testing.T) {
+			address, stop := responder(t, []byte(r.reply))
+			defer stop()
+
+			s := &SSDP{Listen: listenLoopback, Address: address, Timeout: 200 * time.Millisecond}
+			resp, err := s.Discover()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(resp, r.want) {
+				t.Errorf("Discover() = %v, want %v", resp, r.want)
+			}
+		})
+	}
+}
+
+func TestParseSSDPReply(t *testing.T) {
+	replies := []struct {
+		reply    string
+		location string
+		ok       bool
+	}{
+		{"HTTP/1.1 200 OK\r\nhue-bridgeid: 001788FFFEAABBCC\r\nLOCATION: http://10.0.0.2/description.xml\r\n\r\n",
+			"http://10.0.0.2/description.xml", true},
+		{"HTTP/1.1 200 OK\r\nSERVER: FreeRTOS/7.4.2 UPnP/1.0 IpBridge/1.10.0\r\nLOCATION: http://10.0.0.3/d.xml\r\n\r\n",
+			"http://10.0.0.3/d.xml", true},
+		{"HTTP/1.1 200 OK\r\nhue-bridgeid: 001788FFFEAABBCC\r\n\r\n", "", false},
+		{"HTTP/1.1 200 OK\r\nLOCATION: http://10.0.0.4/description.xml\r\n\r\n", "", false},
+		{"not http", "", false},
+	}
+	for _, r := range replies {
+		location, ok := parseSSDPReply([]byte(r.reply))
+		if location != r.location || ok != r.ok {
+			t.Errorf("parseSSDPReply(%q) = %q, %v, want %q, %v", r.reply, location, ok, r.location, r.ok)
+		}
+	}
+}
+
+// dnsName encodes a domain name without compression.
+func dnsName(name string) []byte {
+	b := []byte{}
+	for _, label := range strings.Split(name, ".") {
+		b = append(b, byte(len(label)))
+		b = append(b, label...)
+	}
+	return append(b, 0)
+}
+
+// dnsResourceRecord encodes a resource record of the given type.
+func dnsResourceRecord(name string, rrtype uint16, data []byte) []byte {
+	b := dnsName(name)
+	fixed := make([]byte, 10)
+	binary.BigEndian.PutUint16(fixed[0:], rrtype)
+	binary.BigEndian.PutUint16(fixed[2:], 1)
+	binary.BigEndian.PutUint32(fixed[4:], 120)
+	binary.BigEndian.PutUint16(fixed[8:], uint16(len(data)))
+	return append(append(b, fixed...), data...)
+}
+
+// hueReply builds the mDNS reply of a bridge advertising the Hue service.
+func hueReply(id string, ip net.IP) []byte {
+	instance := "Philips Hue - AABBCC." + hueService
+	host := "Philips-hue.local"
+	txt := "bridgeid=" + id
+	srv := append([]byte{0, 0, 0, 0, 0, 80}, dnsName(host)...)
+
+	msg := []byte{0, 0, 0x84, 0, 0, 0, 0, 1, 0, 0, 0, 3}
+	msg = append(msg, dnsResourceRecord(hueService, dnsTypePTR, dnsName(instance))...)
+	msg = append(msg, dnsResourceRecord(instance, dnsTypeTXT, append([]byte{byte(len(txt))}, txt...))...)
+	msg = append(msg, dnsResourceRecord(instance, dnsTypeSRV, srv)...)
+	msg = append(msg, dnsResourceRecord(host, dnsTypeA, ip.To4())...)
+	return msg
+}
+
+func TestMDNSDiscover(t *testing.T) {
+	address, stop := responder(t, hueReply("001788FFFEAABBCC", net.IPv4(10, 0, 0, 2)))
+	defer stop()
+
+	m := &MDNS{Listen: listenLoopback, Address: address, Timeout: 200 * time.Millisecond}
+	resp, err := m.Discover()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []MeetHueResp{{ID: "001788fffeaabbcc", InternalIP: "10.0.0.2"}}
+	if !reflect.DeepEqual(resp, want) {
+		t.Errorf("Discover() = %v, want %v", resp, want)
+	}
+}
+
+func TestParseDNSMessageRejectsPointerLoops(t *testing.T) {
+	// A single answer whose name is a pointer to itself.
+	msg := []byte{0, 0, 0x84, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0xC0, 12}
+	if _, err := parseDNSMessage(msg); err != errInvalidDNSMessage {
+		t.Errorf("parseDNSMessage() = %v, want %v", err, errInvalidDNSMessage)
+	}
+}
+
+func TestNUPnPDiscover(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id":"001788fffeaabbcc","internalipaddress":"10.0.0.2"}]`)
+	}))
+	defer server.Close()
+
+	n := &NUPnP{URL: server.URL}
+	resp, err := n.Discover()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []MeetHueResp{{ID: "001788fffeaabbcc", InternalIP: "10.0.0.2"}}
+	if !reflect.DeepEqual(resp, want) {
+		t.Errorf("Discover() = %v, want %v", resp, want)
+	}
+}
+
+func TestStaticDiscover(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/config" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"name":"Philips hue","bridgeid":"001788FFFEAABBCC"}`)
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	s := &Static{IP: host}
+	resp, err := s.Discover()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []MeetHueResp{{ID: "001788fffeaabbcc", InternalIP: host}}
+	if !reflect.DeepEqual(resp, want) {
+		t.Errorf("Discover() = %v, want %v", resp, want)
+	}
+}
+
+// discovererFunc adapts a function to the Discoverer interface.
+type discovererFunc func() ([]MeetHueResp, error)
+
+// Discover satisfies the Discoverer interface.
+func (f discovererFunc) Discover() ([]MeetHueResp, error) {
+	return f()
+}
+
+func TestCompositeDiscover(t *testing.T) {
+	failing := discovererFunc(func() ([]MeetHueResp, error) {
+		return nil, fmt.Errorf("unreachable")
+	})
+	empty := discovererFunc(func() ([]MeetHueResp, error) {
+		return []MeetHueResp{}, nil
+	})
+	found := discovererFunc(func() ([]MeetHueResp, error) {
+		return []MeetHueResp{{ID: "a", InternalIP: "10.0.0.2"}, {ID: "A", InternalIP: "10.0.0.3"}}, nil
+	})
+	hanging := discovererFunc(func() ([]MeetHueResp, error) {
+		time.Sleep(time.Second)
+		return nil, nil
+	})
+
+	c := &Composite{Discoverers: []Discoverer{failing, empty, found}}
+	resp, err := c.Discover()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []MeetHueResp{{ID: "a", InternalIP: "10.0.0.2"}}
+	if !reflect.DeepEqual(resp, want) {
+		t.Errorf("Discover() = %v, want %v", resp, want)
+	}
+
+	c = &Composite{Discoverers: []Discoverer{failing, empty}}
+	if _, err = c.Discover(); err == nil {
+		t.Fatal("Discover() = nil error, want *NoBridgesError")
+	}
+	if e, ok := err.(*NoBridgesError); !ok || len(e.Errors) != 1 {
+		t.Errorf("Discover() = %#v, want *NoBridgesError with one error", err)
+	}
+
+	c = &Composite{Discoverers: []Discoverer{hanging}, Timeout: 10 * time.Millisecond}
+	if _, err = c.Discover(); err == nil {
+		t.Error("Discover() = nil error, want a timeout")
+	}
+}
+
+func TestListBridgesDoesNotWaitForUnreachableBridges(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"Living room","bridgeid":"001788FFFEAABBCC","modelid":"BSB002"}`)
+	}))
+	defer server.Close()
+	// A bridge that accepts connections but does not answer until the test is over.
+	hang := make(chan struct{})
+	hung := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-hang
+	}))
+	defer hung.Close()
+	defer close(hang)
+
+	found := discovererFunc(func() ([]MeetHueResp, error) {
+		return []MeetHueResp{
+			{ID: "001788FFFEAABBCC", InternalIP: strings.TrimPrefix(server.URL, "http://")},
+			{ID: "001788fffeddeeff", InternalIP: strings.TrimPrefix(hung.URL, "http://")},
+		}, nil
+	})
+	bridges, err := ListBridges(found, WithTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Bridge{
+		{ID: "001788fffeaabbcc", IP: strings.TrimPrefix(server.URL, "http://"), Name: "Living room", ModelID: "BSB002"},
+		{ID: "001788fffeddeeff", IP: strings.TrimPrefix(hung.URL, "http://")},
+	}
+	if !reflect.DeepEqual(bridges, want) {
+		t.Errorf("ListBridges() = %v, want %v", bridges, want)
+	}
+}
@@ -0,0 +1,282 @@
+package client
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue/logging"
+)
+
+// MDNSAddress is the multicast address and port used by mDNS.
+const MDNSAddress = "224.0.0.251:5353"
+
+// hueService is the DNS-SD service Philips Hue bridges advertise.
+const hueService = "_hue._tcp.local"
+
+// DNS record types used by mDNS discovery.
+const (
+	dnsTypeA   = 1
+	dnsTypePTR = 12
+	dnsTypeTXT = 16
+	dnsTypeSRV = 33
+)
+
+// errInvalidDNSMessage is returned when an mDNS reply cannot be parsed.
+var errInvalidDNSMessage = errors.New("invalid DNS message")
+
+// MDNS discovers Philips Hue bridges on the local network by querying for the _hue._tcp mDNS service. The zero value
+// is ready to use.
+type MDNS struct {
+	// Listen returns the packet connection used to send the query and read the replies. Defaults to an ephemeral UDP
+	// port on all interfaces, which makes responders reply directly to the caller.
+	Listen func() (net.PacketConn, error)
+	// Address is the address the query is sent to. Defaults to MDNSAddress.
+	Address string
+	// Timeout is how long to wait for replies. Defaults to 3 seconds.
+	Timeout time.Duration
+	// Logger is logged to. Defaults to logging nothing.
+	Logger logging.Logger
+}
+
+// dnsRecord represents a resource record from an mDNS reply.
+type dnsRecord struct {
+	name   string
+	rrtype uint16
+	// target is the name a PTR record points to or the host of a SRV record.
+	target string
+	ip     net.IP
+	txt    []string
+}
+
+// Discover sends an mDNS query and returns the bridges that replied.
+func (m *MDNS) Discover() (resp []MeetHueResp, err error) {
+	conn, err := m.listen()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	address := m.Address
+	if address == "" {
+		address = MDNSAddress
+	}
+	addr, err := net.ResolveUDPAddr("udp4", address)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := logging.OrNop(m.Logger)
+	logger.Debug(fmt.Sprintf("Query %v", hueService), logging.Fields{
+		logging.Package:   "github.com/drombosky/disco-dance-party/hue/client",
+		logging.Operation: "(m *MDNS) Discover",
+		logging.Host:      address,
+	})
+	if _, err = conn.WriteTo(dnsQuery(hueService, dnsTypePTR), addr); err != nil {
+		return nil, err
+	}
+	timeout := m.Timeout
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+	if err = conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	resp = []MeetHueResp{}
+	buf := make([]byte, 9000)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			if e, ok := err.(net.Error); ok && e.Timeout() {
+				return dedupe(resp), nil
+			}
+			return nil, err
+		}
+		records, err := parseDNSMessage(buf[:n])
+		if err != nil {
+			logger.Debug(fmt.Sprintf("Skipping reply from %v: %v", from, err), logging.Fields{
+				logging.Package:   "github.com/drombosky/disco-dance-party/hue/client",
+				logging.Operation: "(m *MDNS) Discover",
+				logging.Host:      from.String(),
+				logging.Error:     err,
+			})
+			continue
+		}
+		var sender net.IP
+		if udp, ok := from.(*net.UDPAddr); ok {
+			sender = udp.IP
+		}
+		resp = append(resp, bridgesFromRecords(records, sender)...)
+	}
+}
+
+// listen opens the packet connection used for the query.
+func (m *MDNS) listen() (net.PacketConn, error) {
+	if m.Listen != nil {
+		return m.Listen()
+	}
+	return net.ListenPacket("udp4", ":0")
+}
+
+// bridgesFromRecords finds the Hue service instances in an mDNS reply. The bridge ID comes from the bridgeid TXT
+// entry and the IP from the A record of the SRV target, falling back to the sender of the reply.
+func bridgesFromRecords(records []dnsRecord, sender net.IP) (resp []MeetHueResp) {
+	hosts := map[string]string{}
+	ips := map[string]net.IP{}
+	ids := map[string]string{}
+	instances := []string{}
+	for _, r := range records {
+		switch r.rrtype {
+		case dnsTypePTR:
+			if strings.EqualFold(r.name, hueService) {
+				instances = append(instances, r.target)
+			}
+		case dnsTypeSRV:
+			hosts[r.name] = r.target
+		case dnsTypeA:
+			ips[r.name] = r.ip
+		case dnsTypeTXT:
+			for _, entry := range r.txt {
+				if strings.HasPrefix(strings.ToLower(entry), "bridgeid=") {
+					ids[r.name] = strings.ToLower(entry[len("bridgeid="):])
+				}
+			}
+		}
+	}
+
+	for _, instance := range instances {
+		id := ids[instance]
+		if id == "" {
+			continue
+		}
+		ip := ips[hosts[instance]]
+		if ip == nil {
+			ip = sender
+		}
+		if ip == nil {
+			continue
+		}
+		resp = append(resp, MeetHueResp{ID: id, InternalIP: ip.String()})
+	}
+	return resp
+}
+
+// dnsQuery builds a DNS query message for a single question.
+func dnsQuery(name string, qtype uint16) []byte {
+	// Header: ID, flags, one question and no records.
+	msg := []byte{0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0}
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		msg = append(msg, byte(len(label)))
+		msg = append(msg, label...)
+	}
+	msg = append(msg, 0)
+	msg = append(msg, byte(qtype>>8), byte(qtype), 0, 1)
+	return msg
+}
+
+// parseDNSMessage returns the answer and additional records of a DNS message.
+func parseDNSMessage(msg []byte) (records []dnsRecord, err error) {
+	if len(msg) < 12 {
+		return nil, errInvalidDNSMessage
+	}
+	questions := int(binary.BigEndian.Uint16(msg[4:]))
+	count := int(binary.BigEndian.Uint16(msg[6:])) + int(binary.BigEndian.Uint16(msg[8:])) +
+		int(binary.BigEndian.Uint16(msg[10:]))
+
+	offset := 12
+	for i := 0; i < questions; i++ {
+		if _, offset, err = readDNSName(msg, offset); err != nil {
+			return nil, err
+		}
+		offset += 4
+	}
+
+	for i := 0; i < count; i++ {
+		r := dnsRecord{}
+		if r.name, offset, err = readDNSName(msg, offset); err != nil {
+			return nil, err
+		}
+		if offset+10 > len(msg) {
+			return nil, errInvalidDNSMessage
+		}
+		r.rrtype = binary.BigEndian.Uint16(msg[offset:])
+		length := int(binary.BigEndian.Uint16(msg[offset+8:]))
+		offset += 10
+		if offset+length > len(msg) {
+			return nil, errInvalidDNSMessage
+		}
+		data := msg[offset : offset+length]
+
+		switch r.rrtype {
+		case dnsTypeA:
+			if length != 4 {
+				return nil, errInvalidDNSMessage
+			}
+			r.ip = net.IPv4(data[0], data[1], data[2], data[3])
+		case dnsTypePTR:
+			if r.target, _, err = readDNSName(msg, offset); err != nil {
+				return nil, err
+			}
+		case dnsTypeSRV:
+			// Priority, weight and port precede the target.
+			if r.target, _, err = readDNSName(msg, offset+6); err != nil {
+				return nil, err
+			}
+		case dnsTypeTXT:
+			for j := 0; j < len(data); {
+				n := int(data[j])
+				if j+1+n > len(data) {
+					return nil, errInvalidDNSMessage
+				}
+				r.txt = append(r.txt, string(data[j+1:j+1+n]))
+				j += 1 + n
+			}
+		}
+		records = append(records, r)
+		offset += length
+	}
+	return records, nil
+}
+
+// readDNSName reads a possibly compressed domain name at offset and returns it along with the offset following it.
+func readDNSName(msg []byte, offset int) (name string, next int, err error) {
+	labels := []string{}
+	next = -1
+	// Each pointer must move backwards, which bounds the number of jumps and prevents loops.
+	limit := offset
+	for {
+		if offset >= len(msg) {
+			return "", 0, errInvalidDNSMessage
+		}
+		length := int(msg[offset])
+		switch {
+		case length == 0:
+			if next < 0 {
+				next = offset + 1
+			}
+			return strings.Join(labels, "."), next, nil
+		case length&0xC0 == 0xC0:
+			if offset+1 >= len(msg) {
+				return "", 0, errInvalidDNSMessage
+			}
+			if next < 0 {
+				next = offset + 2
+			}
+			pointer := int(binary.BigEndian.Uint16(msg[offset:]) & 0x3FFF)
+			if pointer >= limit {
+				return "", 0, errInvalidDNSMessage
+			}
+			offset, limit = pointer, pointer
+		default:
+			if offset+1+length > len(msg) {
+				return "", 0, errInvalidDNSMessage
+			}
+			labels = append(labels, string(msg[offset+1:offset+1+length]))
+			offset += 1 + length
+		}
+	}
+}
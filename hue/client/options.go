@@ -0,0 +1,215 @@
+package client
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue/logging"
+)
+
+// Option represents a setting of a client created by NewClient.
+type Option func(o *options)
+
+// options represents the settings of a client.
+type options struct {
+	address     string
+	username    string
+	bridgeID    string
+	timeout     time.Duration
+	transport   http.RoundTripper
+	https       bool
+	fingerprint string
+	discoverer  Discoverer
+	discovery   string
+	credentials *Credentials
+	logger      logging.Logger
+
+	rediscoverAfter int
+	onReconnect     func(bridgeID, oldIP, newIP string)
+}
+
+// WithAddress sets the IP address of the bridge, so it is not discovered. If no username is set the bridge is asked for
+// its ID to look up the saved username.
+func WithAddress(address string) Option {
+	return func(o *options) {
+		o.address = address
+	}
+}
+
+// WithUsername sets the username used to talk to the bridge instead of the one saved by Pair.
+func WithUsername(username string) Option {
+	return func(o *options) {
+		o.username = username
+	}
+}
+
+// WithBridgeID chooses the bridge with the given ID when discovery finds more than one.
+func WithBridgeID(id string) Option {
+	return func(o *options) {
+		o.bridgeID = id
+	}
+}
+
+// WithTimeout limits how long each request to the bridge or the discovery service may take. Defaults to no limit.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithTransport sets the HTTP transport used to talk to the bridge and the discovery service. Defaults to
+// http.DefaultTransport. Use WithHTTPS rather than PinnedTransport to talk to the bridge over HTTPS, since the
+// transport does not change the scheme of the requests.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *options) {
+		o.transport = transport
+	}
+}
+
+// WithHTTPS makes the client talk to the bridge over HTTPS, accepting only a certificate with the bridge ID as its
+// common name and the given fingerprint, as checked by PinnedTLSConfig. If fingerprint is empty the certificate must
+// instead be signed by one of BridgeRootCAs, and NewClient returns ErrNoPin if the bridge ID is not known either. The
+// transport set by WithTransport is then only used to find the bridge.
+func WithHTTPS(fingerprint string) Option {
+	return func(o *options) {
+		o.https = true
+		o.fingerprint = fingerprint
+	}
+}
+
+// WithDiscovery sets how the bridge is found by the name of a discovery method: DiscoveryNUPnP, DiscoverySSDP,
+// DiscoveryMDNS or DiscoveryAll. Unlike a discoverer given to WithDiscoverer, the discoverers log to the logger of the
+// client and use its transport and timeout. It is ignored if WithDiscoverer is given.
+func WithDiscovery(method string) Option {
+	return func(o *options) {
+		o.discovery = method
+	}
+}
+
+// WithDiscoverer sets how the bridge is found. Defaults to the meethue.com cloud service. It is ignored if WithAddress
+// is given.
+func WithDiscoverer(discoverer Discoverer) Option {
+	return func(o *options) {
+		o.discoverer = discoverer
+	}
+}
+
+// WithCredentials sets the credential file the username is loaded from. Defaults to DefaultCredentials().
+func WithCredentials(credentials *Credentials) Option {
+	return func(o *options) {
+		o.credentials = credentials
+	}
+}
+
+// WithLogger sets the logger the client and the default discoverer log to. Defaults to logging nothing.
+func WithLogger(logger logging.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithRediscovery makes the client find the bridge again when failures requests in a row cannot connect to it, because
+// the connection is refused or times out, e.g. because its DHCP lease gave it a new IP address. The bridge is looked up
+// by its ID with the discoverer set by WithDiscoverer or WithDiscovery, or the meethue.com cloud service, and the
+// request is sent again if it moved. Zero, the default, disables re-discovery.
+func WithRediscovery(failures int) Option {
+	return func(o *options) {
+		o.rediscoverAfter = failures
+	}
+}
+
+// WithReconnectCallback sets a function called when re-discovery finds the bridge at a new IP address.
+func WithReconnectCallback(onReconnect func(bridgeID, oldIP, newIP string)) Option {
+	return func(o *options) {
+		o.onReconnect = onReconnect
+	}
+}
+
+// newOptions applies opts to the default settings.
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.credentials == nil {
+		o.credentials = DefaultCredentials()
+	}
+	o.logger = logging.OrNop(o.logger)
+	if o.discoverer == nil {
+		o.discoverer = o.discoveryMethod()
+	}
+	return o
+}
+
+// discoveryMethod returns the discoverer of the method set by WithDiscovery, or nil if none is set.
+func (o *options) discoveryMethod() Discoverer {
+	switch o.discovery {
+	case DiscoveryNUPnP:
+		return &NUPnP{HTTPClient: o.httpClient(), Logger: o.logger}
+	case DiscoverySSDP:
+		return &SSDP{HTTPClient: o.httpClient(), Logger: o.logger}
+	case DiscoveryMDNS:
+		return &MDNS{Logger: o.logger}
+	case DiscoveryAll:
+		return &Composite{Discoverers: []Discoverer{
+			&NUPnP{HTTPClient: o.httpClient(), Logger: o.logger},
+			&SSDP{HTTPClient: o.httpClient(), Logger: o.logger},
+			&MDNS{Logger: o.logger},
+		}}
+	}
+	return nil
+}
+
+// httpClient returns the HTTP client used to find the bridge.
+func (o *options) httpClient() *http.Client {
+	return &http.Client{Transport: o.transport, Timeout: o.timeout}
+}
+
+// bridgeClient returns the HTTP client used to talk to the bridge with the given ID, which pins its certificate if
+// WithHTTPS is given.
+func (o *options) bridgeClient(bridgeID string) *http.Client {
+	if !o.https {
+		return o.httpClient()
+	}
+	return &http.Client{Transport: PinnedTransport(bridgeID, o.fingerprint), Timeout: o.timeout}
+}
+
+// scheme returns the scheme of the bridge endpoint.
+func (o *options) scheme() string {
+	if o.https {
+		return "https"
+	}
+	return "http"
+}
+
+// bridge finds the bridge to talk to.
+func (o *options) bridge() (bridge MeetHueResp, err error) {
+	discoverer := o.discoverer
+	switch {
+	case o.address != "" && o.username != "" && (o.bridgeID != "" || o.rediscoverAfter == 0):
+		// The bridge ID is known or not needed, so the bridge does not need to be asked for it.
+		return MeetHueResp{ID: o.bridgeID, InternalIP: o.address}, nil
+	case o.address != "":
+		discoverer = &Static{IP: o.address, HTTPClient: o.httpClient()}
+	case discoverer == nil:
+		discoverer = &NUPnP{HTTPClient: o.httpClient(), Logger: o.logger}
+	}
+
+	resp, err := discoverer.Discover()
+	if err != nil {
+		return bridge, err
+	}
+	if o.bridgeID != "" {
+		for _, bridge := range resp {
+			if strings.EqualFold(bridge.ID, o.bridgeID) {
+				return bridge, nil
+			}
+		}
+		return bridge, &BridgeNotFoundError{ID: o.bridgeID}
+	}
+	if len(resp) != 1 {
+		return bridge, &MultipleBridgesError{NumberOfBridges: len(resp)}
+	}
+	return resp[0], nil
+}
@@ -0,0 +1,184 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue/logging"
+	"github.com/drombosky/disco-dance-party/hue/message"
+)
+
+// ErrNoBridgeID is returned by Pair when the bridge is given without an ID and does not report one, since the username
+// is saved by bridge ID.
+var ErrNoBridgeID = errors.New("Bridge did not report its ID")
+
+// LinkButtonNotPressedError represents Hue error 101, returned when a user is created without the link button on the
+// bridge having been pressed. Other errors returned while creating a user are returned as a *message.APIError.
+type LinkButtonNotPressedError struct {
+	Description string
+}
+
+// Error satisfies the error interface.
+func (e *LinkButtonNotPressedError) Error() string {
+	return fmt.Sprintf("Link button not pressed: %v", e.Description)
+}
+
+// Pairing creates a user on a Philips Hue bridge once its link button has been pressed.
+type Pairing struct {
+	// DeviceType identifies the application to the bridge in the form <application_name>#<devicename>, at most 40
+	// characters.
+	DeviceType string
+	// Timeout is how long to wait for the link button to be pressed. Defaults to 30 seconds.
+	Timeout time.Duration
+	// Interval is how often to ask the bridge for a user while waiting. Defaults to 1 second.
+	Interval time.Duration
+	// HTTPClient is used to talk to the bridge. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// Credentials is where the new username is saved. Defaults to DefaultCredentials().
+	Credentials *Credentials
+	// Logger is logged to. Defaults to logging nothing.
+	Logger logging.Logger
+}
+
+// Pair waits for the link button of bridge to be pressed, creates a user for deviceType and saves its username to the
+// default credential file.
+func Pair(bridge MeetHueResp, deviceType string) (username string, err error) {
+	p := &Pairing{DeviceType: deviceType}
+	return p.Pair(bridge)
+}
+
+// Pair waits for the link button of bridge to be pressed, creates a user and saves its username to the credential
+// file. If the button is not pressed before the timeout a *LinkButtonNotPressedError is returned. If bridge has no ID
+// it is read from the configuration of the bridge first, so the username can be found by ID later.
+func (p *Pairing) Pair(bridge MeetHueResp) (username string, err error) {
+	username, _, err = p.pair(bridge, false)
+	return username, err
+}
+
+// PairEntertainment is like Pair, but also has the bridge generate a client key, the pre-shared key used to stream to
+// entertainment areas. The bridge only returns the client key once and it is not saved, so the caller must store it.
+func (p *Pairing) PairEntertainment(bridge MeetHueResp) (username, clientKey string, err error) {
+	return p.pair(bridge, true)
+}
+
+// pair waits for the link button to be pressed and creates a user, generating a client key if requested.
+func (p *Pairing) pair(bridge MeetHueResp, generateClientKey bool) (username, clientKey string, err error) {
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	interval := p.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	deadline := time.Now().Add(timeout)
+	if bridge.ID == "" {
+		config, err := GetBridgeConfig(p.httpClient(), bridge.InternalIP)
+		if err != nil {
+			return "", "", err
+		}
+		if bridge.ID = strings.ToLower(config.BridgeID); bridge.ID == "" {
+			return "", "", ErrNoBridgeID
+		}
+	}
+
+	for {
+		username, clientKey, err = p.createUser(bridge, generateClientKey)
+		if _, ok := err.(*LinkButtonNotPressedError); !ok || time.Now().Add(interval).After(deadline) {
+			break
+		}
+		logging.OrNop(p.Logger).Info(fmt.Sprintf("Waiting for the link button of bridge %v to be pressed", bridge.ID),
+			logging.Fields{
+				logging.Package:   "github.com/drombosky/disco-dance-party/hue/client",
+				logging.Operation: "(p *Pairing) pair",
+				logging.Bridge:    bridge.ID,
+				logging.Host:      bridge.InternalIP,
+			})
+		time.Sleep(interval)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	credentials := p.Credentials
+	if credentials == nil {
+		credentials = DefaultCredentials()
+	}
+	if err = credentials.Save(bridge.ID, username); err != nil {
+		return "", "", err
+	}
+	return username, clientKey, nil
+}
+
+// createUser asks the bridge to create a user once.
+func (p *Pairing) createUser(bridge MeetHueResp, generateClientKey bool) (username, clientKey string, err error) {
+	type Body struct {
+		DeviceType        string `json:"devicetype"`
+		GenerateClientKey bool   `json:"generateclientkey,omitempty"`
+	}
+	request, err := json.Marshal(Body{DeviceType: p.DeviceType, GenerateClientKey: generateClientKey})
+	if err != nil {
+		return "", "", err
+	}
+
+	address := fmt.Sprintf("http://%v/api", bridge.InternalIP)
+	start := time.Now()
+	r, err := p.httpClient().Post(address, "application/json", bytes.NewBuffer(request))
+	if err != nil {
+		return "", "", err
+	}
+	defer r.Body.Close()
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return "", "", err
+	}
+	// The response is not logged since it holds the new username and client key.
+	logging.OrNop(p.Logger).Debug(fmt.Sprintf("POST %v %v", address, r.Status), logging.Fields{
+		logging.Package:   "github.com/drombosky/disco-dance-party/hue/client",
+		logging.Operation: "(p *Pairing) createUser",
+		logging.Bridge:    bridge.ID,
+		logging.Host:      address,
+		logging.Method:    "POST",
+		logging.Latency:   time.Since(start),
+	})
+	if r.StatusCode != 200 {
+		return "", "", &ServiceError{StatusCode: r.StatusCode, Status: r.Status, Message: string(body)}
+	}
+
+	resp := []message.Result{}
+	if err = json.Unmarshal(body, &resp); err != nil {
+		return "", "", err
+	}
+	for _, result := range resp {
+		switch {
+		case result.Error != nil && result.Error.Type == message.ErrorTypeLinkButtonNotPressed:
+			return "", "", &LinkButtonNotPressedError{Description: result.Error.Description}
+		case result.Error != nil:
+			return "", "", result.Error
+		case result.Success["username"] != nil:
+			if err = json.Unmarshal(result.Success["username"], &username); err != nil {
+				return "", "", err
+			}
+			if result.Success["clientkey"] != nil {
+				err = json.Unmarshal(result.Success["clientkey"], &clientKey)
+			}
+			return username, clientKey, err
+		}
+	}
+	return "", "", &ServiceError{StatusCode: r.StatusCode, Status: r.Status, Message: string(body)}
+}
+
+// httpClient returns the HTTP client used to talk to the bridge.
+func (p *Pairing) httpClient() *http.Client {
+	if p.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return p.HTTPClient
+}
@@ -0,0 +1,173 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// linkButton is a fake bridge whose link button is pressed after a number of requests to create a user.
+type linkButton struct {
+	mu         sync.Mutex
+	presses    int
+	requests   int
+	deviceType string
+	// bridgeID is reported by /api/config.
+	bridgeID string
+}
+
+func (l *linkButton) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if r.Method == "GET" && r.URL.Path == "/api/config" {
+		fmt.Fprintf(w, `{"name":"Philips hue","bridgeid":%q}`, l.bridgeID)
+		return
+	}
+	if r.Method != "POST" || r.URL.Path != "/api" {
+		http.NotFound(w, r)
+		return
+	}
+	body := struct {
+		DeviceType string `json:"devicetype"`
+	}{}
+	json.NewDecoder(r.Body).Decode(&body)
+	l.deviceType = body.DeviceType
+
+	l.requests++
+	if l.requests <= l.presses {
+		fmt.Fprint(w, `[{"error":{"type":101,"address":"","description":"link button not pressed"}}]`)
+		return
+	}
+	fmt.Fprint(w, `[{"success":{"username":"83b7780291a6ceffbe0bd049104df"}}]`)
+}
+
+// tempCredentials returns a credential file in a new temporary directory.
+func tempCredentials(t *testing.T) (credentials *Credentials, cleanup func()) {
+	dir, err := ioutil.TempDir("", "credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, ".hue", "credentials.json")
+	return &Credentials{Path: path}, func() { os.RemoveAll(dir) }
+}
+
+func TestPairWaitsForLinkButton(t *testing.T) {
+	button := &linkButton{presses: 2}
+	server := httptest.NewServer(button)
+	defer server.Close()
+	credentials, cleanup := tempCredentials(t)
+	defer cleanup()
+
+	p := &Pairing{
+		DeviceType:  "disco#test",
+		Timeout:     time.Second,
+		Interval:    time.Millisecond,
+		Credentials: credentials,
+	}
+	bridge := MeetHueResp{ID: "001788FFFEAABBCC", InternalIP: strings.TrimPrefix(server.URL, "http://")}
+	username, err := p.Pair(bridge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if username != "83b7780291a6ceffbe0bd049104df" {
+		t.Errorf("Pair() = %v, want the username returned by the bridge", username)
+	}
+	button.mu.Lock()
+	defer button.mu.Unlock()
+	if button.requests != 3 || button.deviceType != "disco#test" {
+		t.Errorf("bridge got %v requests for %q, want 3 for disco#test", button.requests, button.deviceType)
+	}
+
+	saved, err := credentials.Load("001788fffeaabbcc")
+	if err != nil || saved != username {
+		t.Errorf("Load() = %v, %v, want %v", saved, err, username)
+	}
+	info, err := os.Stat(credentials.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("credential file mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestPairTimesOut(t *testing.T) {
+	button := &linkButton{presses: 1000}
+	server := httptest.NewServer(button)
+	defer server.Close()
+	credentials, cleanup := tempCredentials(t)
+	defer cleanup()
+
+	p := &Pairing{
+		DeviceType:  "disco#test",
+		Timeout:     20 * time.Millisecond,
+		Interval:    5 * time.Millisecond,
+		Credentials: credentials,
+	}
+	bridge := MeetHueResp{ID: "001788fffeaabbcc", InternalIP: strings.TrimPrefix(server.URL, "http://")}
+	if _, err := p.Pair(bridge); err == nil {
+		t.Fatal("Pair() = nil error, want *LinkButtonNotPressedError")
+	} else if _, ok := err.(*LinkButtonNotPressedError); !ok {
+		t.Fatalf("Pair() = %#v, want *LinkButtonNotPressedError", err)
+	}
+	if _, err := os.Stat(credentials.Path); !os.IsNotExist(err) {
+		t.Errorf("credential file was written after a failed pairing: %v", err)
+	}
+}
+
+func TestPairLooksUpBridgeID(t *testing.T) {
+	button := &linkButton{bridgeID: "001788FFFEAABBCC"}
+	server := httptest.NewServer(button)
+	defer server.Close()
+	credentials, cleanup := tempCredentials(t)
+	defer cleanup()
+
+	p := &Pairing{DeviceType: "disco#test", Interval: time.Millisecond, Credentials: credentials}
+	username, err := p.Pair(MeetHueResp{InternalIP: strings.TrimPrefix(server.URL, "http://")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved, err := credentials.Load("001788fffeaabbcc"); err != nil || saved != username {
+		t.Errorf("Load() = %v, %v, want %v", saved, err, username)
+	}
+
+	// A bridge that does not report its ID is not paired, since the username could not be found again.
+	button.bridgeID = ""
+	if _, err = p.Pair(MeetHueResp{InternalIP: strings.TrimPrefix(server.URL, "http://")}); err != ErrNoBridgeID {
+		t.Errorf("Pair() = %v, want %v", err, ErrNoBridgeID)
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	credentials, cleanup := tempCredentials(t)
+	defer cleanup()
+
+	if _, err := credentials.Load("a"); err == nil {
+		t.Fatal("Load() of a missing file = nil error, want *NoCredentialsError")
+	} else if _, ok := err.(*NoCredentialsError); !ok {
+		t.Fatalf("Load() = %#v, want *NoCredentialsError", err)
+	}
+	if err := credentials.Save("A", "first"); err != nil {
+		t.Fatal(err)
+	}
+	if err := credentials.Save("b", "second"); err != nil {
+		t.Fatal(err)
+	}
+	if err := credentials.Save("a", "third"); err != nil {
+		t.Fatal(err)
+	}
+	for id, want := range map[string]string{"a": "third", "B": "second"} {
+		if username, err := credentials.Load(id); err != nil || username != want {
+			t.Errorf("Load(%v) = %v, %v, want %v", id, username, err, want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue/logging"
+)
+
+// These tests send requests from many goroutines at once and are meant to be run with go test -race.
+
+func TestConcurrentDo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"name":%q}`, r.URL.Path)
+	}))
+	defer server.Close()
+	endpoint, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Client{client: &http.Client{}, endpoint: endpoint, username: "user", logger: logging.OrNop(nil)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			address := fmt.Sprintf("/api/<username>/lights/%v", i)
+			resp := map[string]string{}
+			var err error
+			if i%2 == 0 {
+				err = c.Do("GET", address, nil, &resp)
+			} else {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				err = c.DoContext(ctx, "GET", address, nil, &resp)
+				cancel()
+			}
+			if want := fmt.Sprintf("/api/user/lights/%v", i); err != nil || resp["name"] != want {
+				t.Errorf("Do(%v) = %v, %v, want %v", address, resp, err, want)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestConcurrentRediscovery(t *testing.T) {
+	old := httptest.NewServer(http.NotFoundHandler())
+	old.Close()
+	moved := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"Philips hue"}`)
+	}))
+	defer moved.Close()
+	endpoint, err := url.Parse(old.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lookups, reconnects int32
+	found := discovererFunc(func() ([]MeetHueResp, error) {
+		atomic.AddInt32(&lookups, 1)
+		return []MeetHueResp{{ID: "001788fffeaabbcc", InternalIP: strings.TrimPrefix(moved.URL, "http://")}}, nil
+	})
+	c := &Client{
+		client:          &http.Client{Timeout: 5 * time.Second},
+		endpoint:        endpoint,
+		username:        "user",
+		bridgeID:        "001788fffeaabbcc",
+		logger:          logging.OrNop(nil),
+		discoverer:      found,
+		rediscoverAfter: 1,
+		onReconnect: func(bridgeID, oldIP, newIP string) {
+			atomic.AddInt32(&reconnects, 1)
+		},
+	}
+
+	// Every request fails to connect to the old address at about the same time, but the bridge is only looked up once.
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp := map[string]string{}
+			if err := c.Do("GET", "/api/<username>/config", nil, &resp); err != nil || resp["name"] != "Philips hue" {
+				t.Errorf("Do() = %v, %v, want the config", resp, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if lookups != 1 || reconnects != 1 {
+		t.Errorf("looked up the bridge %v times and reconnected %v times, want 1 and 1", lookups, reconnects)
+	}
+	if c.getEndpoint().Host != strings.TrimPrefix(moved.URL, "http://") {
+		t.Errorf("endpoint = %v, want %v", c.getEndpoint(), moved.URL)
+	}
+}
@@ -0,0 +1,160 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strings"
+	"sync/atomic"
+
+	"github.com/drombosky/disco-dance-party/hue/logging"
+)
+
+// send sends a request to the bridge. If re-discovery is enabled and enough requests in a row could not connect, the
+// bridge is looked up again and, if it moved, the request is sent to its new address.
+func (c *Client) send(ctx context.Context, method string, address string, message []byte) (r *http.Response,
+	err error) {
+	endpoint := c.getEndpoint()
+	var connected int32
+	traced := httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(httptrace.GotConnInfo) { atomic.StoreInt32(&connected, 1) },
+	})
+	r, err = c.sendTo(traced, endpoint, method, address, message)
+	if !isConnectError(err, atomic.LoadInt32(&connected) == 1) {
+		c.resetFailures(endpoint)
+		return r, err
+	}
+	if c.rediscoverAfter <= 0 || c.bridgeID == "" {
+		return nil, err
+	}
+	// A request whose context ended while connecting still counts, since the bridge may be gone, but is not sent again.
+	failures := c.countFailure(endpoint)
+	if current := c.getEndpoint(); current != endpoint {
+		// The bridge was found at a new address while the request was being sent.
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return c.sendTo(ctx, current, method, address, message)
+	}
+	if failures < c.rediscoverAfter {
+		return nil, err
+	}
+
+	// A request that could not connect was never received by the bridge, so it is safe to send it again.
+	moved, rediscoverErr := c.rediscover(endpoint)
+	if rediscoverErr != nil || !moved {
+		if rediscoverErr != nil {
+			c.logger.Debug(fmt.Sprintf("Could not find bridge %v again: %v", c.bridgeID, rediscoverErr), logging.Fields{
+				logging.Package:   "github.com/drombosky/disco-dance-party/hue/client",
+				logging.Operation: "(c *Client) send",
+				logging.Bridge:    c.bridgeID,
+				logging.Error:     rediscoverErr,
+			})
+		}
+		return nil, err
+	}
+	if ctx.Err() != nil {
+		return nil, err
+	}
+	return c.sendTo(ctx, c.getEndpoint(), method, address, message)
+}
+
+// rediscover looks up the bridge and replaces the endpoint if the bridge is no longer at old. It returns whether the
+// endpoint differs from old, which is also the case if another request already replaced it.
+func (c *Client) rediscover(old *url.URL) (moved bool, err error) {
+	// Only one request looks up the bridge; the others wait and use its result.
+	c.rediscoverMu.Lock()
+	defer c.rediscoverMu.Unlock()
+	if c.getEndpoint() != old {
+		return true, nil
+	}
+
+	resp, err := c.discoverer.Discover()
+	if err != nil {
+		return false, err
+	}
+	for _, bridge := range resp {
+		if !strings.EqualFold(bridge.ID, c.bridgeID) {
+			continue
+		}
+		if bridge.InternalIP == old.Host || !isHost(bridge.InternalIP) {
+			return false, nil
+		}
+		endpoint := *old
+		endpoint.Host = bridge.InternalIP
+		c.mu.Lock()
+		c.endpoint = &endpoint
+		c.failures = 0
+		c.mu.Unlock()
+
+		c.logger.Info(fmt.Sprintf("Bridge %v moved from %v to %v", c.bridgeID, old.Host, bridge.InternalIP), logging.Fields{
+			logging.Package:   "github.com/drombosky/disco-dance-party/hue/client",
+			logging.Operation: "(c *Client) rediscover",
+			logging.Bridge:    c.bridgeID,
+			logging.Host:      bridge.InternalIP,
+		})
+		if c.onReconnect != nil {
+			c.onReconnect(c.bridgeID, old.Host, bridge.InternalIP)
+		}
+		return true, nil
+	}
+	return false, &BridgeNotFoundError{ID: c.bridgeID}
+}
+
+// getEndpoint returns the current endpoint. The endpoint is never modified, only replaced.
+func (c *Client) getEndpoint() *url.URL {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.endpoint
+}
+
+// countFailure counts a request to endpoint that could not connect and returns the number of such requests in a row.
+// Requests to an endpoint that has since been replaced are not counted.
+func (c *Client) countFailure(endpoint *url.URL) (failures int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.endpoint != endpoint {
+		return 0
+	}
+	c.failures++
+	return c.failures
+}
+
+// resetFailures resets the count of requests in a row that could not connect to endpoint.
+func (c *Client) resetFailures(endpoint *url.URL) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.endpoint == endpoint {
+		c.failures = 0
+	}
+}
+
+// isConnectError returns whether err means a connection to the bridge could not be opened, either because it was
+// refused or because opening it timed out. A timeout after connected is true is not a connect error, since the bridge
+// was reached.
+func isConnectError(err error, connected bool) bool {
+	if err == nil || connected {
+		return false
+	}
+	if e, ok := err.(*url.Error); ok {
+		err = e.Err
+	}
+	if e, ok := err.(*net.OpError); ok && e.Op == "dial" {
+		return true
+	}
+	if e, ok := err.(net.Error); ok && e.Timeout() {
+		return true
+	}
+	return err == context.DeadlineExceeded
+}
+
+// isHost returns whether address is an IP address with an optional port.
+func isHost(address string) bool {
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		address = host
+	}
+	return net.ParseIP(address) != nil
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue/logging"
+)
+
+// movingBridge returns a client to a bridge that is served at a new address once the first one stops answering, and a
+// function returning the addresses the bridge was found at again.
+func movingBridge(t *testing.T, old *httptest.Server, transport http.RoundTripper) (c *Client, moved *httptest.Server,
+	reconnects func() []string) {
+	moved = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"Philips hue"}`)
+	}))
+	endpoint, err := url.Parse(old.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := discovererFunc(func() ([]MeetHueResp, error) {
+		return []MeetHueResp{{ID: "001788fffeaabbcc", InternalIP: strings.TrimPrefix(moved.URL, "http://")}}, nil
+	})
+	hosts := make(chan string, 10)
+	c = &Client{
+		client:          &http.Client{Transport: transport, Timeout: 200 * time.Millisecond},
+		endpoint:        endpoint,
+		username:        "user",
+		bridgeID:        "001788fffeaabbcc",
+		logger:          logging.OrNop(nil),
+		discoverer:      found,
+		rediscoverAfter: 1,
+		onReconnect: func(bridgeID, oldIP, newIP string) {
+			hosts <- newIP
+		},
+	}
+	return c, moved, func() (reconnected []string) {
+		for {
+			select {
+			case host := <-hosts:
+				reconnected = append(reconnected, host)
+			default:
+				return reconnected
+			}
+		}
+	}
+}
+
+func TestRediscovery(t *testing.T) {
+	// The bridge stopped: connections are refused.
+	t.Run("closed", func(t *testing.T) {
+		old := httptest.NewServer(http.NotFoundHandler())
+		old.Close()
+		c, moved, reconnects := movingBridge(t, old, nil)
+		defer moved.Close()
+
+		resp := map[string]string{}
+		if err := c.Do("GET", "/api/<username>/config", nil, &resp); err != nil {
+			t.Fatal(err)
+		}
+		if got := reconnects(); len(got) != 1 || got[0] != strings.TrimPrefix(moved.URL, "http://") {
+			t.Errorf("reconnected to %v, want %v", got, moved.URL)
+		}
+	})
+
+	// The bridge is gone from the network: connecting hangs until the request times out.
+	t.Run("connect timeout", func(t *testing.T) {
+		old := httptest.NewServer(http.NotFoundHandler())
+		old.Close()
+		oldHost := strings.TrimPrefix(old.URL, "http://")
+		transport := &http.Transport{DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			if address == oldHost {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			return (&net.Dialer{}).DialContext(ctx, network, address)
+		}}
+		c, moved, reconnects := movingBridge(t, old, transport)
+		defer moved.Close()
+
+		resp := map[string]string{}
+		if err := c.Do("GET", "/api/<username>/config", nil, &resp); err != nil {
+			t.Fatal(err)
+		}
+		if got := reconnects(); len(got) != 1 {
+			t.Errorf("reconnected %v times, want 1", len(got))
+		}
+	})
+
+	// The bridge answers too slowly: it was reached, so it is not looked up again.
+	t.Run("response timeout", func(t *testing.T) {
+		hang := make(chan struct{})
+		old := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-hang
+		}))
+		defer old.Close()
+		defer close(hang)
+		c, moved, reconnects := movingBridge(t, old, nil)
+		defer moved.Close()
+
+		if err := c.Do("GET", "/api/<username>/config", nil, nil); err == nil {
+			t.Fatal("Do() = nil error, want a timeout")
+		}
+		if got := reconnects(); len(got) != 0 {
+			t.Errorf("reconnected to %v, want the bridge not to be looked up", got)
+		}
+	})
+}
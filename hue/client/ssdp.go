@@ -0,0 +1,238 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue/logging"
+)
+
+// SSDPAddress is the multicast address and port used by SSDP.
+const SSDPAddress = "239.255.255.250:1900"
+
+// ssdpSearch is the M-SEARCH request sent to discover Philips Hue bridges on the local network.
+const ssdpSearch = "M-SEARCH * HTTP/1.1\r\n" +
+	"HOST: %v\r\n" +
+	"MAN: \"ssdp:discover\"\r\n" +
+	"MX: %v\r\n" +
+	"ST: ssdp:all\r\n" +
+	"\r\n"
+
+// InvalidDescriptionError represents an error that occurs when a bridge description cannot be understood.
+type InvalidDescriptionError struct {
+	Location string
+	Reason   string
+}
+
+// Error satisfies the error interface.
+func (e *InvalidDescriptionError) Error() string {
+	return fmt.Sprintf("Invalid bridge description at %v: %v", e.Location, e.Reason)
+}
+
+// SSDP discovers Philips Hue bridges on the local network by sending an SSDP M-SEARCH multicast request and fetching
+// the description.xml of every bridge that replies. The zero value is ready to use.
+type SSDP struct {
+	// Listen returns the packet connection used to send the search request and read the replies. Defaults to an
+	// ephemeral UDP port on all interfaces.
+	Listen func() (net.PacketConn, error)
+	// Address is the address the search request is sent to. Defaults to SSDPAddress.
+	Address string
+	// Timeout is how long to wait for replies. Defaults to 3 seconds.
+	Timeout time.Duration
+	// HTTPClient is used to fetch the description of each bridge. Defaults to an http.Client using Timeout.
+	HTTPClient *http.Client
+	// Logger is logged to. Defaults to logging nothing.
+	Logger logging.Logger
+}
+
+// Description represents the UPnP description.xml served by a Philips Hue bridge.
+type Description struct {
+	URLBase string `xml:"URLBase"`
+	Device  struct {
+		DeviceType       string `xml:"deviceType"`
+		FriendlyName     string `xml:"friendlyName"`
+		Manufacturer     string `xml:"manufacturer"`
+		ModelDescription string `xml:"modelDescription"`
+		ModelName        string `xml:"modelName"`
+		ModelNumber      string `xml:"modelNumber"`
+		SerialNumber     string `xml:"serialNumber"`
+		UDN              string `xml:"UDN"`
+	} `xml:"device"`
+}
+
+// Discover sends an SSDP search request and returns the bridges that replied.
+func (s *SSDP) Discover() (resp []MeetHueResp, err error) {
+	locations, err := s.search()
+	if err != nil {
+		return nil, err
+	}
+
+	resp = []MeetHueResp{}
+	for _, location := range locations {
+		bridge, err := s.describe(location)
+		if err != nil {
+			logging.OrNop(s.Logger).Debug(fmt.Sprintf("Skipping %v: %v", location, err), logging.Fields{
+				logging.Package:   "github.com/drombosky/disco-dance-party/hue/client",
+				logging.Operation: "(s *SSDP) Discover",
+				logging.Host:      location,
+				logging.Error:     err,
+			})
+			continue
+		}
+		resp = append(resp, *bridge)
+	}
+	return resp, nil
+}
+
+// search sends the M-SEARCH request and returns the unique description locations of the bridges that replied.
+func (s *SSDP) search() (locations []string, err error) {
+	conn, err := s.listen()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	address := s.Address
+	if address == "" {
+		address = SSDPAddress
+	}
+	addr, err := net.ResolveUDPAddr("udp4", address)
+	if err != nil {
+		return nil, err
+	}
+
+	// MX is the maximum number of seconds a device may wait before replying and must be at least 1.
+	timeout := s.timeout()
+	mx := int(timeout / time.Second)
+	if mx < 1 {
+		mx = 1
+	}
+	request := fmt.Sprintf(ssdpSearch, address, mx)
+	logger := logging.OrNop(s.Logger)
+	logger.Debug(fmt.Sprintf("M-SEARCH %v", address), logging.Fields{
+		logging.Package:   "github.com/drombosky/disco-dance-party/hue/client",
+		logging.Operation: "(s *SSDP) search",
+		logging.Host:      address,
+		logging.Method:    "M-SEARCH",
+		logging.Request:   request,
+	})
+	if _, err = conn.WriteTo([]byte(request), addr); err != nil {
+		return nil, err
+	}
+	if err = conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	buf := make([]byte, 2048)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			if e, ok := err.(net.Error); ok && e.Timeout() {
+				return locations, nil
+			}
+			return nil, err
+		}
+		location, ok := parseSSDPReply(buf[:n])
+		logger.Debug(fmt.Sprintf("Received reply from %v", from), logging.Fields{
+			logging.Package:   "github.com/drombosky/disco-dance-party/hue/client",
+			logging.Operation: "(s *SSDP) search",
+			logging.Host:      from.String(),
+			logging.Response:  string(buf[:n]),
+		})
+		if !ok || seen[location] {
+			continue
+		}
+		seen[location] = true
+		locations = append(locations, location)
+	}
+}
+
+// parseSSDPReply returns the description location of a reply if it was sent by a Philips Hue bridge.
+func parseSSDPReply(reply []byte) (location string, ok bool) {
+	r, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(reply)), nil)
+	if err != nil {
+		return "", false
+	}
+	r.Body.Close()
+
+	if r.Header.Get("hue-bridgeid") == "" && !strings.Contains(r.Header.Get("Server"), "IpBridge") {
+		return "", false
+	}
+	location = r.Header.Get("Location")
+	return location, location != ""
+}
+
+// describe fetches the description.xml at location and converts it into a MeetHueResp.
+func (s *SSDP) describe(location string) (bridge *MeetHueResp, err error) {
+	httpClient := s.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: s.timeout()}
+	}
+	r, err := httpClient.Get(location)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	if r.StatusCode != 200 {
+		return nil, &ServiceError{StatusCode: r.StatusCode, Status: r.Status, Message: string(body)}
+	}
+
+	description := Description{}
+	if err = xml.Unmarshal(body, &description); err != nil {
+		return nil, err
+	}
+	if !strings.Contains(description.Device.ModelName, "Philips hue bridge") {
+		return nil, &InvalidDescriptionError{Location: location, Reason: "not a Philips hue bridge"}
+	}
+	if len(description.Device.SerialNumber) != 12 {
+		return nil, &InvalidDescriptionError{Location: location, Reason: "invalid serial number"}
+	}
+
+	// The description may omit URLBase, in which case the bridge is at the host that served it.
+	base := description.URLBase
+	if base == "" {
+		base = location
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, err
+	}
+	host, _, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		host = u.Host
+	}
+
+	// Bridge IDs are the MAC address in the serial number with fffe inserted in the middle.
+	serial := strings.ToLower(description.Device.SerialNumber)
+	return &MeetHueResp{ID: serial[:6] + "fffe" + serial[6:], InternalIP: host}, nil
+}
+
+// listen opens the packet connection used for the search.
+func (s *SSDP) listen() (net.PacketConn, error) {
+	if s.Listen != nil {
+		return s.Listen()
+	}
+	return net.ListenPacket("udp4", ":0")
+}
+
+// timeout returns the configured timeout or the default.
+func (s *SSDP) timeout() time.Duration {
+	if s.Timeout <= 0 {
+		return 3 * time.Second
+	}
+	return s.Timeout
+}
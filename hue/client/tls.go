@@ -0,0 +1,130 @@
+package client
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrNoPin is returned when connecting over HTTPS without a bridge ID or fingerprint to pin the bridge certificate to.
+var ErrNoPin = errors.New("No bridge ID or fingerprint to pin the bridge certificate to")
+
+// BridgeRootCAs holds the certificate authorities that sign the certificates of bridges, against which a certificate
+// pinned only by bridge ID is verified. It is empty, so such certificates are rejected, until the Hue bridge root CA
+// published by Signify is added, e.g. with AppendCertsFromPEM.
+var BridgeRootCAs = x509.NewCertPool()
+
+// CertificateMismatchError represents an error when the certificate presented by a bridge does not match the pin.
+type CertificateMismatchError struct {
+	// Pin is what was checked, either "bridge ID" or "fingerprint".
+	Pin      string
+	Expected string
+	Actual   string
+}
+
+// Error satisfies the error interface.
+func (e *CertificateMismatchError) Error() string {
+	return fmt.Sprintf("Bridge certificate %v mismatch: expected %v, got %v", e.Pin, e.Expected, e.Actual)
+}
+
+// Fingerprint returns the SHA-256 fingerprint of a certificate as a hex string.
+func Fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// GetFingerprint connects to the bridge at address, a host with an optional port, and returns the fingerprint of the
+// certificate it presents without verifying it. It can be used to record the fingerprint the first time a bridge is
+// used.
+func GetFingerprint(address string) (fingerprint string, err error) {
+	if !strings.Contains(address, ":") {
+		address += ":443"
+	}
+	conn, err := tls.Dial("tcp", address, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return "", &CertificateMismatchError{Pin: "fingerprint", Expected: "a certificate", Actual: "none"}
+	}
+	return Fingerprint(certs[0]), nil
+}
+
+// PinnedTLSConfig returns a TLS configuration that accepts the certificate of a Philips Hue bridge only if its common
+// name is bridgeID and its fingerprint is fingerprint. Either may be empty to skip that check, but not both. Without a
+// fingerprint the certificate must also be signed by one of BridgeRootCAs, since anyone can make a self-signed
+// certificate with the common name of a bridge; use GetFingerprint to record the fingerprint of bridges with
+// self-signed certificates. Fingerprints are compared ignoring case and colons.
+func PinnedTLSConfig(bridgeID, fingerprint string) *tls.Config {
+	fingerprint = strings.ToLower(strings.Replace(fingerprint, ":", "", -1))
+	return &tls.Config{
+		// The chain is verified against BridgeRootCAs rather than the system roots, or not at all if pinned by
+		// fingerprint, so the pin is checked instead.
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if bridgeID == "" && fingerprint == "" {
+				return ErrNoPin
+			}
+			if len(rawCerts) == 0 {
+				return &CertificateMismatchError{Pin: "fingerprint", Expected: "a certificate", Actual: "none"}
+			}
+			certs := make([]*x509.Certificate, len(rawCerts))
+			for i, raw := range rawCerts {
+				cert, err := x509.ParseCertificate(raw)
+				if err != nil {
+					return err
+				}
+				certs[i] = cert
+			}
+			cert := certs[0]
+			if fingerprint != "" && Fingerprint(cert) != fingerprint {
+				return &CertificateMismatchError{Pin: "fingerprint", Expected: fingerprint, Actual: Fingerprint(cert)}
+			}
+			if bridgeID != "" && !strings.EqualFold(cert.Subject.CommonName, bridgeID) {
+				return &CertificateMismatchError{Pin: "bridge ID", Expected: bridgeID, Actual: cert.Subject.CommonName}
+			}
+			if fingerprint == "" {
+				return verifyBridgeChain(certs)
+			}
+			return nil
+		},
+	}
+}
+
+// verifyBridgeChain verifies that the first of certs, the certificate of a bridge, is signed by one of BridgeRootCAs
+// through the others.
+func verifyBridgeChain(certs []*x509.Certificate) error {
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	// The bridge is addressed by IP, so the name is the bridge ID checked against the common name rather than a host.
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         BridgeRootCAs,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err
+}
+
+// PinnedTransport returns an HTTP transport that uses PinnedTLSConfig.
+func PinnedTransport(bridgeID, fingerprint string) *http.Transport {
+	return &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: PinnedTLSConfig(bridgeID, fingerprint)}
+}
+
+// NewHTTPSClient is like NewClientForBridge, but talks to the bridge over HTTPS. The certificate of the bridge must
+// have the bridge ID as its common name and match fingerprint or, if fingerprint is empty, be signed by one of
+// BridgeRootCAs. opts are applied after the bridge settings. It is the
+// same as NewClient with WithUsername, WithBridgeID, WithDiscoverer and WithHTTPS.
+func NewHTTPSClient(username, id string, discoverer Discoverer, fingerprint string,
+	opts ...Option) (client *Client, err error) {
+	opts = append([]Option{WithUsername(username), WithBridgeID(id), WithDiscoverer(discoverer)}, opts...)
+	return NewClient(append(opts, WithHTTPS(fingerprint))...)
+}
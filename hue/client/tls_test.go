@@ -0,0 +1,174 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue/logging"
+)
+
+func TestPinnedTransport(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"Philips hue"}`)
+	}))
+	defer server.Close()
+	endpoint, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fingerprint, err := GetFingerprint(endpoint.Host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The same fingerprint as written by tools that print certificates.
+	colons := []string{}
+	for i := 0; i < len(fingerprint); i += 2 {
+		colons = append(colons, strings.ToUpper(fingerprint[i:i+2]))
+	}
+
+	pins := []struct {
+		name        string
+		bridgeID    string
+		fingerprint string
+		ok          bool
+	}{
+		{"fingerprint", "", fingerprint, true},
+		{"fingerprint with colons", "", strings.Join(colons, ":"), true},
+		{"other fingerprint", "", strings.Repeat("ab", 32), false},
+		{"no fingerprint", "", "", false},
+		{"bridge ID only", "001788fffeaabbcc", "", false},
+		{"fingerprint and other bridge ID", "001788fffeaabbcc", fingerprint, false},
+	}
+	for _, p := range pins {
+		c := &Client{
+			client:   &http.Client{Transport: PinnedTransport(p.bridgeID, p.fingerprint), Timeout: time.Second},
+			endpoint: endpoint,
+			username: "user",
+			logger:   logging.OrNop(nil),
+		}
+		resp := map[string]string{}
+		err := c.Do("GET", "/api/<username>/config", nil, &resp)
+		if p.ok && (err != nil || resp["name"] != "Philips hue") {
+			t.Errorf("%v: Do() = %v, %v, want the config", p.name, resp, err)
+		}
+		if !p.ok && err == nil {
+			t.Errorf("%v: Do() = nil error, want the certificate to be rejected", p.name)
+		}
+	}
+}
+
+// newCertificate returns a certificate with the given common name signed by parent, or self-signed if parent is nil.
+func newCertificate(t *testing.T, commonName string, parent *tls.Certificate) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.Leaf, parent.PrivateKey.(*ecdsa.PrivateKey)
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{raw}, PrivateKey: key, Leaf: cert}
+}
+
+func TestPinnedTransportBridgeID(t *testing.T) {
+	root := newCertificate(t, "root-bridge", nil)
+	other := newCertificate(t, "other root", nil)
+	roots := BridgeRootCAs
+	defer func() { BridgeRootCAs = roots }()
+	BridgeRootCAs = x509.NewCertPool()
+	BridgeRootCAs.AddCert(root.Leaf)
+
+	certs := []struct {
+		name     string
+		cert     tls.Certificate
+		bridgeID string
+		ok       bool
+	}{
+		{"bridge ID", newCertificate(t, "001788fffeaabbcc", &root), "001788FFFEAABBCC", true},
+		{"other bridge ID", newCertificate(t, "001788fffeaabbcc", &root), "001788fffeddeeff", false},
+		{"other root", newCertificate(t, "001788fffeaabbcc", &other), "001788fffeaabbcc", false},
+		{"self-signed", newCertificate(t, "001788fffeaabbcc", nil), "001788fffeaabbcc", false},
+		{"no pin", newCertificate(t, "001788fffeaabbcc", &root), "", false},
+	}
+	for _, c := range certs {
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"name":"Philips hue"}`)
+		}))
+		server.TLS = &tls.Config{Certificates: []tls.Certificate{c.cert}}
+		server.StartTLS()
+		endpoint, err := url.Parse(server.URL)
+		if err != nil {
+			server.Close()
+			t.Fatal(err)
+		}
+		client := &Client{
+			client:   &http.Client{Transport: PinnedTransport(c.bridgeID, ""), Timeout: time.Second},
+			endpoint: endpoint,
+			username: "user",
+			logger:   logging.OrNop(nil),
+		}
+		resp := map[string]string{}
+		err = client.Do("GET", "/api/<username>/config", nil, &resp)
+		server.Close()
+		if c.ok && (err != nil || resp["name"] != "Philips hue") {
+			t.Errorf("%v: Do() = %v, %v, want the config", c.name, resp, err)
+		}
+		if !c.ok && err == nil {
+			t.Errorf("%v: Do() = nil error, want the certificate to be rejected", c.name)
+		}
+	}
+}
+
+func TestNewHTTPSClient(t *testing.T) {
+	found := discovererFunc(func() ([]MeetHueResp, error) {
+		return []MeetHueResp{{ID: "001788fffeaabbcc", InternalIP: "10.0.0.2"}}, nil
+	})
+	c, err := NewHTTPSClient("user", "001788fffeaabbcc", found, strings.Repeat("ab", 32), WithTimeout(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.endpoint.String() != "https://10.0.0.2" {
+		t.Errorf("endpoint = %v, want https://10.0.0.2", c.endpoint)
+	}
+	if c.client.Timeout != time.Second {
+		t.Errorf("timeout = %v, want 1s", c.client.Timeout)
+	}
+
+	// Without a fingerprint the certificate is pinned by bridge ID alone.
+	if c, err = NewHTTPSClient("user", "001788fffeaabbcc", found, ""); err != nil {
+		t.Errorf("NewHTTPSClient() without a fingerprint = %v, want the bridge ID to pin the certificate", err)
+	} else if c.endpoint.String() != "https://10.0.0.2" {
+		t.Errorf("endpoint = %v, want https://10.0.0.2", c.endpoint)
+	}
+}
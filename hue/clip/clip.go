@@ -0,0 +1,289 @@
+// Package clip is a library for the CLIP v2 API of the Philips Hue bridge. CLIP v2 exposes resources that do not exist
+// in the v1 API, such as devices, gradients and dynamic effects, under /clip/v2/resource and authenticates with the
+// hue-application-key header instead of a username in the path. The bridge only serves CLIP v2 over HTTPS.
+package clip
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue/client"
+	"github.com/drombosky/disco-dance-party/hue/logging"
+)
+
+// Client represents a client to the CLIP v2 API of a Philips Hue bridge. It satisfies hue.Client, so it can be wrapped
+// in the same rate limiting and retrying clients as the v1 client.
+type Client struct {
+	client   *http.Client
+	endpoint *url.URL
+	key      string
+	logger   logging.Logger
+}
+
+// Option represents a setting of a client created by NewClient.
+type Option func(c *Client)
+
+// WithLogger sets the logger the client logs to. Defaults to logging nothing.
+func WithLogger(logger logging.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// envelope represents the body of every CLIP v2 response.
+type envelope struct {
+	Errors []struct {
+		Description string `json:"description"`
+	} `json:"errors"`
+	Data json.RawMessage `json:"data"`
+}
+
+// NewClient returns a client to the CLIP v2 API of the bridge at address, e.g. https://192.168.1.2, authenticating with
+// key, the username created by pairing. httpClient should pin the certificate of the bridge, e.g. by using
+// client.PinnedTransport; if it is nil http.DefaultClient is used.
+func NewClient(address, key string, httpClient *http.Client, opts ...Option) (c *Client, err error) {
+	endpoint, err := url.Parse(address)
+	if err != nil {
+		return nil, err
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	c = &Client{client: httpClient, endpoint: endpoint, key: key}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.logger = logging.OrNop(c.logger)
+	return c, nil
+}
+
+// Do sends a request to the CLIP v2 API and decodes the data of the response into resp.
+func (c *Client) Do(method string, address string, message []byte, resp interface{}) (err error) {
+	return c.DoContext(context.Background(), method, address, message, resp)
+}
+
+// DoContext is like Do, but the request is canceled if ctx is done before it completes. Errors reported by the bridge
+// are returned as a *client.ServiceError.
+func (c *Client) DoContext(ctx context.Context, method string, address string, message []byte,
+	resp interface{}) (err error) {
+	u := *c.endpoint
+	u.Path = address
+	fields := logging.Fields{
+		logging.Package:   "github.com/drombosky/disco-dance-party/hue/clip",
+		logging.Operation: "(c *Client) DoContext",
+		logging.Host:      u.String(),
+		logging.Method:    method,
+		logging.Address:   address,
+		logging.Request:   string(message),
+	}
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewBuffer(message))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("hue-application-key", c.key)
+
+	start := time.Now()
+	r, err := c.client.Do(req)
+	if err != nil {
+		fields[logging.Latency] = time.Since(start)
+		fields[logging.Error] = err
+		c.logger.Debug(fmt.Sprintf("%v %v failed: %v", method, u.String(), err), fields)
+		return err
+	}
+	defer r.Body.Close()
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	fields[logging.Latency] = time.Since(start)
+	fields[logging.Response] = string(body)
+	c.logger.Debug(fmt.Sprintf("%v %v %v", method, u.String(), string(body)), fields)
+
+	e := envelope{}
+	decodeErr := json.Unmarshal(body, &e)
+	if r.StatusCode < 200 || r.StatusCode > 299 || len(e.Errors) > 0 {
+		message := string(body)
+		if len(e.Errors) > 0 {
+			descriptions := make([]string, len(e.Errors))
+			for i, e := range e.Errors {
+				descriptions[i] = e.Description
+			}
+			message = strings.Join(descriptions, "; ")
+		}
+		return &client.ServiceError{StatusCode: r.StatusCode, Status: r.Status, Message: message}
+	}
+	if decodeErr != nil {
+		return decodeErr
+	}
+	if resp == nil {
+		return nil
+	}
+	return json.Unmarshal(e.Data, resp)
+}
+
+// GetLights gets every light resource.
+func (c *Client) GetLights(ctx context.Context) (resp []Light, err error) {
+	resp = []Light{}
+	if err = c.getAll(ctx, TypeLight, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetLight gets a light resource.
+func (c *Client) GetLight(ctx context.Context, id string) (resp *Light, err error) {
+	resp = &Light{}
+	if err = c.get(ctx, TypeLight, id, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// UpdateLight changes the state of a light.
+func (c *Client) UpdateLight(ctx context.Context, id string, update LightUpdate) (err error) {
+	return c.put(ctx, TypeLight, id, update)
+}
+
+// GetDevices gets every device resource.
+func (c *Client) GetDevices(ctx context.Context) (resp []Device, err error) {
+	resp = []Device{}
+	if err = c.getAll(ctx, TypeDevice, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetDevice gets a device resource.
+func (c *Client) GetDevice(ctx context.Context, id string) (resp *Device, err error) {
+	resp = &Device{}
+	if err = c.get(ctx, TypeDevice, id, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetRooms gets every room resource.
+func (c *Client) GetRooms(ctx context.Context) (resp []Room, err error) {
+	resp = []Room{}
+	if err = c.getAll(ctx, TypeRoom, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetRoom gets a room resource.
+func (c *Client) GetRoom(ctx context.Context, id string) (resp *Room, err error) {
+	resp = &Room{}
+	if err = c.get(ctx, TypeRoom, id, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetZones gets every zone resource.
+func (c *Client) GetZones(ctx context.Context) (resp []Zone, err error) {
+	resp = []Zone{}
+	if err = c.getAll(ctx, TypeZone, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetZone gets a zone resource.
+func (c *Client) GetZone(ctx context.Context, id string) (resp *Zone, err error) {
+	resp = &Zone{}
+	if err = c.get(ctx, TypeZone, id, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetGroupedLights gets every grouped light resource.
+func (c *Client) GetGroupedLights(ctx context.Context) (resp []GroupedLight, err error) {
+	resp = []GroupedLight{}
+	if err = c.getAll(ctx, TypeGroupedLight, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetGroupedLight gets a grouped light resource.
+func (c *Client) GetGroupedLight(ctx context.Context, id string) (resp *GroupedLight, err error) {
+	resp = &GroupedLight{}
+	if err = c.get(ctx, TypeGroupedLight, id, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// UpdateGroupedLight changes the state of every light in a room or zone.
+func (c *Client) UpdateGroupedLight(ctx context.Context, id string, update LightUpdate) (err error) {
+	return c.put(ctx, TypeGroupedLight, id, update)
+}
+
+// GetScenes gets every scene resource.
+func (c *Client) GetScenes(ctx context.Context) (resp []Scene, err error) {
+	resp = []Scene{}
+	if err = c.getAll(ctx, TypeScene, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetScene gets a scene resource.
+func (c *Client) GetScene(ctx context.Context, id string) (resp *Scene, err error) {
+	resp = &Scene{}
+	if err = c.get(ctx, TypeScene, id, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// RecallScene applies a scene to its room or zone.
+func (c *Client) RecallScene(ctx context.Context, id string) (err error) {
+	type Recall struct {
+		Action string `json:"action"`
+	}
+	type Body struct {
+		Recall Recall `json:"recall"`
+	}
+	return c.put(ctx, TypeScene, id, Body{Recall: Recall{Action: "active"}})
+}
+
+// getAll gets every resource of a type into resp, a pointer to a slice.
+func (c *Client) getAll(ctx context.Context, rtype string, resp interface{}) (err error) {
+	return c.DoContext(ctx, "GET", "/clip/v2/resource/"+rtype, nil, resp)
+}
+
+// get gets a resource into resp. The bridge returns single resources in an array, so an empty array means the resource
+// does not exist.
+func (c *Client) get(ctx context.Context, rtype, id string, resp interface{}) (err error) {
+	data := []json.RawMessage{}
+	if err = c.DoContext(ctx, "GET", fmt.Sprintf("/clip/v2/resource/%v/%v", rtype, id), nil, &data); err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return &client.ServiceError{StatusCode: 404, Status: "404 Not Found", Message: rtype + " " + id + " not found"}
+	}
+	return json.Unmarshal(data[0], resp)
+}
+
+// put sends a change to a resource.
+func (c *Client) put(ctx context.Context, rtype, id string, body interface{}) (err error) {
+	message, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return c.DoContext(ctx, "PUT", fmt.Sprintf("/clip/v2/resource/%v/%v", rtype, id), message, nil)
+}
@@ -0,0 +1,223 @@
+package clip
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/drombosky/disco-dance-party/hue/client"
+)
+
+// request represents a request received by the test server.
+type request struct {
+	method string
+	path   string
+	key    string
+	body   string
+}
+
+// newTestClient returns a client to a server that answers every request with status and body, and records the requests
+// it receives in requests.
+func newTestClient(t *testing.T, status int, body string, requests *[]request) (c *Client, stop func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		*requests = append(*requests, request{
+			method: r.Method,
+			path:   r.URL.Path,
+			key:    r.Header.Get("hue-application-key"),
+			body:   string(b),
+		})
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}))
+	c, err := NewClient(server.URL, "key", nil)
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	return c, server.Close
+}
+
+func TestDoContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		data    []string
+		message string
+	}{
+		{"success", 200, `{"errors":[],"data":["a","b"]}`, []string{"a", "b"}, ""},
+		{"errors", 207, `{"errors":[{"description":"not found"},{"description":"invalid"}],"data":[]}`, nil,
+			"not found; invalid"},
+		{"status", 503, `unavailable`, nil, "unavailable"},
+		{"status with errors", 403, `{"errors":[{"description":"unauthorized user"}],"data":[]}`, nil,
+			"unauthorized user"},
+	}
+	for _, test := range tests {
+		requests := []request{}
+		c, stop := newTestClient(t, test.status, test.body, &requests)
+		data := []string{}
+		err := c.DoContext(context.Background(), "GET", "/clip/v2/resource/light", nil, &data)
+		stop()
+
+		if test.message == "" {
+			if err != nil || !reflect.DeepEqual(data, test.data) {
+				t.Errorf("%v: DoContext() = %v, %v, want %v", test.name, data, err, test.data)
+			}
+			continue
+		}
+		e, ok := err.(*client.ServiceError)
+		if !ok {
+			t.Errorf("%v: DoContext() = %v, want a *client.ServiceError", test.name, err)
+			continue
+		}
+		if e.StatusCode != test.status || e.Message != test.message {
+			t.Errorf("%v: DoContext() = %v %q, want %v %q", test.name, e.StatusCode, e.Message, test.status,
+				test.message)
+		}
+	}
+}
+
+func TestDoContextHeaders(t *testing.T) {
+	requests := []request{}
+	c, stop := newTestClient(t, 200, `{"errors":[],"data":[]}`, &requests)
+	defer stop()
+	if err := c.Do("PUT", "/clip/v2/resource/light/1", []byte(`{"on":{"on":true}}`), nil); err != nil {
+		t.Fatal(err)
+	}
+	want := []request{{method: "PUT", path: "/clip/v2/resource/light/1", key: "key", body: `{"on":{"on":true}}`}}
+	if !reflect.DeepEqual(requests, want) {
+		t.Errorf("requests = %+v, want %+v", requests, want)
+	}
+}
+
+func TestGetResources(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name  string
+		rtype string
+		data  string
+		get   func(c *Client) (interface{}, error)
+		want  interface{}
+	}{
+		{"GetLights", TypeLight,
+			`{"id":"l1","owner":{"rid":"d1","rtype":"device"},"metadata":{"name":"Lamp"},"on":{"on":true},` +
+				`"dimming":{"brightness":50},"color":{"xy":{"x":0.3,"y":0.4},"gamut_type":"C"},"type":"light"}`,
+			func(c *Client) (interface{}, error) { return c.GetLights(ctx) },
+			[]Light{{ID: "l1", Owner: ResourceIdentifier{RID: "d1", RType: "device"}, Metadata: Metadata{Name: "Lamp"},
+				On: On{On: true}, Dimming: &Dimming{Brightness: 50},
+				Color: &Color{XY: XY{X: 0.3, Y: 0.4}, GamutType: "C"}, Type: TypeLight}}},
+		{"GetDevices", TypeDevice,
+			`{"id":"d1","product_data":{"model_id":"LCT015","certified":true},"metadata":{"name":"Lamp"},` +
+				`"services":[{"rid":"l1","rtype":"light"}],"type":"device"}`,
+			func(c *Client) (interface{}, error) { return c.GetDevices(ctx) },
+			[]Device{{ID: "d1", ProductData: ProductData{ModelID: "LCT015", Certified: true},
+				Metadata: Metadata{Name: "Lamp"}, Services: []ResourceIdentifier{{RID: "l1", RType: TypeLight}},
+				Type: TypeDevice}}},
+		{"GetRooms", TypeRoom,
+			`{"id":"r1","children":[{"rid":"d1","rtype":"device"}],"services":[{"rid":"g1","rtype":"grouped_light"}],` +
+				`"metadata":{"name":"Living room","archetype":"living_room"},"type":"room"}`,
+			func(c *Client) (interface{}, error) { return c.GetRooms(ctx) },
+			[]Room{{ID: "r1", Children: []ResourceIdentifier{{RID: "d1", RType: TypeDevice}},
+				Services: []ResourceIdentifier{{RID: "g1", RType: TypeGroupedLight}},
+				Metadata: Metadata{Name: "Living room", Archetype: "living_room"}, Type: TypeRoom}}},
+		{"GetZones", TypeZone,
+			`{"id":"z1","children":[{"rid":"l1","rtype":"light"}],"services":[],"metadata":{"name":"Upstairs"},` +
+				`"type":"zone"}`,
+			func(c *Client) (interface{}, error) { return c.GetZones(ctx) },
+			[]Zone{{ID: "z1", Children: []ResourceIdentifier{{RID: "l1", RType: TypeLight}},
+				Services: []ResourceIdentifier{}, Metadata: Metadata{Name: "Upstairs"}, Type: TypeZone}}},
+		{"GetGroupedLights", TypeGroupedLight,
+			`{"id":"g1","owner":{"rid":"r1","rtype":"room"},"on":{"on":false},"type":"grouped_light"}`,
+			func(c *Client) (interface{}, error) { return c.GetGroupedLights(ctx) },
+			[]GroupedLight{{ID: "g1", Owner: ResourceIdentifier{RID: "r1", RType: TypeRoom}, On: &On{},
+				Type: TypeGroupedLight}}},
+		{"GetScenes", TypeScene,
+			`{"id":"s1","metadata":{"name":"Relax"},"group":{"rid":"r1","rtype":"room"},` +
+				`"actions":[{"target":{"rid":"l1","rtype":"light"},"action":{"on":{"on":true}}}],"type":"scene"}`,
+			func(c *Client) (interface{}, error) { return c.GetScenes(ctx) },
+			[]Scene{{ID: "s1", Metadata: Metadata{Name: "Relax"}, Group: ResourceIdentifier{RID: "r1", RType: TypeRoom},
+				Actions: []SceneAction{{Target: ResourceIdentifier{RID: "l1", RType: TypeLight},
+					Action: LightUpdate{On: &On{On: true}}}}, Type: TypeScene}}},
+	}
+	for _, test := range tests {
+		requests := []request{}
+		c, stop := newTestClient(t, 200, `{"errors":[],"data":[`+test.data+`]}`, &requests)
+		resp, err := test.get(c)
+		stop()
+		if err != nil {
+			t.Errorf("%v() = %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(resp, test.want) {
+			t.Errorf("%v() = %+v, want %+v", test.name, resp, test.want)
+		}
+		if len(requests) != 1 || requests[0].method != "GET" || requests[0].path != "/clip/v2/resource/"+test.rtype {
+			t.Errorf("%v() sent %+v, want GET /clip/v2/resource/%v", test.name, requests, test.rtype)
+		}
+	}
+}
+
+func TestGetResource(t *testing.T) {
+	requests := []request{}
+	c, stop := newTestClient(t, 200, `{"errors":[],"data":[{"id":"l1","on":{"on":true},"type":"light"}]}`, &requests)
+	light, err := c.GetLight(context.Background(), "l1")
+	stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (&Light{ID: "l1", On: On{On: true}, Type: TypeLight}); !reflect.DeepEqual(light, want) {
+		t.Errorf("GetLight() = %+v, want %+v", light, want)
+	}
+	if len(requests) != 1 || requests[0].path != "/clip/v2/resource/light/l1" {
+		t.Errorf("GetLight() sent %+v, want GET /clip/v2/resource/light/l1", requests)
+	}
+
+	// The bridge returns an empty array for resources that do not exist.
+	c, stop = newTestClient(t, 200, `{"errors":[],"data":[]}`, &requests)
+	defer stop()
+	if _, err = c.GetScene(context.Background(), "s1"); err == nil {
+		t.Fatal("GetScene() = nil error, want not found")
+	}
+	if e, ok := err.(*client.ServiceError); !ok || e.StatusCode != 404 {
+		t.Errorf("GetScene() = %v, want a 404 *client.ServiceError", err)
+	}
+}
+
+func TestUpdates(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name   string
+		update func(c *Client) error
+		path   string
+		body   string
+	}{
+		{"UpdateLight", func(c *Client) error {
+			return c.UpdateLight(ctx, "l1", LightUpdate{On: &On{On: true}, Dimming: &Dimming{Brightness: 20}})
+		}, "/clip/v2/resource/light/l1", `{"on":{"on":true},"dimming":{"brightness":20}}`},
+		{"UpdateGroupedLight", func(c *Client) error {
+			return c.UpdateGroupedLight(ctx, "g1", LightUpdate{Effects: &Effects{Effect: "candle"}})
+		}, "/clip/v2/resource/grouped_light/g1", `{"effects":{"effect":"candle"}}`},
+		{"RecallScene", func(c *Client) error {
+			return c.RecallScene(ctx, "s1")
+		}, "/clip/v2/resource/scene/s1", `{"recall":{"action":"active"}}`},
+	}
+	for _, test := range tests {
+		requests := []request{}
+		c, stop := newTestClient(t, 200, `{"errors":[],"data":[{"rid":"1","rtype":"light"}]}`, &requests)
+		err := test.update(c)
+		stop()
+		if err != nil {
+			t.Errorf("%v() = %v", test.name, err)
+			continue
+		}
+		want := []request{{method: "PUT", path: test.path, key: "key", body: test.body}}
+		if !reflect.DeepEqual(requests, want) {
+			t.Errorf("%v() sent %+v, want %+v", test.name, requests, want)
+		}
+	}
+}
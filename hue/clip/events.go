@@ -0,0 +1,396 @@
+package clip
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue"
+	"github.com/drombosky/disco-dance-party/hue/client"
+	"github.com/drombosky/disco-dance-party/hue/logging"
+	"github.com/drombosky/disco-dance-party/hue/message"
+)
+
+// Event types sent by the bridge.
+const (
+	EventAdd    = "add"
+	EventUpdate = "update"
+	EventDelete = "delete"
+	EventError  = "error"
+)
+
+// Event represents a change to one or more resources reported by the bridge.
+type Event struct {
+	// Unique ID of the event.
+	ID string
+	// The kind of change, one of EventAdd, EventUpdate, EventDelete or EventError.
+	Type string
+	// The time the bridge created the event.
+	CreationTime time.Time
+	// The resources that changed.
+	Data []EventResource
+}
+
+// EventResource represents a resource that changed. Update events only hold the fields that changed, so Resource only
+// has the fields present in Raw set.
+type EventResource struct {
+	// Unique ID of the resource.
+	ID string
+	// The resource type, e.g. light.
+	Type string
+	// The resource as sent by the bridge.
+	Raw json.RawMessage
+	// The resource decoded as a *Light, *Device, *Room, *Zone, *GroupedLight or *Scene, or nil for other types.
+	Resource interface{}
+}
+
+// SubscribeOptions represents how events are delivered.
+type SubscribeOptions struct {
+	// Buffer is the number of events that can wait to be received. Defaults to 64.
+	Buffer int
+	// DropWhenFull drops events that arrive while the buffer is full. Otherwise a full buffer stops reading from the
+	// bridge until the receiver catches up.
+	DropWhenFull bool
+	// ReconnectDelay is the time to wait before reconnecting after the stream ends, doubling up to 30 seconds while
+	// reconnecting fails. Defaults to 1 second. When polling it is the poll interval.
+	ReconnectDelay time.Duration
+}
+
+// Subscription represents a stream of events from the bridge.
+type Subscription struct {
+	events  chan Event
+	options SubscribeOptions
+	cancel  context.CancelFunc
+	done    chan struct{}
+	dropped uint64
+
+	mu  sync.Mutex
+	err error
+}
+
+// Events returns the channel events are delivered on. It is closed once the subscription is closed.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Err returns the last error encountered while reading events, or nil.
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Dropped returns the number of events dropped because the buffer was full.
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Close stops the subscription and waits for it to finish.
+func (s *Subscription) Close() error {
+	s.cancel()
+	<-s.done
+	return nil
+}
+
+// newSubscription returns a subscription that is stopped when ctx is done.
+func newSubscription(ctx context.Context, options SubscribeOptions) (*Subscription, context.Context) {
+	if options.Buffer <= 0 {
+		options.Buffer = 64
+	}
+	if options.ReconnectDelay <= 0 {
+		options.ReconnectDelay = time.Second
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return &Subscription{
+		events:  make(chan Event, options.Buffer),
+		options: options,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}, ctx
+}
+
+// send delivers an event, returning false if ctx is done first.
+func (s *Subscription) send(ctx context.Context, e Event) bool {
+	if s.options.DropWhenFull {
+		select {
+		case s.events <- e:
+		default:
+			atomic.AddUint64(&s.dropped, 1)
+		}
+		return ctx.Err() == nil
+	}
+	select {
+	case s.events <- e:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// setErr records the last error.
+func (s *Subscription) setErr(err error) {
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
+
+// Subscribe connects to the event stream of the bridge and delivers its events until ctx is done or the subscription
+// is closed. If the stream ends it is reconnected, resuming after the last event received. An error is returned if the
+// first connection fails, e.g. because the bridge does not support CLIP v2; PollV1 can be used instead.
+func (c *Client) Subscribe(ctx context.Context, options SubscribeOptions) (s *Subscription, err error) {
+	s, ctx = newSubscription(ctx, options)
+	r, err := c.connect(ctx, "")
+	if err != nil {
+		s.cancel()
+		return nil, err
+	}
+
+	go func(r *http.Response) {
+		defer close(s.done)
+		defer close(s.events)
+		var err error
+		lastEventID := ""
+		delay := s.options.ReconnectDelay
+		for {
+			if r != nil {
+				lastEventID, err = c.read(ctx, s, r, lastEventID)
+				r.Body.Close()
+				delay = s.options.ReconnectDelay
+				if err != nil {
+					s.setErr(err)
+				}
+			}
+			c.logger.Debug(fmt.Sprintf("Reconnecting to event stream in %v", delay), logging.Fields{
+				logging.Package:   "github.com/drombosky/disco-dance-party/hue/clip",
+				logging.Operation: "(c *Client) Subscribe",
+				logging.Event:     lastEventID,
+			})
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			if r, err = c.connect(ctx, lastEventID); err != nil {
+				s.setErr(err)
+				r = nil
+				if delay *= 2; delay > 30*time.Second {
+					delay = 30 * time.Second
+				}
+			}
+		}
+	}(r)
+	return s, nil
+}
+
+// connect opens the event stream, resuming after lastEventID if it is not empty.
+func (c *Client) connect(ctx context.Context, lastEventID string) (r *http.Response, err error) {
+	u := *c.endpoint
+	u.Path = "/eventstream/clip/v2"
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("hue-application-key", c.key)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	r, err = c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if r.StatusCode != 200 {
+		defer r.Body.Close()
+		body, _ := ioutil.ReadAll(r.Body)
+		return nil, &client.ServiceError{StatusCode: r.StatusCode, Status: r.Status, Message: string(body)}
+	}
+	return r, nil
+}
+
+// read delivers the events in a stream until it ends and returns the ID of the last event received.
+func (c *Client) read(ctx context.Context, s *Subscription, r *http.Response, lastEventID string) (string, error) {
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	id := ""
+	data := []string{}
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			// A blank line dispatches the event built from the preceding lines.
+			if len(data) > 0 {
+				events, err := decodeEvents([]byte(strings.Join(data, "\n")))
+				if err != nil {
+					s.setErr(err)
+				}
+				for _, e := range events {
+					if !s.send(ctx, e) {
+						return lastEventID, nil
+					}
+				}
+			}
+			if id != "" {
+				lastEventID = id
+			}
+			id, data = "", data[:0]
+		case strings.HasPrefix(line, ":"):
+			// Comments keep the connection alive.
+		case strings.HasPrefix(line, "id:"):
+			id = strings.TrimSpace(line[len("id:"):])
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(line[len("data:"):], " "))
+		}
+	}
+	return lastEventID, scanner.Err()
+}
+
+// decodeEvents decodes the data of a server-sent event, an array of events.
+func decodeEvents(data []byte) (events []Event, err error) {
+	type rawEvent struct {
+		ID           string            `json:"id"`
+		Type         string            `json:"type"`
+		CreationTime time.Time         `json:"creationtime"`
+		Data         []json.RawMessage `json:"data"`
+	}
+	raw := []rawEvent{}
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	for _, r := range raw {
+		e := Event{ID: r.ID, Type: r.Type, CreationTime: r.CreationTime}
+		for _, d := range r.Data {
+			resource, err := decodeResource(d)
+			if err != nil {
+				return nil, err
+			}
+			e.Data = append(e.Data, resource)
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// decodeResource decodes a resource according to its type.
+func decodeResource(raw json.RawMessage) (resource EventResource, err error) {
+	header := struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+	}{}
+	if err = json.Unmarshal(raw, &header); err != nil {
+		return resource, err
+	}
+	resource = EventResource{ID: header.ID, Type: header.Type, Raw: raw}
+	switch header.Type {
+	case TypeLight:
+		resource.Resource = &Light{}
+	case TypeDevice:
+		resource.Resource = &Device{}
+	case TypeRoom:
+		resource.Resource = &Room{}
+	case TypeZone:
+		resource.Resource = &Zone{}
+	case TypeGroupedLight:
+		resource.Resource = &GroupedLight{}
+	case TypeScene:
+		resource.Resource = &Scene{}
+	default:
+		return resource, nil
+	}
+	return resource, json.Unmarshal(raw, resource.Resource)
+}
+
+// PollV1 fakes the event stream of a bridge that does not support CLIP v2 by polling lights every
+// options.ReconnectDelay and reporting the lights that were added, changed or deleted. Like the event stream, only
+// changes are reported: the first successful poll is taken as the baseline, so use GetAll for the lights that already
+// exist. Since v1 lights have no resource IDs, the ID of each resource is its v1 address, e.g. /lights/1.
+func PollV1(ctx context.Context, lights hue.Lights, options SubscribeOptions) (s *Subscription) {
+	s, ctx = newSubscription(ctx, options)
+	go func() {
+		defer close(s.done)
+		defer close(s.events)
+		var previous map[string]message.Light
+		polled := false
+		sequence := 0
+		for {
+			current, err := lights.GetAllContext(ctx)
+			switch {
+			case err != nil:
+				s.setErr(err)
+			case !polled:
+				previous, polled = current, true
+			default:
+				for _, e := range diffV1(previous, current) {
+					sequence++
+					e.ID = strconv.Itoa(sequence)
+					if !s.send(ctx, e) {
+						return
+					}
+				}
+				previous = current
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(s.options.ReconnectDelay):
+			}
+		}
+	}()
+	return s
+}
+
+// diffV1 returns the events that turn previous into current.
+func diffV1(previous, current map[string]message.Light) (events []Event) {
+	now := time.Now()
+	for id, light := range current {
+		old, ok := previous[id]
+		switch {
+		case !ok:
+			events = append(events, Event{Type: EventAdd, CreationTime: now, Data: []EventResource{lightFromV1(id, light)}})
+		case !reflect.DeepEqual(old, light):
+			events = append(events, Event{Type: EventUpdate, CreationTime: now,
+				Data: []EventResource{lightFromV1(id, light)}})
+		}
+	}
+	for id, light := range previous {
+		if _, ok := current[id]; !ok {
+			events = append(events, Event{Type: EventDelete, CreationTime: now,
+				Data: []EventResource{lightFromV1(id, light)}})
+		}
+	}
+	return events
+}
+
+// lightFromV1 converts a v1 light into the resource CLIP v2 would report for it.
+func lightFromV1(id string, l message.Light) EventResource {
+	address := "/lights/" + id
+	light := &Light{
+		ID:       address,
+		IDV1:     address,
+		Metadata: Metadata{Name: l.Name},
+		On:       On{On: l.State.On},
+		Type:     TypeLight,
+	}
+	if l.State.Bri != 0 {
+		light.Dimming = &Dimming{Brightness: float64(l.State.Bri) * 100 / 254}
+	}
+	if l.State.Xy != [2]float64{} {
+		light.Color = &Color{XY: XY{X: l.State.Xy[0], Y: l.State.Xy[1]}}
+	}
+	if l.State.Ct != 0 {
+		ct := l.State.Ct
+		light.ColorTemperature = &ColorTemperature{Mirek: &ct, MirekValid: true}
+	}
+	raw, _ := json.Marshal(light)
+	return EventResource{ID: address, Type: TypeLight, Raw: raw, Resource: light}
+}
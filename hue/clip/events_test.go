@@ -0,0 +1,273 @@
+package clip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue"
+	"github.com/drombosky/disco-dance-party/hue/client"
+	"github.com/drombosky/disco-dance-party/hue/message"
+)
+
+// sse returns a server-sent event with the given ID holding an update event of a light.
+func sse(id string) string {
+	return fmt.Sprintf("id: %v\ndata: [{\"id\":\"e%v\",\"type\":\"update\",\"creationtime\":\"2024-01-02T03:04:05Z\","+
+		"\"data\":[{\"id\":\"l%v\",\"type\":\"light\",\"on\":{\"on\":true}}]}]\n\n", id, id, id)
+}
+
+// newEventServer returns a client to a server that writes streams[i] as the event stream of the ith connection. Every
+// stream but the last ends once written; the last is kept open until the client disconnects. The Last-Event-ID header
+// of each connection is sent on lastEventIDs.
+func newEventServer(t *testing.T, lastEventIDs chan<- string, streams ...string) (c *Client, stop func()) {
+	connections := make(chan string, len(streams))
+	for _, stream := range streams {
+		connections <- stream
+	}
+	hang := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/eventstream/clip/v2" || r.Header.Get("hue-application-key") != "key" ||
+			r.Header.Get("Accept") != "text/event-stream" {
+			http.Error(w, "unexpected request", 400)
+			return
+		}
+		if lastEventIDs != nil {
+			lastEventIDs <- r.Header.Get("Last-Event-ID")
+		}
+		select {
+		case stream := <-connections:
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, stream)
+			w.(http.Flusher).Flush()
+			if len(connections) > 0 {
+				return
+			}
+		default:
+		}
+		select {
+		case <-r.Context().Done():
+		case <-hang:
+		}
+	}))
+	c, err := NewClient(server.URL, "key", nil)
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	return c, func() {
+		close(hang)
+		server.Close()
+	}
+}
+
+// receive returns the next event of s, failing the test if none arrives within a second.
+func receive(t *testing.T, s *Subscription) Event {
+	select {
+	case e, ok := <-s.Events():
+		if !ok {
+			t.Fatalf("Events() closed, want an event: %v", s.Err())
+		}
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("Events() = nothing, want an event")
+	}
+	return Event{}
+}
+
+func TestSubscribe(t *testing.T) {
+	stream := ": hi\n\n" + sse("1") +
+		// Data split over several lines is joined with newlines, and resources of other types are not decoded.
+		"id: 2\ndata: [{\"id\":\"e2\",\"type\":\"add\",\n" +
+		"data: \"data\":[{\"id\":\"m1\",\"type\":\"motion\"}]}]\n\n"
+	c, stop := newEventServer(t, nil, stream)
+	defer stop()
+	s, err := c.Subscribe(context.Background(), SubscribeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	e := receive(t, s)
+	if e.ID != "e1" || e.Type != EventUpdate || !e.CreationTime.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("event = %+v, want update e1 created at 2024-01-02T03:04:05Z", e)
+	}
+	if len(e.Data) != 1 || e.Data[0].ID != "l1" || e.Data[0].Type != TypeLight {
+		t.Fatalf("event data = %+v, want light l1", e.Data)
+	}
+	if light, ok := e.Data[0].Resource.(*Light); !ok || light.ID != "l1" || !light.On.On {
+		t.Errorf("event resource = %#v, want light l1 turned on", e.Data[0].Resource)
+	}
+
+	e = receive(t, s)
+	if e.ID != "e2" || e.Type != EventAdd || len(e.Data) != 1 || e.Data[0].Type != "motion" ||
+		e.Data[0].Resource != nil {
+		t.Errorf("event = %+v, want add e2 of an undecoded motion resource", e)
+	}
+	if err = s.Err(); err != nil {
+		t.Errorf("Err() = %v", err)
+	}
+}
+
+func TestSubscribeFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized user", 403)
+	}))
+	defer server.Close()
+	c, err := NewClient(server.URL, "key", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.Subscribe(context.Background(), SubscribeOptions{}); err == nil {
+		t.Fatal("Subscribe() = nil error, want the status of the bridge")
+	}
+	if e, ok := err.(*client.ServiceError); !ok || e.StatusCode != 403 {
+		t.Errorf("Subscribe() = %v, want a 403 *client.ServiceError", err)
+	}
+}
+
+func TestSubscribeReconnects(t *testing.T) {
+	lastEventIDs := make(chan string, 3)
+	c, stop := newEventServer(t, lastEventIDs, sse("1"), sse("2"))
+	defer stop()
+	s, err := c.Subscribe(context.Background(), SubscribeOptions{ReconnectDelay: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if e := receive(t, s); e.ID != "e1" {
+		t.Errorf("event = %+v, want e1", e)
+	}
+	// The stream ended, so the second event comes from a new connection that resumes after the first.
+	if e := receive(t, s); e.ID != "e2" {
+		t.Errorf("event = %+v, want e2", e)
+	}
+	if first, second := <-lastEventIDs, <-lastEventIDs; first != "" || second != "1" {
+		t.Errorf("Last-Event-ID = %q, %q, want none, then 1", first, second)
+	}
+}
+
+func TestSubscribeDropsWhenFull(t *testing.T) {
+	c, stop := newEventServer(t, nil, sse("1")+sse("2")+sse("3"))
+	defer stop()
+	s, err := c.Subscribe(context.Background(), SubscribeOptions{Buffer: 1, DropWhenFull: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	for deadline := time.Now().Add(time.Second); s.Dropped() < 2 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	if dropped := s.Dropped(); dropped != 2 {
+		t.Fatalf("Dropped() = %v, want 2", dropped)
+	}
+	if e := receive(t, s); e.ID != "e1" {
+		t.Errorf("event = %+v, want e1, the event that fit in the buffer", e)
+	}
+}
+
+func TestSubscribeWaitsWhenFull(t *testing.T) {
+	c, stop := newEventServer(t, nil, sse("1")+sse("2")+sse("3")+sse("4"))
+	defer stop()
+	s, err := c.Subscribe(context.Background(), SubscribeOptions{Buffer: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Give the subscription time to fill the buffer and wait to send the next event.
+	time.Sleep(50 * time.Millisecond)
+	for _, id := range []string{"e1", "e2"} {
+		if e := receive(t, s); e.ID != id {
+			t.Errorf("event = %+v, want %v", e, id)
+		}
+	}
+	if dropped := s.Dropped(); dropped != 0 {
+		t.Errorf("Dropped() = %v, want 0", dropped)
+	}
+
+	// Closing stops the subscription even though it is waiting for the receiver.
+	closed := make(chan struct{})
+	go func() {
+		s.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Close() did not return while the subscription waited to send")
+	}
+}
+
+// poll represents the result of one poll of fakeLights.
+type poll struct {
+	lights map[string]message.Light
+	err    error
+}
+
+// fakeLights represents a hue.Lights whose GetAllContext returns the results sent on polls.
+type fakeLights struct {
+	hue.Lights
+	polls chan poll
+}
+
+// GetAllContext returns the next result sent on polls.
+func (f *fakeLights) GetAllContext(ctx context.Context) (map[string]message.Light, error) {
+	select {
+	case p := <-f.polls:
+		return p.lights, p.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestPollV1(t *testing.T) {
+	light := func(name string, on bool) message.Light {
+		l := message.Light{Name: name}
+		l.State.On = on
+		return l
+	}
+	f := &fakeLights{polls: make(chan poll)}
+	s := PollV1(context.Background(), f, SubscribeOptions{ReconnectDelay: time.Millisecond})
+	defer s.Close()
+
+	unreachable := errors.New("unreachable")
+	f.polls <- poll{err: unreachable}
+	// The first successful poll is the baseline, so the light that already exists is not reported as added.
+	f.polls <- poll{lights: map[string]message.Light{"1": light("Lamp", true)}}
+	f.polls <- poll{lights: map[string]message.Light{"1": light("Lamp", false), "2": light("Strip", true)}}
+	f.polls <- poll{lights: map[string]message.Light{"2": light("Strip", true)}}
+	if err := s.Err(); err != unreachable {
+		t.Errorf("Err() = %v, want %v", err, unreachable)
+	}
+
+	ids := []string{}
+	events := []string{}
+	for i := 0; i < 3; i++ {
+		e := receive(t, s)
+		if len(e.Data) != 1 {
+			t.Fatalf("event = %+v, want one light", e)
+		}
+		l, ok := e.Data[0].Resource.(*Light)
+		if !ok {
+			t.Fatalf("event resource = %#v, want a *Light", e.Data[0].Resource)
+		}
+		ids = append(ids, e.ID)
+		events = append(events, fmt.Sprintf("%v %v on=%v", e.Type, e.Data[0].ID, l.On.On))
+	}
+	if want := []string{"1", "2", "3"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("event IDs = %v, want %v", ids, want)
+	}
+	// The add and the update come from the same poll in any order.
+	sort.Strings(events[:2])
+	want := []string{"add /lights/2 on=true", "update /lights/1 on=false", "delete /lights/1 on=false"}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+}
@@ -0,0 +1,268 @@
+package clip
+
+// Resource types of the CLIP v2 API.
+const (
+	TypeLight        = "light"
+	TypeDevice       = "device"
+	TypeRoom         = "room"
+	TypeZone         = "zone"
+	TypeGroupedLight = "grouped_light"
+	TypeScene        = "scene"
+)
+
+// ResourceIdentifier represents a reference from one resource to another.
+type ResourceIdentifier struct {
+	// The ID of the referenced resource.
+	RID string `json:"rid"`
+	// The type of the referenced resource, e.g. light.
+	RType string `json:"rtype"`
+}
+
+// Metadata represents the user configurable attributes of a resource.
+type Metadata struct {
+	// A human readable name of the resource.
+	Name string `json:"name,omitempty"`
+	// The archetype of the resource, e.g. sultan_bulb or living_room.
+	Archetype string `json:"archetype,omitempty"`
+}
+
+// On represents the on/off state of a light.
+type On struct {
+	// On/Off state of the light. On=true, Off=false
+	On bool `json:"on"`
+}
+
+// Dimming represents the brightness of a light.
+type Dimming struct {
+	// Brightness percentage. 0 cannot be set; use On to turn the light off.
+	Brightness float64 `json:"brightness"`
+	// The lowest brightness percentage the light supports.
+	MinDimLevel float64 `json:"min_dim_level,omitempty"`
+}
+
+// XY represents a color in CIE color space. Both x and y are between 0 and 1.
+type XY struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Color represents the color of a light.
+type Color struct {
+	// The color in CIE color space.
+	XY XY `json:"xy"`
+	// The gamut the light supports, identified by letter. Only returned by the bridge.
+	GamutType string `json:"gamut_type,omitempty"`
+}
+
+// MirekSchema represents the color temperatures a light supports.
+type MirekSchema struct {
+	MirekMinimum int `json:"mirek_minimum"`
+	MirekMaximum int `json:"mirek_maximum"`
+}
+
+// ColorTemperature represents the white color temperature of a light.
+type ColorTemperature struct {
+	// The color temperature in mirek, or nil if the light is not in color temperature mode.
+	Mirek *int `json:"mirek"`
+	// Indicates whether Mirek holds a valid temperature. Only returned by the bridge.
+	MirekValid bool `json:"mirek_valid,omitempty"`
+	// The supported color temperatures. Only returned by the bridge.
+	MirekSchema *MirekSchema `json:"mirek_schema,omitempty"`
+}
+
+// Dynamics represents how a light transitions to a new state.
+type Dynamics struct {
+	// Duration of the transition in milliseconds. Only used when setting a state.
+	Duration int `json:"duration,omitempty"`
+	// Speed of dynamic palettes and effects, between 0 and 1.
+	Speed float64 `json:"speed,omitempty"`
+	// The current dynamic status, e.g. dynamic_palette or none. Only returned by the bridge.
+	Status string `json:"status,omitempty"`
+}
+
+// Alert represents the alert effect of a light.
+type Alert struct {
+	// The alert to perform, e.g. breathe. Only used when setting a state.
+	Action string `json:"action,omitempty"`
+	// The alerts the light supports. Only returned by the bridge.
+	ActionValues []string `json:"action_values,omitempty"`
+}
+
+// GradientPoint represents a color on a gradient light strip.
+type GradientPoint struct {
+	Color struct {
+		XY XY `json:"xy"`
+	} `json:"color"`
+}
+
+// Gradient represents the colors of a gradient light strip.
+type Gradient struct {
+	// The colors of the gradient, from the start of the strip to its end.
+	Points []GradientPoint `json:"points"`
+	// The maximum number of points the light supports. Only returned by the bridge.
+	PointsCapable int `json:"points_capable,omitempty"`
+}
+
+// Effects represents the dynamic effect of a light.
+type Effects struct {
+	// The effect to run, e.g. candle, fire or no_effect. Only used when setting a state.
+	Effect string `json:"effect,omitempty"`
+	// The effect that is running. Only returned by the bridge.
+	Status string `json:"status,omitempty"`
+	// The effects the light supports. Only returned by the bridge.
+	EffectValues []string `json:"effect_values,omitempty"`
+}
+
+// Light represents a light resource.
+type Light struct {
+	// Unique ID of the resource.
+	ID string `json:"id"`
+	// The address of the matching v1 resource, e.g. /lights/1.
+	IDV1 string `json:"id_v1,omitempty"`
+	// The device the light belongs to.
+	Owner ResourceIdentifier `json:"owner"`
+	// The name and archetype of the light.
+	Metadata Metadata `json:"metadata"`
+	// The on/off state of the light.
+	On On `json:"on"`
+	// The brightness of the light, if it is dimmable.
+	Dimming *Dimming `json:"dimming,omitempty"`
+	// The color temperature of the light, if it supports white tones.
+	ColorTemperature *ColorTemperature `json:"color_temperature,omitempty"`
+	// The color of the light, if it supports color.
+	Color *Color `json:"color,omitempty"`
+	// The transition state of the light.
+	Dynamics *Dynamics `json:"dynamics,omitempty"`
+	// The alert effects of the light.
+	Alert *Alert `json:"alert,omitempty"`
+	// The colors of the light, if it is a gradient light strip.
+	Gradient *Gradient `json:"gradient,omitempty"`
+	// The dynamic effects of the light, if it supports them.
+	Effects *Effects `json:"effects,omitempty"`
+	// The mode of the light, normal or streaming.
+	Mode string `json:"mode,omitempty"`
+	// The resource type, always light.
+	Type string `json:"type"`
+}
+
+// LightUpdate represents a change to the state of a light or grouped light. Only the non-nil fields are changed.
+type LightUpdate struct {
+	On               *On               `json:"on,omitempty"`
+	Dimming          *Dimming          `json:"dimming,omitempty"`
+	ColorTemperature *ColorTemperature `json:"color_temperature,omitempty"`
+	Color            *Color            `json:"color,omitempty"`
+	Dynamics         *Dynamics         `json:"dynamics,omitempty"`
+	Alert            *Alert            `json:"alert,omitempty"`
+	Gradient         *Gradient         `json:"gradient,omitempty"`
+	Effects          *Effects          `json:"effects,omitempty"`
+}
+
+// ProductData represents the fixed attributes of a device.
+type ProductData struct {
+	// The hardware model of the device.
+	ModelID string `json:"model_id"`
+	// The manufacturer name.
+	ManufacturerName string `json:"manufacturer_name"`
+	// The product name, e.g. Hue color lamp.
+	ProductName string `json:"product_name"`
+	// The archetype of the product.
+	ProductArchetype string `json:"product_archetype"`
+	// Indicates whether the device is Friends of Hue certified.
+	Certified bool `json:"certified"`
+	// An identifier for the software version running on the device.
+	SoftwareVersion string `json:"software_version"`
+}
+
+// Device represents a physical device, such as a bulb or switch, and the services it provides.
+type Device struct {
+	// Unique ID of the resource.
+	ID string `json:"id"`
+	// The address of the matching v1 resource, e.g. /lights/1.
+	IDV1 string `json:"id_v1,omitempty"`
+	// The fixed attributes of the device.
+	ProductData ProductData `json:"product_data"`
+	// The name and archetype of the device.
+	Metadata Metadata `json:"metadata"`
+	// The services the device provides, e.g. its light.
+	Services []ResourceIdentifier `json:"services"`
+	// The resource type, always device.
+	Type string `json:"type"`
+}
+
+// Room represents a room: a group of devices in the same physical area. A device can be in only one room.
+type Room struct {
+	// Unique ID of the resource.
+	ID string `json:"id"`
+	// The address of the matching v1 resource, e.g. /groups/1.
+	IDV1 string `json:"id_v1,omitempty"`
+	// The devices in the room.
+	Children []ResourceIdentifier `json:"children"`
+	// The services of the room, including its grouped light.
+	Services []ResourceIdentifier `json:"services"`
+	// The name and archetype of the room.
+	Metadata Metadata `json:"metadata"`
+	// The resource type, always room.
+	Type string `json:"type"`
+}
+
+// Zone represents a zone: a group of lights that may span rooms. A light can be in any number of zones.
+type Zone struct {
+	// Unique ID of the resource.
+	ID string `json:"id"`
+	// The address of the matching v1 resource, e.g. /groups/1.
+	IDV1 string `json:"id_v1,omitempty"`
+	// The lights in the zone.
+	Children []ResourceIdentifier `json:"children"`
+	// The services of the zone, including its grouped light.
+	Services []ResourceIdentifier `json:"services"`
+	// The name and archetype of the zone.
+	Metadata Metadata `json:"metadata"`
+	// The resource type, always zone.
+	Type string `json:"type"`
+}
+
+// GroupedLight represents the combined state of the lights in a room or zone.
+type GroupedLight struct {
+	// Unique ID of the resource.
+	ID string `json:"id"`
+	// The address of the matching v1 resource, e.g. /groups/1.
+	IDV1 string `json:"id_v1,omitempty"`
+	// The room or zone the grouped light belongs to.
+	Owner ResourceIdentifier `json:"owner"`
+	// Whether any light in the group is on.
+	On *On `json:"on,omitempty"`
+	// The average brightness of the lights in the group.
+	Dimming *Dimming `json:"dimming,omitempty"`
+	// The alert effects of the group.
+	Alert *Alert `json:"alert,omitempty"`
+	// The resource type, always grouped_light.
+	Type string `json:"type"`
+}
+
+// SceneAction represents the state a scene sets on one light.
+type SceneAction struct {
+	// The light the action applies to.
+	Target ResourceIdentifier `json:"target"`
+	// The state of the light.
+	Action LightUpdate `json:"action"`
+}
+
+// Scene represents a stored set of light states for a room or zone.
+type Scene struct {
+	// Unique ID of the resource.
+	ID string `json:"id"`
+	// The address of the matching v1 resource, e.g. /scenes/abc.
+	IDV1 string `json:"id_v1,omitempty"`
+	// The name of the scene.
+	Metadata Metadata `json:"metadata"`
+	// The room or zone the scene belongs to.
+	Group ResourceIdentifier `json:"group"`
+	// The state of each light in the scene.
+	Actions []SceneAction `json:"actions"`
+	// Speed of the dynamic palette, between 0 and 1.
+	Speed float64 `json:"speed,omitempty"`
+	// Indicates whether the scene starts in dynamic mode when recalled.
+	AutoDynamic bool `json:"auto_dynamic,omitempty"`
+	// The resource type, always scene.
+	Type string `json:"type"`
+}
@@ -0,0 +1,379 @@
+// Package dryrun is a hue.Client that never talks to a bridge, so shows can be rehearsed offline. Each request is
+// checked against a schema of the v1 API: the address must be a known resource, the method must be supported by it and
+// each parameter in the body must exist and be in range, e.g. bri between 1 and 254. Lights are given to NewClient,
+// while groups, scenes, schedules, sensors and rules can be created during the dry run. Requests are logged and
+// answered the way the bridge would, with success arrays for changes and the simulated resources for reads, and invalid
+// requests fail with the *message.APIError the bridge would return.
+package dryrun
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/drombosky/disco-dance-party/hue/logging"
+	"github.com/drombosky/disco-dance-party/hue/message"
+)
+
+// Client represents a simulated Philips Hue bridge. It is safe for concurrent use by multiple goroutines.
+type Client struct {
+	mu        sync.Mutex
+	lights    map[string]message.Light
+	groups    map[string]map[string]interface{}
+	nextGroup int
+	resources map[string]map[string]map[string]interface{}
+	nextID    map[string]int
+	logger    logging.Logger
+}
+
+// Option represents a setting of a client created by NewClient.
+type Option func(c *Client)
+
+// WithLogger sets the logger requests are logged to. Defaults to logging nothing.
+func WithLogger(logger logging.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// handler answers a request for a resource with the given ID, or an empty ID for collections.
+type handler func(c *Client, id string, body map[string]interface{}) (resp interface{})
+
+// route represents a resource of the v1 API and the methods it supports.
+type route struct {
+	pattern *regexp.Regexp
+	methods map[string]handler
+}
+
+// routes are the resources the client knows, addressed relative to /api/<username>.
+var routes = []route{
+	{regexp.MustCompile(`^/lights$`), map[string]handler{"GET": getLights, "POST": searchLights}},
+	{regexp.MustCompile(`^/lights/new$`), map[string]handler{"GET": getNewDevices}},
+	{regexp.MustCompile(`^/lights/([^/]+)$`), map[string]handler{"GET": getLight, "PUT": renameLight,
+		"DELETE": deleteLight}},
+	{regexp.MustCompile(`^/lights/([^/]+)/state$`), map[string]handler{"PUT": setLight}},
+	{regexp.MustCompile(`^/groups$`), map[string]handler{"GET": getGroups, "POST": createGroup}},
+	{regexp.MustCompile(`^/groups/([^/]+)$`), map[string]handler{"GET": getGroup, "PUT": updateGroup,
+		"DELETE": deleteGroup}},
+	{regexp.MustCompile(`^/groups/([^/]+)/action$`), map[string]handler{"PUT": setGroupAction}},
+	{regexp.MustCompile(`^/scenes$`), map[string]handler{"GET": scenes.list, "POST": scenes.add}},
+	{regexp.MustCompile(`^/scenes/([^/]+)$`), map[string]handler{"GET": scenes.get, "PUT": scenes.set,
+		"DELETE": scenes.remove}},
+	{regexp.MustCompile(`^/scenes/([^/]+/lightstates/[^/]+)$`), map[string]handler{"PUT": setSceneLightState}},
+	{regexp.MustCompile(`^/schedules$`), map[string]handler{"GET": schedules.list, "POST": schedules.add}},
+	{regexp.MustCompile(`^/schedules/([^/]+)$`), map[string]handler{"GET": schedules.get, "PUT": schedules.set,
+		"DELETE": schedules.remove}},
+	{regexp.MustCompile(`^/sensors$`), map[string]handler{"GET": sensors.list, "POST": addSensor}},
+	{regexp.MustCompile(`^/sensors/new$`), map[string]handler{"GET": getNewDevices}},
+	{regexp.MustCompile(`^/sensors/([^/]+)$`), map[string]handler{"GET": sensors.get, "PUT": sensors.set,
+		"DELETE": sensors.remove}},
+	{regexp.MustCompile(`^/sensors/([^/]+)/state$`), map[string]handler{"PUT": setSensorState}},
+	{regexp.MustCompile(`^/sensors/([^/]+)/config$`), map[string]handler{"PUT": setSensorConfig}},
+	{regexp.MustCompile(`^/rules$`), map[string]handler{"GET": rules.list, "POST": rules.add}},
+	{regexp.MustCompile(`^/rules/([^/]+)$`), map[string]handler{"GET": rules.get, "PUT": rules.set,
+		"DELETE": rules.remove}},
+}
+
+// NewClient returns a simulated bridge with the given lights, keyed by light ID. Changes to the lights are applied to
+// the simulation, so later reads see them.
+func NewClient(lights map[string]message.Light, opts ...Option) (client *Client, err error) {
+	client = &Client{lights: map[string]message.Light{}, groups: map[string]map[string]interface{}{}, nextGroup: 1,
+		resources: map[string]map[string]map[string]interface{}{}, nextID: map[string]int{}}
+	for id, light := range lights {
+		client.lights[id] = light
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+	client.logger = logging.OrNop(client.logger)
+	return client, nil
+}
+
+// Do checks and logs a request and decodes the response the bridge would send into resp. If the bridge would reject
+// the request, or part of it, the first error is returned as a *message.APIError.
+func (c *Client) Do(method string, address string, message []byte, resp interface{}) (err error) {
+	return c.DoContext(context.Background(), method, address, message, resp)
+}
+
+// DoContext is like Do, but returns the error of ctx if it is done.
+func (c *Client) DoContext(ctx context.Context, method string, address string, message []byte,
+	resp interface{}) (err error) {
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+	c.logger.Info(fmt.Sprintf("Dry run: %v %v %v", method, address, string(message)), logging.Fields{
+		logging.Package:   "github.com/drombosky/disco-dance-party/hue/dryrun",
+		logging.Operation: "(c *Client) DoContext",
+		logging.Address:   address,
+		logging.Method:    method,
+		logging.Request:   string(message),
+	})
+
+	body, err := c.handle(method, address, message)
+	if err != nil {
+		return err
+	}
+	if resp != nil {
+		if err = json.Unmarshal(body, resp); err != nil {
+			// A response that does not fit resp, e.g. an error array for a read, is still reported as its error.
+			if apiErr := firstError(body); apiErr != nil {
+				return apiErr
+			}
+			return err
+		}
+	}
+	if apiErr := firstError(body); apiErr != nil {
+		return apiErr
+	}
+	return nil
+}
+
+// handle returns the body of the response to a request.
+func (c *Client) handle(method string, address string, request []byte) (resp []byte, err error) {
+	path := strings.TrimPrefix(address, "/api/<username>")
+	if path == address {
+		return json.Marshal([]interface{}{failure(message.ErrorTypeResourceNotAvailable, address,
+			fmt.Sprintf("resource, %v, not available", address))})
+	}
+	for _, r := range routes {
+		match := r.pattern.FindStringSubmatch(path)
+		if match == nil {
+			continue
+		}
+		h, ok := r.methods[method]
+		if !ok {
+			return json.Marshal([]interface{}{failure(message.ErrorTypeMethodNotAvailable, path,
+				fmt.Sprintf("method, %v, not available for resource, %v", method, path))})
+		}
+		body := map[string]interface{}{}
+		if len(request) > 0 {
+			if err := json.Unmarshal(request, &body); err != nil {
+				return json.Marshal([]interface{}{failure(message.ErrorTypeInvalidJSON, "",
+					"body contains invalid json")})
+			}
+		}
+		if method == "PUT" && len(body) == 0 {
+			return json.Marshal([]interface{}{failure(message.ErrorTypeMissingParameters, path,
+				"invalid/missing parameters in body")})
+		}
+		id := ""
+		if len(match) > 1 {
+			id = match[1]
+		}
+		// The response is encoded while locked since it may be the simulated lights themselves.
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return json.Marshal(h(c, id, body))
+	}
+	return json.Marshal([]interface{}{failure(message.ErrorTypeResourceNotAvailable, path,
+		fmt.Sprintf("resource, %v, not available", path))})
+}
+
+// getLights returns every simulated light.
+func getLights(c *Client, _ string, _ map[string]interface{}) interface{} {
+	return c.lights
+}
+
+// searchLights pretends to start a search for new lights.
+func searchLights(c *Client, _ string, _ map[string]interface{}) interface{} {
+	return []interface{}{success("/lights", "Searching for new devices")}
+}
+
+// getNewDevices reports that no search has found new lights or sensors.
+func getNewDevices(c *Client, _ string, _ map[string]interface{}) interface{} {
+	return map[string]interface{}{"lastscan": "none"}
+}
+
+// getLight returns a simulated light.
+func getLight(c *Client, id string, _ map[string]interface{}) interface{} {
+	light, ok := c.lights[id]
+	if !ok {
+		return []interface{}{notFound("/lights/" + id)}
+	}
+	return light
+}
+
+// renameLight changes the name of a simulated light.
+func renameLight(c *Client, id string, body map[string]interface{}) interface{} {
+	light, ok := c.lights[id]
+	if !ok {
+		return []interface{}{notFound("/lights/" + id)}
+	}
+	results, valid := validate("/lights/"+id, lightAttributes, body)
+	if name, ok := valid["name"].(string); ok {
+		light.Name = name
+		c.lights[id] = light
+	}
+	return results
+}
+
+// deleteLight removes a simulated light.
+func deleteLight(c *Client, id string, _ map[string]interface{}) interface{} {
+	if _, ok := c.lights[id]; !ok {
+		return []interface{}{notFound("/lights/" + id)}
+	}
+	delete(c.lights, id)
+	return []interface{}{map[string]interface{}{"success": fmt.Sprintf("/lights/%v deleted", id)}}
+}
+
+// setLight changes the state of a simulated light.
+func setLight(c *Client, id string, body map[string]interface{}) interface{} {
+	light, ok := c.lights[id]
+	if !ok {
+		return []interface{}{notFound("/lights/" + id)}
+	}
+	results, valid := validate(fmt.Sprintf("/lights/%v/state", id), lightState, body)
+	// Valid parameters are decoded over the current state, so parameters that were not sent keep their value.
+	if state, err := json.Marshal(valid); err == nil {
+		json.Unmarshal(state, &light.State)
+		c.lights[id] = light
+	}
+	return results
+}
+
+// getGroups returns the groups created during the dry run.
+func getGroups(c *Client, _ string, _ map[string]interface{}) interface{} {
+	return c.groups
+}
+
+// createGroup creates a simulated group.
+func createGroup(c *Client, _ string, body map[string]interface{}) interface{} {
+	results, valid := validate("/groups", newGroup, body)
+	if len(results) != len(valid) {
+		return errorsOf(results)
+	}
+	if _, ok := valid["lights"]; !ok {
+		return []interface{}{failure(message.ErrorTypeMissingParameters, "/groups",
+			"invalid/missing parameters in body")}
+	}
+	id := strconv.Itoa(c.nextGroup)
+	c.nextGroup++
+	if _, ok := valid["type"]; !ok {
+		valid["type"] = "LightGroup"
+	}
+	c.groups[id] = valid
+	return []interface{}{map[string]interface{}{"success": map[string]interface{}{"id": id}}}
+}
+
+// getGroup returns a group created during the dry run, or group 0, which holds every light.
+func getGroup(c *Client, id string, _ map[string]interface{}) interface{} {
+	if id == "0" {
+		lights := []string{}
+		for id := range c.lights {
+			lights = append(lights, id)
+		}
+		sort.Strings(lights)
+		return map[string]interface{}{"name": "Group 0", "lights": lights, "type": "LightGroup"}
+	}
+	group, ok := c.groups[id]
+	if !ok {
+		return []interface{}{notFound("/groups/" + id)}
+	}
+	return group
+}
+
+// updateGroup changes the attributes of a simulated group.
+func updateGroup(c *Client, id string, body map[string]interface{}) interface{} {
+	group, ok := c.groups[id]
+	if !ok {
+		return []interface{}{notFound("/groups/" + id)}
+	}
+	results, valid := validate("/groups/"+id, groupAttributes, body)
+	for name, value := range valid {
+		group[name] = value
+	}
+	return results
+}
+
+// deleteGroup removes a simulated group.
+func deleteGroup(c *Client, id string, _ map[string]interface{}) interface{} {
+	if _, ok := c.groups[id]; !ok {
+		return []interface{}{notFound("/groups/" + id)}
+	}
+	delete(c.groups, id)
+	return []interface{}{map[string]interface{}{"success": fmt.Sprintf("/groups/%v deleted", id)}}
+}
+
+// setGroupAction pretends to change the state of the lights in a group.
+func setGroupAction(c *Client, id string, body map[string]interface{}) interface{} {
+	if _, ok := c.groups[id]; !ok && id != "0" {
+		return []interface{}{notFound("/groups/" + id)}
+	}
+	results, _ := validate(fmt.Sprintf("/groups/%v/action", id), groupAction, body)
+	return results
+}
+
+// validate checks each parameter of body against schema and returns a success or error result for each, in the order
+// of their names, and the valid parameters.
+func validate(prefix string, schema map[string]field, body map[string]interface{}) (results []interface{},
+	valid map[string]interface{}) {
+	names := []string{}
+	for name := range body {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results = []interface{}{}
+	valid = map[string]interface{}{}
+	for _, name := range names {
+		value := body[name]
+		address := prefix + "/" + name
+		f, ok := schema[name]
+		switch {
+		case !ok:
+			results = append(results, failure(message.ErrorTypeParameterNotAvailable, address,
+				fmt.Sprintf("parameter, %v, not available", name)))
+		case !f(value):
+			results = append(results, failure(message.ErrorTypeInvalidValue, address,
+				fmt.Sprintf("invalid value, %v, for parameter, %v", format(value), name)))
+		default:
+			results = append(results, success(address, value))
+			valid[name] = value
+		}
+	}
+	return results, valid
+}
+
+// success returns a success result.
+func success(address string, value interface{}) map[string]interface{} {
+	return map[string]interface{}{"success": map[string]interface{}{address: value}}
+}
+
+// failure returns an error result.
+func failure(t message.ErrorType, address, description string) map[string]interface{} {
+	return map[string]interface{}{"error": message.APIError{Type: t, Address: address, Description: description}}
+}
+
+// notFound returns the error result for a resource that does not exist.
+func notFound(address string) map[string]interface{} {
+	return failure(message.ErrorTypeResourceNotAvailable, address, fmt.Sprintf("resource, %v, not available", address))
+}
+
+// errorsOf returns the error results in results.
+func errorsOf(results []interface{}) (errs []interface{}) {
+	for _, r := range results {
+		if _, ok := r.(map[string]interface{})["error"]; ok {
+			errs = append(errs, r)
+		}
+	}
+	return errs
+}
+
+// firstError returns the first error in body if it is an array of results.
+func firstError(body []byte) *message.APIError {
+	results := []message.Result{}
+	if json.Unmarshal(body, &results) != nil {
+		return nil
+	}
+	for _, result := range results {
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
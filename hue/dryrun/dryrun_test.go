@@ -0,0 +1,119 @@
+package dryrun
+
+import (
+	"testing"
+
+	"github.com/drombosky/disco-dance-party/hue/message"
+)
+
+func TestResources(t *testing.T) {
+	c, err := NewClient(map[string]message.Light{"1": {Name: "Lamp"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := []message.Result{}
+	for _, tc := range []struct {
+		address, body string
+	}{
+		{"/api/<username>/scenes", `{"name":"Relax","lights":["1"],"lightstates":{"1":{"on":true,"bri":100}}}`},
+		{"/api/<username>/schedules", `{"command":{"address":"/api/<username>/groups/0/action","method":"PUT",` +
+			`"body":{"on":false}},"localtime":"W124/T20:00:00"}`},
+		{"/api/<username>/sensors", `{"name":"Flag","type":"CLIPGenericFlag","modelid":"flag",` +
+			`"manufacturername":"dryrun","swversion":"1.0","uniqueid":"flag-1"}`},
+		{"/api/<username>/rules", `{"conditions":[{"address":"/sensors/1/state/flag","operator":"eq",` +
+			`"value":"true"}],"actions":[{"address":"/groups/0/action","method":"PUT","body":{"on":true}}]}`},
+	} {
+		if err := c.Do("POST", tc.address, []byte(tc.body), &created); err != nil {
+			t.Errorf("POST %v = %v", tc.address, err)
+		}
+	}
+
+	if err := c.Do("PUT", "/api/<username>/scenes/1/lightstates/1", []byte(`{"xy":[0.3,0.4]}`), nil); err != nil {
+		t.Errorf("set scene light state = %v", err)
+	}
+	if err := c.Do("PUT", "/api/<username>/sensors/1/state", []byte(`{"flag":true}`), nil); err != nil {
+		t.Errorf("set sensor state = %v", err)
+	}
+	scene := map[string]interface{}{}
+	if err := c.Do("GET", "/api/<username>/scenes/1", nil, &scene); err != nil {
+		t.Fatal(err)
+	}
+	state := scene["lightstates"].(map[string]interface{})["1"].(map[string]interface{})
+	if state["bri"] != float64(100) || state["xy"] == nil {
+		t.Errorf("scene light state = %v, want bri and xy", state)
+	}
+
+	for _, tc := range []struct {
+		method, address, body string
+		want                  message.ErrorType
+	}{
+		// Scenes store absolute values, so increments and alerts are rejected.
+		{"PUT", "/api/<username>/scenes/1/lightstates/1", `{"xy_inc":[0,0]}`, message.ErrorTypeParameterNotAvailable},
+		{"PUT", "/api/<username>/scenes/1/lightstates/1", `{"alert":"select"}`, message.ErrorTypeParameterNotAvailable},
+		{"PUT", "/api/<username>/scenes/2/lightstates/1", `{"on":true}`, message.ErrorTypeResourceNotAvailable},
+		{"POST", "/api/<username>/scenes", `{"lights":["1"]}`, message.ErrorTypeMissingParameters},
+		{"POST", "/api/<username>/schedules", `{"command":{"address":"/lights/1/state","method":"GET","body":{}},` +
+			`"localtime":"PT00:01:00"}`, message.ErrorTypeInvalidValue},
+		{"PUT", "/api/<username>/sensors/1/config", `{"battery":101}`, message.ErrorTypeInvalidValue},
+		{"DELETE", "/api/<username>/rules/2", "", message.ErrorTypeResourceNotAvailable},
+		{"POST", "/api/<username>/rules/1", `{}`, message.ErrorTypeMethodNotAvailable},
+	} {
+		err := c.Do(tc.method, tc.address, []byte(tc.body), nil)
+		if apiErr, ok := err.(*message.APIError); !ok || apiErr.Type != tc.want {
+			t.Errorf("%v %v %v = %v, want error type %v", tc.method, tc.address, tc.body, err, tc.want)
+		}
+	}
+
+	if err := c.Do("DELETE", "/api/<username>/rules/1", nil, nil); err != nil {
+		t.Errorf("delete rule = %v", err)
+	}
+	rules := map[string]interface{}{}
+	if err := c.Do("GET", "/api/<username>/rules", nil, &rules); err != nil || len(rules) != 0 {
+		t.Errorf("rules = %v, %v, want none", rules, err)
+	}
+}
+
+func TestValidation(t *testing.T) {
+	c, err := NewClient(map[string]message.Light{"1": {Name: "Lamp"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		method, address, body string
+		// want is the type of the error, or 0 if the request is valid.
+		want message.ErrorType
+	}{
+		{"PUT", "/api/<username>/lights/1/state", `{"bri":1}`, 0},
+		{"PUT", "/api/<username>/lights/1/state", `{"bri":254}`, 0},
+		{"PUT", "/api/<username>/lights/1/state", `{"bri":0}`, message.ErrorTypeInvalidValue},
+		{"PUT", "/api/<username>/lights/1/state", `{"bri":255}`, message.ErrorTypeInvalidValue},
+		{"PUT", "/api/<username>/lights/1/state", `{"bri":100.5}`, message.ErrorTypeInvalidValue},
+		{"PUT", "/api/<username>/lights/1/state", `{"hue":65535}`, 0},
+		{"PUT", "/api/<username>/lights/1/state", `{"hue":65536}`, message.ErrorTypeInvalidValue},
+		{"PUT", "/api/<username>/lights/1/state", `{"xy":[0,1]}`, 0},
+		{"PUT", "/api/<username>/lights/1/state", `{"xy":[1.1,0.3]}`, message.ErrorTypeInvalidValue},
+		{"PUT", "/api/<username>/lights/1/state", `{"xy":[0.3,1.5]}`, message.ErrorTypeInvalidValue},
+		{"PUT", "/api/<username>/lights/1/state", `{"ct":153}`, 0},
+		{"PUT", "/api/<username>/lights/1/state", `{"ct":500}`, 0},
+		{"PUT", "/api/<username>/lights/1/state", `{"ct":152}`, message.ErrorTypeInvalidValue},
+		{"PUT", "/api/<username>/lights/1/state", `{"ct":501}`, message.ErrorTypeInvalidValue},
+		{"PUT", "/api/<username>/groups/0/action", `{"ct":501}`, message.ErrorTypeInvalidValue},
+		{"PUT", "/api/<username>/lights/1/state", `{"flash":true}`, message.ErrorTypeParameterNotAvailable},
+		{"PUT", "/api/<username>/lights/1/state", `{"on":`, message.ErrorTypeInvalidJSON},
+		{"PATCH", "/api/<username>/lights/1/state", `{"on":true}`, message.ErrorTypeMethodNotAvailable},
+		{"GET", "/api/<username>/toasters", "", message.ErrorTypeResourceNotAvailable},
+		{"GET", "/api/<username>/lights/1/toast", "", message.ErrorTypeResourceNotAvailable},
+		{"GET", "/lights", "", message.ErrorTypeResourceNotAvailable},
+	} {
+		err := c.Do(tc.method, tc.address, []byte(tc.body), nil)
+		if tc.want == 0 {
+			if err != nil {
+				t.Errorf("%v %v %v = %v, want it to be valid", tc.method, tc.address, tc.body, err)
+			}
+			continue
+		}
+		if apiErr, ok := err.(*message.APIError); !ok || apiErr.Type != tc.want {
+			t.Errorf("%v %v %v = %v, want error type %v", tc.method, tc.address, tc.body, err, tc.want)
+		}
+	}
+}
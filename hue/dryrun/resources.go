@@ -0,0 +1,165 @@
+package dryrun
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/drombosky/disco-dance-party/hue/message"
+)
+
+// collection represents a kind of resource that is only created during the dry run, such as scenes or rules, and is
+// stored as the parameters it was created with.
+type collection struct {
+	// name is the address of the collection, e.g. "scenes".
+	name string
+	// create is the schema of a resource being created and required the parameters it must have.
+	create   map[string]field
+	required []string
+	// update is the schema of the attributes of a resource.
+	update map[string]field
+	// defaults are the parameters a resource has if it was not created with them.
+	defaults map[string]interface{}
+}
+
+var (
+	scenes = &collection{name: "scenes", create: newScene, required: []string{"name"}, update: sceneAttributes,
+		defaults: map[string]interface{}{"type": "LightScene", "lights": []interface{}{}}}
+	schedules = &collection{name: "schedules", create: newSchedule, required: []string{"command", "localtime"},
+		update: scheduleAttributes, defaults: map[string]interface{}{"status": "enabled"}}
+	sensors = &collection{name: "sensors", create: newSensor, required: []string{"name", "type", "modelid",
+		"manufacturername", "swversion", "uniqueid"}, update: sensorAttributes,
+		defaults: map[string]interface{}{"state": map[string]interface{}{}, "config": map[string]interface{}{}}}
+	rules = &collection{name: "rules", create: newRule, required: []string{"conditions", "actions"},
+		update: ruleAttributes, defaults: map[string]interface{}{"status": "enabled"}}
+)
+
+// resources returns the resources of the collection created during the dry run, keyed by ID.
+func (col *collection) resources(c *Client) map[string]map[string]interface{} {
+	if c.resources[col.name] == nil {
+		c.resources[col.name] = map[string]map[string]interface{}{}
+	}
+	return c.resources[col.name]
+}
+
+// list returns every resource of the collection.
+func (col *collection) list(c *Client, _ string, _ map[string]interface{}) interface{} {
+	return col.resources(c)
+}
+
+// add creates a resource of the collection.
+func (col *collection) add(c *Client, _ string, body map[string]interface{}) interface{} {
+	results, valid := validate("/"+col.name, col.create, body)
+	if len(results) != len(valid) {
+		return errorsOf(results)
+	}
+	for _, name := range col.required {
+		if _, ok := valid[name]; !ok {
+			return []interface{}{failure(message.ErrorTypeMissingParameters, "/"+col.name,
+				"invalid/missing parameters in body")}
+		}
+	}
+	for name, value := range col.defaults {
+		if _, ok := valid[name]; !ok {
+			valid[name] = value
+		}
+	}
+	c.nextID[col.name]++
+	id := strconv.Itoa(c.nextID[col.name])
+	col.resources(c)[id] = valid
+	return []interface{}{map[string]interface{}{"success": map[string]interface{}{"id": id}}}
+}
+
+// get returns a resource of the collection.
+func (col *collection) get(c *Client, id string, _ map[string]interface{}) interface{} {
+	resource, ok := col.resources(c)[id]
+	if !ok {
+		return []interface{}{notFound(fmt.Sprintf("/%v/%v", col.name, id))}
+	}
+	return resource
+}
+
+// set changes the attributes of a resource of the collection.
+func (col *collection) set(c *Client, id string, body map[string]interface{}) interface{} {
+	return col.setIn(c, id, "", col.update, body)
+}
+
+// setIn changes the parameters of a resource of the collection that are kept in the object named key, or the
+// resource itself if key is empty.
+func (col *collection) setIn(c *Client, id, key string, schema map[string]field,
+	body map[string]interface{}) interface{} {
+	address := fmt.Sprintf("/%v/%v", col.name, id)
+	resource, ok := col.resources(c)[id]
+	if !ok {
+		return []interface{}{notFound(address)}
+	}
+	target := resource
+	if key != "" {
+		address += "/" + key
+		if target, ok = resource[key].(map[string]interface{}); !ok {
+			target = map[string]interface{}{}
+			resource[key] = target
+		}
+	}
+	results, valid := validate(address, schema, body)
+	for name, value := range valid {
+		target[name] = value
+	}
+	return results
+}
+
+// remove deletes a resource of the collection.
+func (col *collection) remove(c *Client, id string, _ map[string]interface{}) interface{} {
+	address := fmt.Sprintf("/%v/%v", col.name, id)
+	if _, ok := col.resources(c)[id]; !ok {
+		return []interface{}{notFound(address)}
+	}
+	delete(col.resources(c), id)
+	return []interface{}{map[string]interface{}{"success": address + " deleted"}}
+}
+
+// setSceneLightState changes the state a scene stores for a light. id is "<scene>/lightstates/<light>".
+func setSceneLightState(c *Client, id string, body map[string]interface{}) interface{} {
+	parts := strings.Split(id, "/")
+	scene, light := parts[0], parts[2]
+	resource, ok := scenes.resources(c)[scene]
+	if !ok {
+		return []interface{}{notFound("/scenes/" + scene)}
+	}
+	if _, ok := c.lights[light]; !ok {
+		return []interface{}{notFound("/lights/" + light)}
+	}
+	states, ok := resource["lightstates"].(map[string]interface{})
+	if !ok {
+		states = map[string]interface{}{}
+		resource["lightstates"] = states
+	}
+	state, ok := states[light].(map[string]interface{})
+	if !ok {
+		state = map[string]interface{}{}
+		states[light] = state
+	}
+	results, valid := validate("/scenes/"+id, sceneLightState, body)
+	for name, value := range valid {
+		state[name] = value
+	}
+	return results
+}
+
+// addSensor creates a CLIP sensor, or pretends to start a search for new sensors if body is empty.
+func addSensor(c *Client, id string, body map[string]interface{}) interface{} {
+	if len(body) == 0 {
+		return []interface{}{success("/sensors", "Searching for new devices")}
+	}
+	return sensors.add(c, id, body)
+}
+
+// setSensorState changes the state of a simulated sensor.
+func setSensorState(c *Client, id string, body map[string]interface{}) interface{} {
+	return sensors.setIn(c, id, "state", sensorState, body)
+}
+
+// setSensorConfig changes the configuration of a simulated sensor.
+func setSensorConfig(c *Client, id string, body map[string]interface{}) interface{} {
+	return sensors.setIn(c, id, "config", sensorConfig, body)
+}
@@ -0,0 +1,329 @@
+package dryrun
+
+import (
+	"fmt"
+	"math"
+)
+
+// field represents the valid values of a parameter of a request body.
+type field func(value interface{}) (ok bool)
+
+// lightState is the schema of a light state, used by /lights/<id>/state and /groups/<id>/action.
+var lightState = map[string]field{
+	"on":             boolean(),
+	"bri":            integer(1, 254),
+	"hue":            integer(0, 65535),
+	"sat":            integer(0, 254),
+	"xy":             pair(0, 1),
+	"ct":             integer(153, 500),
+	"alert":          oneOf("none", "select", "lselect"),
+	"effect":         oneOf("none", "colorloop"),
+	"transitiontime": integer(0, 65535),
+	"bri_inc":        integer(-254, 254),
+	"sat_inc":        integer(-254, 254),
+	"hue_inc":        integer(-65534, 65534),
+	"ct_inc":         integer(-65534, 65534),
+	"xy_inc":         pair(-0.5, 0.5),
+}
+
+// groupAction is the schema of a group action, a light state that can also recall a scene.
+var groupAction = merge(lightState, map[string]field{
+	"scene": text(1, 16),
+})
+
+// lightAttributes is the schema of the attributes of a light.
+var lightAttributes = map[string]field{
+	"name": text(1, 32),
+}
+
+// groupAttributes is the schema of the attributes of a group.
+var groupAttributes = map[string]field{
+	"name":   text(1, 32),
+	"lights": list(),
+	"class":  text(1, 32),
+}
+
+// newGroup is the schema of a group being created.
+var newGroup = merge(groupAttributes, map[string]field{
+	"type": oneOf("LightGroup", "Room", "Luminaire", "LightSource", "Entertainment", "Zone"),
+})
+
+// boolean accepts true and false.
+func boolean() field {
+	return func(value interface{}) bool {
+		_, ok := value.(bool)
+		return ok
+	}
+}
+
+// integer accepts whole numbers between min and max.
+func integer(min, max int) field {
+	return func(value interface{}) bool {
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n) && n >= float64(min) && n <= float64(max)
+	}
+}
+
+// pair accepts an array of two numbers between min and max.
+func pair(min, max float64) field {
+	return func(value interface{}) bool {
+		values, ok := value.([]interface{})
+		if !ok || len(values) != 2 {
+			return false
+		}
+		for _, v := range values {
+			n, ok := v.(float64)
+			if !ok || n < min || n > max {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// oneOf accepts the given strings.
+func oneOf(allowed ...string) field {
+	return func(value interface{}) bool {
+		s, ok := value.(string)
+		for _, a := range allowed {
+			if ok && s == a {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// text accepts strings between min and max characters long.
+func text(min, max int) field {
+	return func(value interface{}) bool {
+		s, ok := value.(string)
+		return ok && len([]rune(s)) >= min && len([]rune(s)) <= max
+	}
+}
+
+// list accepts an array of strings.
+func list() field {
+	return func(value interface{}) bool {
+		values, ok := value.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, v := range values {
+			if _, ok := v.(string); !ok {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// merge returns the parameters of both schemas.
+func merge(a, b map[string]field) map[string]field {
+	m := map[string]field{}
+	for name, f := range a {
+		m[name] = f
+	}
+	for name, f := range b {
+		m[name] = f
+	}
+	return m
+}
+
+// format returns value as the bridge shows it in error descriptions.
+func format(value interface{}) string {
+	if n, ok := value.(float64); ok && n == math.Trunc(n) {
+		return fmt.Sprintf("%d", int64(n))
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// sceneLightState is the schema of the state a scene stores for a light. Scenes store absolute values only, so alerts
+// and increments are not available.
+var sceneLightState = map[string]field{
+	"on":             boolean(),
+	"bri":            integer(1, 254),
+	"hue":            integer(0, 65535),
+	"sat":            integer(0, 254),
+	"xy":             pair(0, 1),
+	"ct":             integer(153, 500),
+	"effect":         oneOf("none", "colorloop"),
+	"transitiontime": integer(0, 65535),
+}
+
+// newScene is the schema of a scene being created.
+var newScene = map[string]field{
+	"name":        text(1, 32),
+	"type":        oneOf("LightScene", "GroupScene"),
+	"group":       text(1, 32),
+	"lights":      list(),
+	"recycle":     boolean(),
+	"appdata":     object(),
+	"picture":     text(0, 16),
+	"lightstates": objectOf(sceneLightState),
+}
+
+// sceneAttributes is the schema of the attributes of a scene.
+var sceneAttributes = map[string]field{
+	"name":            text(1, 32),
+	"lights":          list(),
+	"storelightstate": boolean(),
+}
+
+// scheduleAttributes is the schema of the attributes of a schedule.
+var scheduleAttributes = map[string]field{
+	"name":        text(0, 32),
+	"description": text(0, 64),
+	"command":     command(),
+	"localtime":   text(1, 64),
+	"status":      oneOf("enabled", "disabled"),
+	"autodelete":  boolean(),
+}
+
+// newSchedule is the schema of a schedule being created.
+var newSchedule = merge(scheduleAttributes, map[string]field{
+	"recycle": boolean(),
+})
+
+// sensorAttributes is the schema of the attributes of a sensor.
+var sensorAttributes = map[string]field{
+	"name": text(1, 32),
+}
+
+// newSensor is the schema of a CLIP sensor being created.
+var newSensor = merge(sensorAttributes, map[string]field{
+	"type": oneOf("CLIPSwitch", "CLIPOpenClose", "CLIPPresence", "CLIPTemperature", "CLIPHumidity",
+		"CLIPLightlevel", "CLIPGenericFlag", "CLIPGenericStatus"),
+	"modelid":          text(1, 32),
+	"manufacturername": text(1, 32),
+	"swversion":        text(1, 16),
+	"uniqueid":         text(1, 32),
+	"state":            object(),
+	"config":           object(),
+	"recycle":          boolean(),
+})
+
+// sensorState is the schema of the state of a CLIP sensor.
+var sensorState = map[string]field{
+	"buttonevent": integer(0, 1<<31-1),
+	"open":        boolean(),
+	"presence":    boolean(),
+	"temperature": integer(-27315, 1<<15-1),
+	"humidity":    integer(0, 10000),
+	"lightlevel":  integer(0, 65535),
+	"dark":        boolean(),
+	"daylight":    boolean(),
+	"flag":        boolean(),
+	"status":      integer(-1<<31, 1<<31-1),
+}
+
+// sensorConfig is the schema of the configuration of a sensor.
+var sensorConfig = map[string]field{
+	"on":            boolean(),
+	"reachable":     boolean(),
+	"battery":       integer(0, 100),
+	"alert":         oneOf("none", "select", "lselect"),
+	"ledindication": boolean(),
+	"sensitivity":   integer(0, 255),
+	"tholddark":     integer(0, 65535),
+	"tholdoffset":   integer(1, 65534),
+	"sunriseoffset": integer(-120, 120),
+	"sunsetoffset":  integer(-120, 120),
+	"url":           text(0, 64),
+}
+
+// ruleAttributes is the schema of the attributes of a rule.
+var ruleAttributes = map[string]field{
+	"name":       text(1, 32),
+	"status":     oneOf("enabled", "disabled"),
+	"conditions": conditions(),
+	"actions":    commands(),
+}
+
+// newRule is the schema of a rule being created.
+var newRule = merge(ruleAttributes, map[string]field{
+	"recycle": boolean(),
+})
+
+// object accepts a JSON object.
+func object() field {
+	return func(value interface{}) bool {
+		_, ok := value.(map[string]interface{})
+		return ok
+	}
+}
+
+// objectOf accepts a JSON object whose values are objects with valid parameters of schema, e.g. the light states of a
+// scene keyed by light ID.
+func objectOf(schema map[string]field) field {
+	return func(value interface{}) bool {
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for _, v := range values {
+			parameters, ok := v.(map[string]interface{})
+			if !ok {
+				return false
+			}
+			for name, p := range parameters {
+				if f, ok := schema[name]; !ok || !f(p) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+}
+
+// command accepts a command stored on the bridge, as run by schedules and rules.
+func command() field {
+	address := text(1, 64)
+	method := oneOf("PUT", "POST", "DELETE")
+	body := object()
+	return func(value interface{}) bool {
+		c, ok := value.(map[string]interface{})
+		return ok && len(c) == 3 && address(c["address"]) && method(c["method"]) && body(c["body"])
+	}
+}
+
+// commands accepts between 1 and 8 commands.
+func commands() field {
+	f := command()
+	return func(value interface{}) bool {
+		values, ok := value.([]interface{})
+		if !ok || len(values) < 1 || len(values) > 8 {
+			return false
+		}
+		for _, v := range values {
+			if !f(v) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// conditions accepts between 1 and 8 rule conditions.
+func conditions() field {
+	address := text(1, 64)
+	operator := oneOf("eq", "gt", "lt", "dx", "ddx", "stable", "not stable", "in", "not in")
+	return func(value interface{}) bool {
+		values, ok := value.([]interface{})
+		if !ok || len(values) < 1 || len(values) > 8 {
+			return false
+		}
+		for _, v := range values {
+			c, ok := v.(map[string]interface{})
+			if !ok || !address(c["address"]) || !operator(c["operator"]) {
+				return false
+			}
+			if v, ok := c["value"]; ok {
+				if _, ok := v.(string); !ok {
+					return false
+				}
+			}
+		}
+		return true
+	}
+}
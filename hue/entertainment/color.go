@@ -0,0 +1,98 @@
+package entertainment
+
+import "math"
+
+// white is the x and y of the D65 white point.
+var white = [2]float64{0.3127, 0.3290}
+
+// rgbToXY converts a gamma corrected sRGB color to x and y in CIE color space.
+func rgbToXY(r, g, b float64) (x, y float64) {
+	r, g, b = linear(r), linear(g), linear(b)
+	X := 0.4124*r + 0.3576*g + 0.1805*b
+	Y := 0.2126*r + 0.7152*g + 0.0722*b
+	Z := 0.0193*r + 0.1192*g + 0.9505*b
+	if X+Y+Z == 0 {
+		return white[0], white[1]
+	}
+	return X / (X + Y + Z), Y / (X + Y + Z)
+}
+
+// xyToRGB converts x and y in CIE color space and a brightness between 0 and 1 to a gamma corrected sRGB color. Colors
+// outside sRGB are clipped to its gamut.
+func xyToRGB(x, y, brightness float64) (r, g, b float64) {
+	if y == 0 {
+		x, y = white[0], white[1]
+	}
+	Y := brightness
+	X := Y / y * x
+	Z := Y / y * (1 - x - y)
+	r = math.Max(0, 3.2406*X-1.5372*Y-0.4986*Z)
+	g = math.Max(0, -0.9689*X+1.8758*Y+0.0415*Z)
+	b = math.Max(0, 0.0557*X-0.2040*Y+1.0570*Z)
+	if max := math.Max(r, math.Max(g, b)); max > 1 {
+		r, g, b = r/max, g/max, b/max
+	}
+	return gamma(r), gamma(g), gamma(b)
+}
+
+// hsToXY converts a hue between 0 and 65535 and a saturation between 0 and 254, as used by the v1 API, to x and y in
+// CIE color space.
+func hsToXY(hue, sat int) (x, y float64) {
+	h := math.Mod(float64(hue)/65536*6, 6)
+	s := clamp(float64(sat) / 254)
+	c := s
+	m := 1 - c
+	f := c * (1 - math.Abs(math.Mod(h, 2)-1))
+	var r, g, b float64
+	switch int(h) {
+	case 0:
+		r, g, b = c, f, 0
+	case 1:
+		r, g, b = f, c, 0
+	case 2:
+		r, g, b = 0, c, f
+	case 3:
+		r, g, b = 0, f, c
+	case 4:
+		r, g, b = f, 0, c
+	default:
+		r, g, b = c, 0, f
+	}
+	return rgbToXY(r+m, g+m, b+m)
+}
+
+// ctToXY converts a color temperature in mired to x and y in CIE color space, using the approximation of the Planckian
+// locus by Kim et al.
+func ctToXY(ct int) (x, y float64) {
+	t := math.Max(1667, math.Min(25000, 1e6/float64(ct)))
+	if t <= 4000 {
+		x = -0.2661239e9/(t*t*t) - 0.2343589e6/(t*t) + 0.8776956e3/t + 0.179910
+	} else {
+		x = -3.0258469e9/(t*t*t) + 2.1070379e6/(t*t) + 0.2226347e3/t + 0.240390
+	}
+	switch {
+	case t <= 2222:
+		y = -1.1063814*x*x*x - 1.34811020*x*x + 2.18555832*x - 0.20219683
+	case t <= 4000:
+		y = -0.9549476*x*x*x - 1.37418593*x*x + 2.09137015*x - 0.16748867
+	default:
+		y = 3.0817580*x*x*x - 5.87338670*x*x + 3.75112997*x - 0.37001483
+	}
+	return x, y
+}
+
+// linear removes the sRGB gamma from a component.
+func linear(v float64) float64 {
+	if v > 0.04045 {
+		return math.Pow((v+0.055)/1.055, 2.4)
+	}
+	return v / 12.92
+}
+
+// gamma applies the sRGB gamma to a linear component.
+func gamma(v float64) float64 {
+	if v > 0.0031308 {
+		return 1.055*math.Pow(v, 1/2.4) - 0.055
+	}
+	return 12.92 * v
+}
@@ -0,0 +1,508 @@
+package entertainment
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// Content types of DTLS records.
+const (
+	recordChangeCipherSpec = 20
+	recordAlert            = 21
+	recordHandshake        = 22
+	recordApplicationData  = 23
+)
+
+// Types of DTLS handshake messages.
+const (
+	handshakeClientHello        = 1
+	handshakeServerHello        = 2
+	handshakeHelloVerifyRequest = 3
+	handshakeServerKeyExchange  = 12
+	handshakeServerHelloDone    = 14
+	handshakeClientKeyExchange  = 16
+	handshakeFinished           = 20
+)
+
+// cipherPSKWithAES128GCMSHA256 is TLS_PSK_WITH_AES_128_GCM_SHA256, the only cipher suite the bridge accepts.
+const cipherPSKWithAES128GCMSHA256 = 0x00A8
+
+// Sizes used by the DTLS record and handshake layers.
+const (
+	recordHeaderLength    = 13
+	handshakeHeaderLength = 12
+	explicitNonceLength   = 8
+)
+
+// dtlsVersion is the protocol version of DTLS 1.2 on the wire.
+var dtlsVersion = [2]byte{0xFE, 0xFD}
+
+// errHandshakeTimeout is returned when the bridge does not answer the handshake.
+var errHandshakeTimeout = errors.New("DTLS handshake timed out")
+
+// errBadRecord is returned by parseRecord for a record that does not decrypt. Such records are dropped rather than
+// failing the connection, as RFC 6347 recommends, since anyone can send a datagram to the client.
+var errBadRecord = errors.New("DTLS record does not decrypt")
+
+// HandshakeError represents an error when the DTLS handshake with the bridge fails.
+type HandshakeError struct {
+	Reason string
+}
+
+// Error satisfies the error interface.
+func (e *HandshakeError) Error() string {
+	return fmt.Sprintf("DTLS handshake failed: %v", e.Reason)
+}
+
+// AlertError represents a fatal alert sent by the bridge.
+type AlertError struct {
+	Level       byte
+	Description byte
+}
+
+// Error satisfies the error interface.
+func (e *AlertError) Error() string {
+	return fmt.Sprintf("Received DTLS alert %v (level %v)", e.Description, e.Level)
+}
+
+// DTLSConn represents a DTLS 1.2 client connection secured with a pre-shared key, as used by the entertainment
+// streaming port of the bridge. It only supports TLS_PSK_WITH_AES_128_GCM_SHA256 and does not support renegotiation.
+type DTLSConn struct {
+	conn net.Conn
+
+	// State of the write side. Each epoch has its own record sequence numbers.
+	writeEpoch uint16
+	writeSeq   [2]uint64
+	writeAEAD  cipher.AEAD
+	writeIV    []byte
+
+	// State of the read side.
+	readAEAD cipher.AEAD
+	readIV   []byte
+
+	// State of the handshake.
+	clientRandom []byte
+	serverRandom []byte
+	master       []byte
+	sendSeq      uint16
+	recvSeq      uint16
+	transcript   bytes.Buffer
+
+	// pending holds the records of a datagram that have not been read yet.
+	pending []byte
+}
+
+// flightRecord represents a record of a flight sent to the server. Flights are encoded again each time they are sent,
+// since a retransmitted record must have a new sequence number.
+type flightRecord struct {
+	typ     byte
+	epoch   uint16
+	payload []byte
+}
+
+// handshakeMessage represents a handshake message received from the server.
+type handshakeMessage struct {
+	typ  byte
+	seq  uint16
+	body []byte
+	// raw is the message with its header, as included in the transcript.
+	raw []byte
+}
+
+// ClientDTLS performs a DTLS handshake over conn, a connected UDP socket, using identity and psk, and returns the
+// secured connection. Each flight of the handshake is retransmitted if no answer arrives within timeout.
+func ClientDTLS(conn net.Conn, identity string, psk []byte, timeout time.Duration) (c *DTLSConn, err error) {
+	c = &DTLSConn{conn: conn, clientRandom: make([]byte, 32)}
+	binary.BigEndian.PutUint32(c.clientRandom, uint32(time.Now().Unix()))
+	if _, err = rand.Read(c.clientRandom[4:]); err != nil {
+		return nil, err
+	}
+	if err = c.handshake(identity, psk, timeout); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// handshake runs the client side of the handshake.
+func (c *DTLSConn) handshake(identity string, psk []byte, timeout time.Duration) (err error) {
+	// Flight 1 and 3: a ClientHello, answered by either a HelloVerifyRequest carrying a cookie to echo or the flight
+	// from ServerHello to ServerHelloDone.
+	hello := c.handshakeMessage(handshakeClientHello, c.clientHello(nil))
+	var msgs []handshakeMessage
+	for {
+		msgs, err = c.exchange([]flightRecord{{recordHandshake, 0, hello}}, timeout, handshakeServerHello,
+			handshakeServerHelloDone)
+		if err != nil {
+			return err
+		}
+		if msgs[0].typ != handshakeHelloVerifyRequest {
+			break
+		}
+		body := msgs[0].body
+		if len(body) < 3 || int(body[2]) > len(body)-3 {
+			return &HandshakeError{Reason: "invalid HelloVerifyRequest"}
+		}
+		hello = c.handshakeMessage(handshakeClientHello, c.clientHello(body[3:3+int(body[2])]))
+	}
+	// The transcript starts at the ClientHello that was answered with a ServerHello.
+	c.transcript.Write(hello)
+	for _, msg := range msgs {
+		c.transcript.Write(msg.raw)
+	}
+	if err = c.readServerHello(msgs[0].body); err != nil {
+		return err
+	}
+
+	// Flight 5: ClientKeyExchange, ChangeCipherSpec and Finished.
+	keyExchange := make([]byte, 2+len(identity))
+	binary.BigEndian.PutUint16(keyExchange, uint16(len(identity)))
+	copy(keyExchange[2:], identity)
+	keyExchangeMsg := c.handshakeMessage(handshakeClientKeyExchange, keyExchange)
+	c.transcript.Write(keyExchangeMsg)
+
+	if err = c.deriveKeys(psk); err != nil {
+		return err
+	}
+	sum := sha256.Sum256(c.transcript.Bytes())
+	finishedMsg := c.handshakeMessage(handshakeFinished, prf(c.master, "client finished", sum[:], 12))
+	c.transcript.Write(finishedMsg)
+
+	flight := []flightRecord{
+		{recordHandshake, 0, keyExchangeMsg},
+		{recordChangeCipherSpec, 0, []byte{1}},
+		{recordHandshake, 1, finishedMsg},
+	}
+	c.writeEpoch = 1
+
+	// Flight 6: the server's ChangeCipherSpec and Finished.
+	msgs, err = c.exchange(flight, timeout, handshakeFinished, handshakeFinished)
+	if err == errHandshakeTimeout {
+		// Both sides drop records that do not decrypt, so a wrong key looks like a bridge that does not answer.
+		return &HandshakeError{Reason: "no Finished from the bridge, check the identity and client key"}
+	}
+	if err != nil {
+		return err
+	}
+	sum = sha256.Sum256(c.transcript.Bytes())
+	if !hmac.Equal(msgs[0].body, prf(c.master, "server finished", sum[:], 12)) {
+		return &HandshakeError{Reason: "server Finished does not verify, check the client key"}
+	}
+	return nil
+}
+
+// clientHello returns the body of a ClientHello carrying cookie.
+func (c *DTLSConn) clientHello(cookie []byte) []byte {
+	body := []byte{dtlsVersion[0], dtlsVersion[1]}
+	body = append(body, c.clientRandom...)
+	body = append(body, 0) // No session ID.
+	body = append(body, byte(len(cookie)))
+	body = append(body, cookie...)
+	body = append(body, 0, 2, cipherPSKWithAES128GCMSHA256>>8, cipherPSKWithAES128GCMSHA256&0xFF)
+	body = append(body, 1, 0) // Null compression only.
+	return body
+}
+
+// readServerHello checks the ServerHello and records the server random.
+func (c *DTLSConn) readServerHello(body []byte) error {
+	if len(body) < 35 {
+		return &HandshakeError{Reason: "invalid ServerHello"}
+	}
+	c.serverRandom = append([]byte{}, body[2:34]...)
+	offset := 35 + int(body[34])
+	if len(body) < offset+3 {
+		return &HandshakeError{Reason: "invalid ServerHello"}
+	}
+	if suite := binary.BigEndian.Uint16(body[offset:]); suite != cipherPSKWithAES128GCMSHA256 {
+		return &HandshakeError{Reason: fmt.Sprintf("unsupported cipher suite %#04x", suite)}
+	}
+	return nil
+}
+
+// deriveKeys computes the master secret and the record protection keys from the pre-shared key.
+func (c *DTLSConn) deriveKeys(psk []byte) (err error) {
+	// The PSK premaster secret is N zero bytes followed by the N byte key, each prefixed by its length.
+	premaster := make([]byte, 4+2*len(psk))
+	binary.BigEndian.PutUint16(premaster, uint16(len(psk)))
+	binary.BigEndian.PutUint16(premaster[2+len(psk):], uint16(len(psk)))
+	copy(premaster[4+len(psk):], psk)
+
+	seed := append(append([]byte{}, c.clientRandom...), c.serverRandom...)
+	c.master = prf(premaster, "master secret", seed, 48)
+
+	seed = append(append([]byte{}, c.serverRandom...), c.clientRandom...)
+	keys := prf(c.master, "key expansion", seed, 2*16+2*4)
+	if c.writeAEAD, err = newGCM(keys[:16]); err != nil {
+		return err
+	}
+	if c.readAEAD, err = newGCM(keys[16:32]); err != nil {
+		return err
+	}
+	c.writeIV, c.readIV = keys[32:36], keys[36:40]
+	return nil
+}
+
+// exchange sends a flight and returns the server flight answering it, from a message of type first to a message of
+// type last, or a HelloVerifyRequest. The flight is retransmitted until the answer is complete.
+func (c *DTLSConn) exchange(flight []flightRecord, timeout time.Duration, first, last byte) (msgs []handshakeMessage,
+	err error) {
+	buf := make([]byte, 4096)
+	received := map[uint16]handshakeMessage{}
+	for attempt := 0; attempt < 5; attempt++ {
+		for _, record := range flight {
+			if _, err = c.conn.Write(c.record(record.epoch, record.typ, record.payload)); err != nil {
+				return nil, err
+			}
+		}
+		if err = c.conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+			return nil, err
+		}
+		for {
+			n, err := c.conn.Read(buf)
+			if e, ok := err.(net.Error); ok && e.Timeout() {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			// Received messages outlive buf, so each datagram is copied.
+			datagram, err := c.handshakeMessages(append([]byte{}, buf[:n]...))
+			if err != nil {
+				return nil, err
+			}
+			for _, msg := range datagram {
+				if msg.typ == handshakeHelloVerifyRequest {
+					c.recvSeq = msg.seq + 1
+					return []handshakeMessage{msg}, c.conn.SetReadDeadline(time.Time{})
+				}
+				// Messages from earlier flights are retransmissions.
+				if msg.seq < c.recvSeq {
+					continue
+				}
+				received[msg.seq] = msg
+			}
+			if msgs = flightOf(received, first, last); msgs != nil {
+				c.recvSeq = msgs[len(msgs)-1].seq + 1
+				return msgs, c.conn.SetReadDeadline(time.Time{})
+			}
+		}
+	}
+	return nil, errHandshakeTimeout
+}
+
+// flightOf returns the messages received from a message of type first to a message of type last in sequence, or nil
+// if some are still missing.
+func flightOf(received map[uint16]handshakeMessage, first, last byte) (msgs []handshakeMessage) {
+	start, found := uint16(0), false
+	for seq, msg := range received {
+		if msg.typ == first && (!found || seq < start) {
+			start, found = seq, true
+		}
+	}
+	for seq := start; found; seq++ {
+		msg, ok := received[seq]
+		if !ok {
+			return nil
+		}
+		msgs = append(msgs, msg)
+		if msg.typ == last {
+			return msgs
+		}
+	}
+	return nil
+}
+
+// handshakeMessages parses the handshake messages in a datagram.
+func (c *DTLSConn) handshakeMessages(datagram []byte) (msgs []handshakeMessage, err error) {
+	for len(datagram) > 0 {
+		typ, payload, rest, err := c.parseRecord(datagram)
+		if err == errBadRecord {
+			datagram = rest
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		datagram = rest
+		switch typ {
+		case recordAlert:
+			if len(payload) >= 2 {
+				return nil, &AlertError{Level: payload[0], Description: payload[1]}
+			}
+		case recordHandshake:
+			for len(payload) >= handshakeHeaderLength {
+				length := int(payload[1])<<16 | int(payload[2])<<8 | int(payload[3])
+				fragment := int(payload[9])<<16 | int(payload[10])<<8 | int(payload[11])
+				if fragment != length || len(payload) < handshakeHeaderLength+length {
+					return nil, &HandshakeError{Reason: "fragmented handshake messages are not supported"}
+				}
+				msgs = append(msgs, handshakeMessage{
+					typ:  payload[0],
+					seq:  binary.BigEndian.Uint16(payload[4:]),
+					body: payload[handshakeHeaderLength : handshakeHeaderLength+length],
+					raw:  payload[:handshakeHeaderLength+length],
+				})
+				payload = payload[handshakeHeaderLength+length:]
+			}
+		}
+	}
+	return msgs, nil
+}
+
+// parseRecord parses and, once the read epoch is 1, decrypts the first record of a datagram. A record that does not
+// decrypt returns errBadRecord along with the rest of the datagram.
+func (c *DTLSConn) parseRecord(datagram []byte) (typ byte, payload, rest []byte, err error) {
+	if len(datagram) < recordHeaderLength {
+		return 0, nil, nil, &HandshakeError{Reason: "truncated record"}
+	}
+	length := int(binary.BigEndian.Uint16(datagram[11:]))
+	if len(datagram) < recordHeaderLength+length {
+		return 0, nil, nil, &HandshakeError{Reason: "truncated record"}
+	}
+	header := datagram[:recordHeaderLength]
+	payload = datagram[recordHeaderLength : recordHeaderLength+length]
+	rest = datagram[recordHeaderLength+length:]
+	typ = header[0]
+	if epoch := binary.BigEndian.Uint16(header[3:]); epoch == 0 {
+		return typ, payload, rest, nil
+	}
+
+	if c.readAEAD == nil || len(payload) < explicitNonceLength+c.readAEAD.Overhead() {
+		return 0, nil, nil, &HandshakeError{Reason: "unexpected encrypted record"}
+	}
+	nonce := append(append([]byte{}, c.readIV...), payload[:explicitNonceLength]...)
+	additional := make([]byte, 13)
+	copy(additional, header[3:11])
+	additional[8] = typ
+	copy(additional[9:], header[1:3])
+	binary.BigEndian.PutUint16(additional[11:], uint16(length-explicitNonceLength-c.readAEAD.Overhead()))
+	payload, err = c.readAEAD.Open(nil, nonce, payload[explicitNonceLength:], additional)
+	if err != nil {
+		return 0, nil, rest, errBadRecord
+	}
+	return typ, payload, rest, nil
+}
+
+// handshakeMessage returns a handshake message with its header and advances the message sequence.
+func (c *DTLSConn) handshakeMessage(typ byte, body []byte) []byte {
+	msg := make([]byte, handshakeHeaderLength+len(body))
+	msg[0] = typ
+	msg[1], msg[2], msg[3] = byte(len(body)>>16), byte(len(body)>>8), byte(len(body))
+	binary.BigEndian.PutUint16(msg[4:], c.sendSeq)
+	// Fragment offset is zero and the fragment length is the message length.
+	msg[9], msg[10], msg[11] = msg[1], msg[2], msg[3]
+	copy(msg[handshakeHeaderLength:], body)
+	c.sendSeq++
+	return msg
+}
+
+// record returns a record of epoch holding payload, encrypted if epoch is 1, and advances the record sequence of epoch.
+func (c *DTLSConn) record(epoch uint16, typ byte, payload []byte) []byte {
+	sequence := uint64(epoch)<<48 | c.writeSeq[epoch]
+	c.writeSeq[epoch]++
+
+	header := make([]byte, recordHeaderLength)
+	header[0] = typ
+	header[1], header[2] = dtlsVersion[0], dtlsVersion[1]
+	binary.BigEndian.PutUint64(header[3:], sequence)
+	if epoch == 0 {
+		binary.BigEndian.PutUint16(header[11:], uint16(len(payload)))
+		return append(header, payload...)
+	}
+
+	explicit := header[3:11]
+	nonce := append(append([]byte{}, c.writeIV...), explicit...)
+	additional := make([]byte, 13)
+	copy(additional, explicit)
+	additional[8] = typ
+	additional[9], additional[10] = dtlsVersion[0], dtlsVersion[1]
+	binary.BigEndian.PutUint16(additional[11:], uint16(len(payload)))
+	sealed := c.writeAEAD.Seal(append([]byte{}, explicit...), nonce, payload, additional)
+	binary.BigEndian.PutUint16(header[11:], uint16(len(sealed)))
+	return append(header, sealed...)
+}
+
+// Write sends p as a single application data record.
+func (c *DTLSConn) Write(p []byte) (n int, err error) {
+	if _, err = c.conn.Write(c.record(c.writeEpoch, recordApplicationData, p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Read reads the payload of the next application data record.
+func (c *DTLSConn) Read(p []byte) (n int, err error) {
+	for {
+		if len(c.pending) == 0 {
+			buf := make([]byte, 65536)
+			n, err := c.conn.Read(buf)
+			if err != nil {
+				return 0, err
+			}
+			c.pending = buf[:n]
+		}
+		typ, payload, rest, err := c.parseRecord(c.pending)
+		if err == errBadRecord {
+			c.pending = rest
+			continue
+		}
+		if err != nil {
+			c.pending = nil
+			return 0, err
+		}
+		c.pending = rest
+		switch typ {
+		case recordApplicationData:
+			return copy(p, payload), nil
+		case recordAlert:
+			if len(payload) >= 2 && payload[1] == 0 {
+				return 0, io.EOF
+			}
+			if len(payload) >= 2 {
+				return 0, &AlertError{Level: payload[0], Description: payload[1]}
+			}
+		}
+	}
+}
+
+// Close sends a close_notify alert and closes the underlying connection.
+func (c *DTLSConn) Close() error {
+	c.conn.Write(c.record(c.writeEpoch, recordAlert, []byte{1, 0}))
+	return c.conn.Close()
+}
+
+// newGCM returns an AES-GCM AEAD for key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// prf is the TLS 1.2 pseudo-random function with SHA-256.
+func prf(secret []byte, label string, seed []byte, length int) []byte {
+	labelSeed := append([]byte(label), seed...)
+	out := make([]byte, 0, length+sha256.Size)
+	a := labelSeed
+	for len(out) < length {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(a)
+		a = mac.Sum(nil)
+
+		mac = hmac.New(sha256.New, secret)
+		mac.Write(a)
+		mac.Write(labelSeed)
+		out = mac.Sum(out)
+	}
+	return out[:length]
+}
@@ -0,0 +1,236 @@
+package entertainment
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestPRF(t *testing.T) {
+	// The TLS 1.2 PRF test vector for SHA-256 published on the IETF TLS mailing list.
+	secret, _ := hex.DecodeString("9bbe436ba940f017b17652849a71db35")
+	seed, _ := hex.DecodeString("a0ba9f936cda311827a6f796ffd5198c")
+	want := "e3f229ba727be17b8d122620557cd453c2aab21d07c3d495329b52d4e61edb5a6b301791e90d35c9c9a46b4e14baf9af" +
+		"0fa022f7077def17abfd3797c0564bab4fbc91666e9def9b97fce34f796789baa48082d122ee42c5a72e5a5110fff70187347b66"
+	if got := hex.EncodeToString(prf(secret, "test label", seed, 100)); got != want {
+		t.Errorf("prf() = %v, want %v", got, want)
+	}
+}
+
+// pskServer is the server side of a DTLS handshake with a pre-shared key, answering a single client on a loopback UDP
+// port. It drops the first flight holding the client Finished, so the client has to send it again.
+type pskServer struct {
+	conn   net.PacketConn
+	client net.Addr
+	psk    []byte
+	// sequences holds the epoch and sequence number of every record of the client Finished flights.
+	sequences []uint64
+	// received is the application data read after the handshake.
+	received chan []byte
+}
+
+// read reads a datagram from the client.
+func (s *pskServer) read() (datagram []byte, err error) {
+	buf := make([]byte, 4096)
+	s.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, from, err := s.conn.ReadFrom(buf)
+	if err != nil {
+		return nil, err
+	}
+	s.client = from
+	return buf[:n], nil
+}
+
+// write sends records to the client in a single datagram.
+func (s *pskServer) write(records ...[]byte) (err error) {
+	_, err = s.conn.WriteTo(bytes.Join(records, nil), s.client)
+	return err
+}
+
+// handshakeMessage reads a datagram holding a single handshake message of type typ.
+func (s *pskServer) handshakeMessage(srv *DTLSConn, typ byte) (msg handshakeMessage, err error) {
+	datagram, err := s.read()
+	if err != nil {
+		return msg, err
+	}
+	msgs, err := srv.handshakeMessages(datagram)
+	if err != nil {
+		return msg, err
+	}
+	if len(msgs) != 1 || msgs[0].typ != typ {
+		return msg, fmt.Errorf("received %v, want a single handshake message of type %v", msgs, typ)
+	}
+	return msgs[0], nil
+}
+
+// serve runs the handshake and reads one application data record.
+func (s *pskServer) serve() (err error) {
+	srv := &DTLSConn{serverRandom: bytes.Repeat([]byte{7}, 32)}
+	cookie := []byte("cookie")
+
+	if _, err = s.handshakeMessage(srv, handshakeClientHello); err != nil {
+		return err
+	}
+	err = s.write(srv.record(0, recordHandshake, srv.handshakeMessage(handshakeHelloVerifyRequest,
+		append([]byte{0xFE, 0xFF, byte(len(cookie))}, cookie...))))
+	if err != nil {
+		return err
+	}
+
+	hello, err := s.handshakeMessage(srv, handshakeClientHello)
+	if err != nil {
+		return err
+	}
+	if !bytes.Contains(hello.body, cookie) {
+		return fmt.Errorf("second ClientHello does not echo the cookie")
+	}
+	srv.clientRandom = hello.body[2:34]
+	serverHello := []byte{dtlsVersion[0], dtlsVersion[1]}
+	serverHello = append(serverHello, srv.serverRandom...)
+	serverHello = append(serverHello, 0, cipherPSKWithAES128GCMSHA256>>8, cipherPSKWithAES128GCMSHA256&0xFF, 0)
+	serverHelloMsg := srv.handshakeMessage(handshakeServerHello, serverHello)
+	doneMsg := srv.handshakeMessage(handshakeServerHelloDone, nil)
+	srv.transcript.Write(hello.raw)
+	srv.transcript.Write(serverHelloMsg)
+	srv.transcript.Write(doneMsg)
+	if err = s.write(srv.record(0, recordHandshake, append(serverHelloMsg, doneMsg...))); err != nil {
+		return err
+	}
+
+	if err = srv.deriveKeys(s.psk); err != nil {
+		return err
+	}
+	// The server writes with the client read keys and reads with the client write keys.
+	srv.writeAEAD, srv.readAEAD = srv.readAEAD, srv.writeAEAD
+	srv.writeIV, srv.readIV = srv.readIV, srv.writeIV
+
+	// The client sends ClientKeyExchange, ChangeCipherSpec and Finished as separate records, twice.
+	flight := [][]byte{}
+	for i := 0; i < 6; i++ {
+		record, err := s.read()
+		if err != nil {
+			return err
+		}
+		s.sequences = append(s.sequences, binary.BigEndian.Uint64(record[3:11]))
+		flight = append(flight, record)
+	}
+	var keyExchange, finished handshakeMessage
+	for i, record := range flight[3:] {
+		msgs, err := srv.handshakeMessages(record)
+		if err != nil {
+			return fmt.Errorf("record %v of the retransmitted flight: %v", i, err)
+		}
+		for _, msg := range msgs {
+			if msg.typ == handshakeClientKeyExchange {
+				keyExchange = msg
+			} else {
+				finished = msg
+			}
+		}
+	}
+	srv.transcript.Write(keyExchange.raw)
+	sum := sha256.Sum256(srv.transcript.Bytes())
+	if !hmac.Equal(finished.body, prf(srv.master, "client finished", sum[:], 12)) {
+		return fmt.Errorf("client Finished does not verify")
+	}
+	srv.transcript.Write(finished.raw)
+	sum = sha256.Sum256(srv.transcript.Bytes())
+	err = s.write(srv.record(0, recordChangeCipherSpec, []byte{1}), srv.record(1, recordHandshake,
+		srv.handshakeMessage(handshakeFinished, prf(srv.master, "server finished", sum[:], 12))))
+	if err != nil {
+		return err
+	}
+
+	datagram, err := s.read()
+	if err != nil {
+		return err
+	}
+	typ, payload, _, err := srv.parseRecord(datagram)
+	if err != nil || typ != recordApplicationData {
+		return fmt.Errorf("parseRecord() = %v, %x, %v, want application data", typ, payload, err)
+	}
+	s.received <- payload
+	return nil
+}
+
+func TestClientDTLS(t *testing.T) {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	psk, _ := hex.DecodeString("0123456789abcdef0123456789abcdef")
+	server := &pskServer{conn: conn, psk: psk, received: make(chan []byte, 1)}
+	failed := make(chan error, 1)
+	go func() {
+		if err := server.serve(); err != nil {
+			failed <- err
+		}
+	}()
+
+	udp, err := net.Dial("udp4", conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := ClientDTLS(udp, "identity", psk, 100*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if _, err = c.Write([]byte("HueStream")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case payload := <-server.received:
+		if string(payload) != "HueStream" {
+			t.Errorf("server received %q, want %q", payload, "HueStream")
+		}
+	case err = <-failed:
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not receive the application data")
+	}
+
+	// A record sent again must not reuse a sequence number, or the server drops it as a replay.
+	seen := map[uint64]bool{}
+	for _, sequence := range server.sequences {
+		if seen[sequence] {
+			t.Errorf("record sequence %#x was sent twice", sequence)
+		}
+		seen[sequence] = true
+	}
+}
+
+func TestHandshakeMessagesDropsBadRecords(t *testing.T) {
+	key, wrongKey := bytes.Repeat([]byte{1}, 16), bytes.Repeat([]byte{2}, 16)
+	iv := []byte{0, 1, 2, 3}
+	good, bad := &DTLSConn{writeIV: iv}, &DTLSConn{writeIV: iv}
+	c := &DTLSConn{readIV: iv}
+	var err error
+	if good.writeAEAD, err = newGCM(key); err != nil {
+		t.Fatal(err)
+	}
+	if bad.writeAEAD, err = newGCM(wrongKey); err != nil {
+		t.Fatal(err)
+	}
+	if c.readAEAD, err = newGCM(key); err != nil {
+		t.Fatal(err)
+	}
+
+	// A record that does not decrypt is dropped and the records after it in the datagram are still read.
+	datagram := append(bad.record(1, recordHandshake, bad.handshakeMessage(handshakeFinished, []byte("forged"))),
+		good.record(1, recordHandshake, good.handshakeMessage(handshakeFinished, []byte("finished")))...)
+	msgs, err := c.handshakeMessages(datagram)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 1 || string(msgs[0].body) != "finished" {
+		t.Errorf("handshakeMessages() = %v, want only the Finished that decrypts", msgs)
+	}
+}
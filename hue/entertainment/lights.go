@@ -0,0 +1,107 @@
+package entertainment
+
+import (
+	"context"
+	"sync"
+
+	"github.com/drombosky/disco-dance-party/hue"
+	"github.com/drombosky/disco-dance-party/hue/message"
+)
+
+// Lights represents a hue.Lights that sets the state of the lights of an entertainment area through a stream, so code
+// written against hue.Lights can drive them at the frame rate of the stream. Lights that are not in the area and every
+// other method are passed to the embedded hue.Lights.
+type Lights struct {
+	hue.Lights
+	stream   *Stream
+	channels map[string]uint16
+
+	mu     sync.Mutex
+	states map[string]*streamState
+}
+
+// streamState represents the last state set on a streamed light. The hue and saturation are kept so an update setting
+// only one of them is combined with the last value of the other.
+type streamState struct {
+	on         bool
+	x, y       float64
+	hue, sat   int
+	brightness float64
+}
+
+// NewLights returns a hue.Lights that streams the state of the lights in channels, which maps light IDs to their v1
+// light ID or v2 channel ID in the stream, and passes everything else to lights.
+func NewLights(lights hue.Lights, stream *Stream, channels map[string]uint16) *Lights {
+	return &Lights{Lights: lights, stream: stream, channels: channels, states: map[string]*streamState{}}
+}
+
+// Set sets the state of a light from the next frame of the stream. The transition time, alert and effect are ignored
+// since each frame is shown as it is, as are the color temperature and xy increments.
+func (l *Lights) Set(id string, state message.NewLightState) (err error) {
+	return l.SetContext(context.Background(), id, state)
+}
+
+// SetContext is like Set. Streamed lights are set immediately, so ctx only applies to lights that are not streamed. If
+// the stream stopped because sending a frame failed, that error is returned since the state would never be shown.
+func (l *Lights) SetContext(ctx context.Context, id string, state message.NewLightState) (err error) {
+	channel, ok := l.channels[id]
+	if !ok {
+		return l.Lights.SetContext(ctx, id, state)
+	}
+	if err = l.stream.Err(); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	current := l.states[id]
+	if current == nil {
+		current = &streamState{x: white[0], y: white[1], brightness: 1}
+		l.states[id] = current
+	}
+	current.on = state.On
+	switch {
+	case state.Xy != [2]float64{}:
+		current.x, current.y = state.Xy[0], state.Xy[1]
+	case state.Ct != 0:
+		current.x, current.y = ctToXY(state.Ct)
+	case state.Hue != 0 || state.Sat != 0 || state.HueInc != 0 || state.SatInc != 0:
+		if state.Hue != 0 {
+			current.hue = state.Hue
+		}
+		if state.Sat != 0 {
+			current.sat = state.Sat
+		}
+		current.hue = ((current.hue+state.HueInc)%65536 + 65536) % 65536
+		current.sat += state.SatInc
+		if current.sat < 0 {
+			current.sat = 0
+		} else if current.sat > 254 {
+			current.sat = 254
+		}
+		current.x, current.y = hsToXY(current.hue, current.sat)
+	}
+	switch {
+	case state.Bri != 0:
+		current.brightness = clamp(float64(state.Bri) / 254)
+	case state.BriInc != 0:
+		current.brightness = clamp(current.brightness + float64(state.BriInc)/254)
+	}
+	color := l.color(*current)
+	l.mu.Unlock()
+
+	l.stream.Set(channel, color)
+	return nil
+}
+
+// color returns the color of a state in the color space of the stream.
+func (l *Lights) color(state streamState) Color {
+	brightness := state.brightness
+	if !state.on {
+		brightness = 0
+	}
+	if l.stream.config.ColorSpace == XYBrightness {
+		return Color{state.x, state.y, brightness}
+	}
+	r, g, b := xyToRGB(state.x, state.y, brightness)
+	return Color{r, g, b}
+}
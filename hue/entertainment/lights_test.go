@@ -0,0 +1,45 @@
+package entertainment
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/drombosky/disco-dance-party/hue/message"
+)
+
+func TestLightsSetMergesHueAndSaturation(t *testing.T) {
+	stream := &Stream{config: Config{ColorSpace: XYBrightness}, colors: map[uint16]Color{}}
+	l := NewLights(nil, stream, map[string]uint16{"1": 7})
+
+	updates := []struct {
+		state    message.NewLightState
+		hue, sat int
+	}{
+		{message.NewLightState{BasicState: message.BasicState{On: true, Hue: 21845}}, 21845, 0},
+		{message.NewLightState{BasicState: message.BasicState{On: true, Sat: 254}}, 21845, 254},
+		{message.NewLightState{BasicState: message.BasicState{On: true, Hue: 43690}}, 43690, 254},
+		{message.NewLightState{BasicState: message.BasicState{On: true}, SatInc: -54}, 43690, 200},
+		{message.NewLightState{BasicState: message.BasicState{On: true}, HueInc: 30000}, 8154, 200},
+	}
+	for _, u := range updates {
+		if err := l.Set("1", u.state); err != nil {
+			t.Fatal(err)
+		}
+		x, y := hsToXY(u.hue, u.sat)
+		if got, want := stream.colors[7], (Color{x, y, 1}); got != want {
+			t.Errorf("Set(%+v) streamed %v, want %v", u.state, got, want)
+		}
+	}
+}
+
+func TestLightsSetAfterStreamFailed(t *testing.T) {
+	failed := errors.New("write: connection refused")
+	stream := &Stream{config: Config{ColorSpace: XYBrightness}, colors: map[uint16]Color{}, err: failed}
+	l := NewLights(nil, stream, map[string]uint16{"1": 7})
+	if err := l.Set("1", message.NewLightState{BasicState: message.BasicState{On: true}}); err != failed {
+		t.Errorf("Set() = %v, want %v", err, failed)
+	}
+	if _, ok := stream.colors[7]; ok {
+		t.Errorf("Set() streamed %v, want nothing once the stream failed", stream.colors[7])
+	}
+}
@@ -0,0 +1,126 @@
+package entertainment
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// ColorSpace represents how the colors of a HueStream packet are encoded.
+type ColorSpace byte
+
+// Color spaces of HueStream packets.
+const (
+	// RGB colors are red, green and blue.
+	RGB ColorSpace = 0x00
+	// XYBrightness colors are x and y in CIE color space and a brightness.
+	XYBrightness ColorSpace = 0x01
+)
+
+// Maximum number of lights or channels in a packet of each protocol version.
+const (
+	MaxLightsV1   = 10
+	MaxChannelsV2 = 20
+)
+
+// Sizes of the parts of HueStream packets.
+const (
+	headerLength    = 16
+	areaIDLength    = 36
+	lightLengthV1   = 9
+	channelLengthV2 = 7
+)
+
+// Color represents the color of a light or channel in the color space of the stream. Each component is between 0 and
+// 1: red, green and blue in RGB, or x, y and brightness in XYBrightness.
+type Color [3]float64
+
+// Channel represents the color of one light, for protocol version 1, or one channel of an entertainment configuration,
+// for protocol version 2.
+type Channel struct {
+	// The v1 light ID or the v2 channel ID.
+	ID uint16
+	// The color to show.
+	Color Color
+}
+
+// PacketError represents an error when channels cannot be encoded in a HueStream packet.
+type PacketError struct {
+	Version int
+	Reason  string
+}
+
+// Error satisfies the error interface.
+func (e *PacketError) Error() string {
+	return fmt.Sprintf("Invalid HueStream v%v packet: %v", e.Version, e.Reason)
+}
+
+// EncodeV1 returns a HueStream version 1 packet setting the color of each light, for entertainment groups of the v1
+// API.
+func EncodeV1(sequence byte, space ColorSpace, lights []Channel) (packet []byte, err error) {
+	if len(lights) > MaxLightsV1 {
+		return nil, &PacketError{Version: 1, Reason: fmt.Sprintf("%v lights, at most %v", len(lights), MaxLightsV1)}
+	}
+	for _, light := range lights {
+		if light.ID > 0xFF {
+			return nil, &PacketError{Version: 1, Reason: fmt.Sprintf("light ID %v above 255", light.ID)}
+		}
+	}
+	packet = header(1, sequence, space)
+	for _, light := range lights {
+		// Each light is a device type, 0 for a light, its ID and its color.
+		entry := make([]byte, lightLengthV1)
+		binary.BigEndian.PutUint16(entry[1:], light.ID)
+		putColor(entry[3:], light.Color)
+		packet = append(packet, entry...)
+	}
+	return packet, nil
+}
+
+// EncodeV2 returns a HueStream version 2 packet setting the color of each channel of the entertainment configuration
+// with ID areaID, for entertainment configurations of the CLIP v2 API.
+func EncodeV2(sequence byte, space ColorSpace, areaID string, channels []Channel) (packet []byte, err error) {
+	if len(areaID) != areaIDLength {
+		return nil, &PacketError{Version: 2, Reason: fmt.Sprintf("entertainment configuration ID %q is not a UUID", areaID)}
+	}
+	if len(channels) > MaxChannelsV2 {
+		return nil, &PacketError{Version: 2, Reason: fmt.Sprintf("%v channels, at most %v", len(channels), MaxChannelsV2)}
+	}
+	for _, channel := range channels {
+		if channel.ID > 0xFF {
+			return nil, &PacketError{Version: 2, Reason: fmt.Sprintf("channel ID %v above 255", channel.ID)}
+		}
+	}
+	packet = append(header(2, sequence, space), areaID...)
+	for _, channel := range channels {
+		entry := make([]byte, channelLengthV2)
+		entry[0] = byte(channel.ID)
+		putColor(entry[1:], channel.Color)
+		packet = append(packet, entry...)
+	}
+	return packet, nil
+}
+
+// header returns the header shared by both protocol versions.
+func header(version byte, sequence byte, space ColorSpace) []byte {
+	h := make([]byte, headerLength, headerLength+areaIDLength+MaxChannelsV2*channelLengthV2)
+	copy(h, "HueStream")
+	h[9], h[10] = version, 0
+	h[11] = sequence
+	// Bytes 12 and 13 are reserved.
+	h[14] = byte(space)
+	// Byte 15 is reserved.
+	return h
+}
+
+// putColor writes the components of color as 16 bit values.
+func putColor(b []byte, color Color) {
+	for i, c := range color {
+		binary.BigEndian.PutUint16(b[2*i:], uint16(math.Floor(clamp(c)*0xFFFF+0.5)))
+	}
+}
+
+// clamp limits v to between 0 and 1.
+func clamp(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
@@ -0,0 +1,279 @@
+// Package entertainment streams colors to the lights of an entertainment area of a Philips Hue bridge. Streaming sends
+// HueStream packets over a DTLS connection on UDP port 2100, which the bridge applies without the rate limits of the
+// REST API, so animations can run at 25 to 50 frames per second. Streaming needs a client key, the pre-shared key of
+// the DTLS connection, which the bridge only returns when pairing with client.Pairing.PairEntertainment.
+package entertainment
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue"
+	"github.com/drombosky/disco-dance-party/hue/logging"
+)
+
+// Port is the UDP port of the entertainment streaming service of the bridge.
+const Port = 2100
+
+// ErrInvalidRate is returned when the frame rate of a stream is outside the range the bridge supports.
+var ErrInvalidRate = errors.New("Frame rate must be between 25 and 50 frames per second")
+
+// ErrInvalidVersion is returned when the protocol version of a stream is not 1 or 2.
+var ErrInvalidVersion = errors.New("HueStream version must be 1 or 2")
+
+// Config represents how to stream to an entertainment area.
+type Config struct {
+	// Address of the bridge, a host with an optional port. The port defaults to Port.
+	Address string
+	// Username is the username created by pairing. It is the identity of the DTLS connection.
+	Username string
+	// ClientKey is the hex encoded client key returned by client.Pairing.PairEntertainment.
+	ClientKey string
+	// Area is the ID of the entertainment area: a v1 group ID for version 1, or an entertainment configuration ID for
+	// version 2.
+	Area string
+	// Version is the HueStream protocol version, 1 or 2. Version 1 starts the area with the v1 API and version 2 with
+	// the CLIP v2 API, so the hue.Client given to Start must talk to that API. Defaults to 2.
+	Version int
+	// ColorSpace is the color space of the colors set on the stream. Defaults to RGB.
+	ColorSpace ColorSpace
+	// Rate is the number of frames sent per second, between 25 and 50. Defaults to 50.
+	Rate int
+	// Timeout is how long to wait for each flight of the DTLS handshake before sending it again. Defaults to 1 second.
+	Timeout time.Duration
+	// Dial opens the UDP connection to address. Defaults to net.Dial, but can be replaced, e.g. to stream to a local
+	// DTLS server.
+	Dial func(address string) (net.Conn, error)
+	// Logger is logged to. Defaults to logging nothing.
+	Logger logging.Logger
+}
+
+// Stream represents a stream of colors to an entertainment area. The colors set on the stream are sent every frame,
+// whether or not they changed, since packets may be lost and the bridge ends the stream if it receives nothing for 10
+// seconds.
+type Stream struct {
+	client hue.Client
+	config Config
+	conn   io.WriteCloser
+	stop   chan struct{}
+	done   chan struct{}
+	closed sync.Once
+	// closeErr is the error returned by every call to Close.
+	closeErr error
+
+	mu       sync.Mutex
+	colors   map[uint16]Color
+	sequence byte
+	frames   uint64
+	err      error
+}
+
+// Start starts streaming to an entertainment area: it activates the area through client, opens the DTLS connection and
+// sends a frame at the configured rate until the stream is closed. While streaming, the lights of the area ignore
+// commands sent to the REST API.
+func Start(ctx context.Context, client hue.Client, config Config) (s *Stream, err error) {
+	if config.Version == 0 {
+		config.Version = 2
+	}
+	if config.Version != 1 && config.Version != 2 {
+		return nil, ErrInvalidVersion
+	}
+	if config.Rate == 0 {
+		config.Rate = 50
+	}
+	if config.Rate < 25 || config.Rate > 50 {
+		return nil, ErrInvalidRate
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = time.Second
+	}
+	if config.Dial == nil {
+		config.Dial = func(address string) (net.Conn, error) {
+			return net.Dial("udp", address)
+		}
+	}
+	config.Logger = logging.OrNop(config.Logger)
+	psk, err := hex.DecodeString(config.ClientKey)
+	if err != nil {
+		return nil, err
+	}
+
+	s = &Stream{
+		client: client,
+		config: config,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+		colors: map[uint16]Color{},
+	}
+	if err = s.activate(ctx, true); err != nil {
+		return nil, err
+	}
+	if s.conn, err = s.dial(psk); err != nil {
+		s.activate(ctx, false)
+		return nil, err
+	}
+	go s.run()
+	return s, nil
+}
+
+// dial opens the DTLS connection to the bridge.
+func (s *Stream) dial(psk []byte) (conn io.WriteCloser, err error) {
+	address := s.config.Address
+	if !strings.Contains(address, ":") {
+		address = fmt.Sprintf("%v:%v", address, Port)
+	}
+	udp, err := s.config.Dial(address)
+	if err != nil {
+		return nil, err
+	}
+	conn, err = ClientDTLS(udp, s.config.Username, psk, s.config.Timeout)
+	if err != nil {
+		udp.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// activate starts or stops streaming to the area.
+func (s *Stream) activate(ctx context.Context, active bool) (err error) {
+	var address string
+	var body interface{}
+	if s.config.Version == 1 {
+		type Stream struct {
+			Active bool `json:"active"`
+		}
+		type Body struct {
+			Stream Stream `json:"stream"`
+		}
+		address = fmt.Sprintf("/api/<username>/groups/%v", s.config.Area)
+		body = Body{Stream: Stream{Active: active}}
+	} else {
+		type Body struct {
+			Action string `json:"action"`
+		}
+		address = fmt.Sprintf("/clip/v2/resource/entertainment_configuration/%v", s.config.Area)
+		body = Body{Action: "stop"}
+		if active {
+			body = Body{Action: "start"}
+		}
+	}
+	message, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return s.client.DoContext(ctx, "PUT", address, message, nil)
+}
+
+// Set sets the color of a light, for version 1, or a channel, for version 2, from the next frame on.
+func (s *Stream) Set(id uint16, color Color) {
+	s.mu.Lock()
+	s.colors[id] = color
+	s.mu.Unlock()
+}
+
+// Err returns the error that ended the stream, or nil while it is streaming.
+func (s *Stream) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Frames returns the number of frames sent.
+func (s *Stream) Frames() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.frames
+}
+
+// Close stops streaming, closes the DTLS connection and deactivates the area so the lights accept REST commands again.
+// Calling Close more than once does nothing and returns the error of the first call.
+func (s *Stream) Close() error {
+	s.closed.Do(func() {
+		close(s.stop)
+		<-s.done
+		s.conn.Close()
+		s.closeErr = s.activate(context.Background(), false)
+	})
+	return s.closeErr
+}
+
+// run sends a frame at the configured rate until the stream is closed or sending fails.
+func (s *Stream) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(time.Second / time.Duration(s.config.Rate))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+		if err := s.send(); err != nil {
+			s.config.Logger.Error(fmt.Sprintf("Streaming stopped: %v", err), logging.Fields{
+				logging.Package:   "github.com/drombosky/disco-dance-party/hue/entertainment",
+				logging.Operation: "(s *Stream) run",
+				logging.Error:     err,
+				logging.Area:      s.config.Area,
+			})
+			s.mu.Lock()
+			s.err = err
+			s.mu.Unlock()
+			return
+		}
+	}
+}
+
+// send sends one frame holding every color set, split over as many packets as needed.
+func (s *Stream) send() (err error) {
+	s.mu.Lock()
+	channels := make([]Channel, 0, len(s.colors))
+	for id, color := range s.colors {
+		channels = append(channels, Channel{ID: id, Color: color})
+	}
+	s.sequence++
+	sequence := s.sequence
+	s.frames++
+	s.mu.Unlock()
+	sort.Sort(byID(channels))
+
+	max := MaxChannelsV2
+	if s.config.Version == 1 {
+		max = MaxLightsV1
+	}
+	for len(channels) > 0 {
+		n := len(channels)
+		if n > max {
+			n = max
+		}
+		var packet []byte
+		if s.config.Version == 1 {
+			packet, err = EncodeV1(sequence, s.config.ColorSpace, channels[:n])
+		} else {
+			packet, err = EncodeV2(sequence, s.config.ColorSpace, s.config.Area, channels[:n])
+		}
+		if err != nil {
+			return err
+		}
+		if _, err = s.conn.Write(packet); err != nil {
+			return err
+		}
+		channels = channels[n:]
+	}
+	return nil
+}
+
+// byID sorts channels by ID.
+type byID []Channel
+
+func (c byID) Len() int           { return len(c) }
+func (c byID) Less(i, j int) bool { return c[i].ID < c[j].ID }
+func (c byID) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
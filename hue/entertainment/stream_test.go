@@ -0,0 +1,41 @@
+package entertainment
+
+import (
+	"testing"
+
+	"github.com/drombosky/disco-dance-party/hue/logging"
+	"github.com/drombosky/disco-dance-party/hue/middleware"
+)
+
+// nopConn is a DTLS connection that discards everything written to it.
+type nopConn struct{}
+
+func (nopConn) Write(p []byte) (n int, err error) { return len(p), nil }
+func (nopConn) Close() error                      { return nil }
+
+func TestStreamCloseTwice(t *testing.T) {
+	deactivated := 0
+	client := middleware.RoundTripperFunc(func(req *middleware.Request) *middleware.Response {
+		deactivated++
+		return &middleware.Response{}
+	})
+	s := &Stream{
+		client: client,
+		config: Config{Version: 2, Rate: 50, Area: "1", Logger: logging.OrNop(nil)},
+		conn:   nopConn{},
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+		colors: map[uint16]Color{},
+	}
+	go s.run()
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if deactivated != 1 {
+		t.Errorf("deactivated the area %v times, want 1", deactivated)
+	}
+}
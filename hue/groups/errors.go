@@ -0,0 +1,26 @@
+package groups
+
+import (
+	"fmt"
+
+	"github.com/drombosky/disco-dance-party/hue/message"
+)
+
+// ActionError represents a SetAction that the Philips Hue bridge rejected in whole or in part. Applied and Rejected
+// list the state fields, e.g. "on".
+type ActionError struct {
+	// ID of the group.
+	ID string
+	message.PartialError
+}
+
+// Error satisfies the error interface.
+func (e *ActionError) Error() string {
+	return fmt.Sprintf("Action for group %v: %v", e.ID, e.PartialError.Error())
+}
+
+// newActionError builds an *ActionError from the results of a SetAction. If the results could not be decoded the error
+// returned by the client is reported against the address it refers to.
+func newActionError(id string, results []message.Result, err *message.APIError) *ActionError {
+	return &ActionError{ID: id, PartialError: message.NewPartialError(results, err)}
+}
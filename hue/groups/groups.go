@@ -0,0 +1,146 @@
+// Package groups is a library for interacting with the groups of lights on a Philips Hue bridge, such as rooms, zones
+// and entertainment areas. Setting the action of a group changes every light in it with a single command, which the
+// bridge applies far faster than setting each light, and is limited to 1 command per second rather than 10.
+package groups
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue"
+	"github.com/drombosky/disco-dance-party/hue/internal/resource"
+	"github.com/drombosky/disco-dance-party/hue/logging"
+	"github.com/drombosky/disco-dance-party/hue/message"
+)
+
+// Client represents a client to control groups of lights via the Philips Hue bridge. It is safe for concurrent use by
+// multiple goroutines if its hue.Client is.
+type Client struct {
+	client *resource.Client
+}
+
+// NewClient takes a *hue.Client and returns a client for interacting with groups.
+func NewClient(hueClient hue.Client, opts ...Option) (client *Client, err error) {
+	o := &resource.Options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &Client{client: resource.NewClient(hueClient, o.Logger, "github.com/drombosky/disco-dance-party/hue/groups",
+		logging.Group)}, nil
+}
+
+// GetAll gets a list of all groups that have been created on the Philips Hue bridge. Group 0 is not included.
+func (c *Client) GetAll() (resp map[string]message.Group, err error) {
+	return c.GetAllContext(context.Background())
+}
+
+// GetAllContext is like GetAll, but the request is canceled if ctx is done before it completes.
+func (c *Client) GetAllContext(ctx context.Context) (resp map[string]message.Group, err error) {
+	start := time.Now()
+	resp = map[string]message.Group{}
+	err = c.client.DoContext(ctx, "GET", "/api/<username>/groups", nil, &resp)
+	c.client.Log("(c *Client) GetAllContext", "", start, err, "Get all")
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Get gets the attributes and state of a given group. Group 0 holds every light known to the bridge.
+func (c *Client) Get(id string) (resp *message.Group, err error) {
+	return c.GetContext(context.Background(), id)
+}
+
+// GetContext is like Get, but the request is canceled if ctx is done before it completes.
+func (c *Client) GetContext(ctx context.Context, id string) (resp *message.Group, err error) {
+	start := time.Now()
+	resp = &message.Group{}
+	err = c.client.DoContext(ctx, "GET", fmt.Sprintf("/api/<username>/groups/%v", id), nil, resp)
+	c.client.Log("(c *Client) GetContext", id, start, err, "Get %v", id)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Create creates a group and returns its ID. A light can only be in one room, and the bridge rejects rooms with lights
+// that are already in another room.
+func (c *Client) Create(group message.NewGroup) (id string, err error) {
+	return c.CreateContext(context.Background(), group)
+}
+
+// CreateContext is like Create, but the request is canceled if ctx is done before it completes.
+func (c *Client) CreateContext(ctx context.Context, group message.NewGroup) (id string, err error) {
+	if group.Lights == nil {
+		// The bridge requires the lights parameter, even for an empty room.
+		group.Lights = []string{}
+	}
+	return c.client.Create(ctx, "/api/<username>/groups", group)
+}
+
+// Update changes the name, lights or class of a group. Attributes left empty are not changed.
+func (c *Client) Update(id string, attributes message.GroupAttributes) (err error) {
+	return c.UpdateContext(context.Background(), id, attributes)
+}
+
+// UpdateContext is like Update, but the request is canceled if ctx is done before it completes.
+func (c *Client) UpdateContext(ctx context.Context, id string, attributes message.GroupAttributes) (err error) {
+	body, err := json.Marshal(attributes)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err = c.client.DoContext(ctx, "PUT", fmt.Sprintf("/api/<username>/groups/%v", id), body, nil)
+	c.client.Log("(c *Client) UpdateContext", id, start, err, "Update %v to %v", id, string(body))
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetAction sets the state of every light in a group at once. Group 0 changes every light known to the bridge. If the
+// bridge rejects any of the fields an *ActionError reporting which fields were applied and which were rejected is
+// returned.
+func (c *Client) SetAction(id string, state message.NewLightState) (err error) {
+	return c.SetActionContext(context.Background(), id, state)
+}
+
+// SetActionContext is like SetAction, but the request is canceled if ctx is done before it completes.
+func (c *Client) SetActionContext(ctx context.Context, id string, state message.NewLightState) (err error) {
+	body, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	results := []message.Result{}
+	address := fmt.Sprintf("/api/<username>/groups/%v/action", id)
+	err = c.client.DoContext(ctx, "PUT", address, body, &results)
+	c.client.Log("(c *Client) SetActionContext", id, start, err, "Set action for %v to %v", id, string(body))
+	if err != nil {
+		if apiErr, ok := err.(*message.APIError); ok {
+			return newActionError(id, results, apiErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete deletes a group from the Philips Hue bridge. The lights in the group are not deleted.
+func (c *Client) Delete(id string) (err error) {
+	return c.DeleteContext(context.Background(), id)
+}
+
+// DeleteContext is like Delete, but the request is canceled if ctx is done before it completes.
+func (c *Client) DeleteContext(ctx context.Context, id string) (err error) {
+	start := time.Now()
+	err = c.client.DoContext(ctx, "DELETE", fmt.Sprintf("/api/<username>/groups/%v", id), nil, nil)
+	c.client.Log("(c *Client) DeleteContext", id, start, err, "Delete %v", id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package groups
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/drombosky/disco-dance-party/hue/message"
+	"github.com/drombosky/disco-dance-party/hue/middleware"
+)
+
+// recorder returns a hue.Client that accepts every request and appends its method, address and body to requests.
+func recorder(requests *[]string) middleware.RoundTripperFunc {
+	return func(req *middleware.Request) *middleware.Response {
+		*requests = append(*requests, req.Method+" "+req.Address+" "+string(req.Message))
+		if results, ok := req.Resp.(*[]message.Result); ok {
+			*results = []message.Result{{Success: map[string]json.RawMessage{"id": json.RawMessage(`"1"`)}}}
+		}
+		return &middleware.Response{}
+	}
+}
+
+func TestCreate(t *testing.T) {
+	requests := []string{}
+	c, err := NewClient(recorder(&requests))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, group := range []message.NewGroup{
+		{Name: "Living room", Type: message.GroupTypeRoom},
+		{Name: "Desk", Lights: []string{"1", "2"}},
+	} {
+		id, err := c.Create(group)
+		if err != nil || id != "1" {
+			t.Errorf("Create(%+v) = %v, %v, want 1", group, id, err)
+		}
+	}
+	want := []string{
+		// The bridge requires the lights, even for an empty room.
+		`POST /api/<username>/groups {"name":"Living room","lights":[],"type":"Room"}`,
+		`POST /api/<username>/groups {"name":"Desk","lights":["1","2"]}`,
+	}
+	if !reflect.DeepEqual(requests, want) {
+		t.Errorf("sent %q, want %q", requests, want)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	requests := []string{}
+	c, err := NewClient(recorder(&requests))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, attributes := range []message.GroupAttributes{
+		{Name: "Den"},
+		{Lights: &[]string{"3"}, Class: "Office"},
+		{Lights: &[]string{}},
+	} {
+		if err = c.Update("1", attributes); err != nil {
+			t.Errorf("Update(%+v) = %v", attributes, err)
+		}
+	}
+	want := []string{
+		`PUT /api/<username>/groups/1 {"name":"Den"}`,
+		`PUT /api/<username>/groups/1 {"lights":["3"],"class":"Office"}`,
+		// An empty list is sent, so every light is removed.
+		`PUT /api/<username>/groups/1 {"lights":[]}`,
+	}
+	if !reflect.DeepEqual(requests, want) {
+		t.Errorf("sent %q, want %q", requests, want)
+	}
+}
+
+func TestSetAction(t *testing.T) {
+	requests := []string{}
+	c, err := NewClient(recorder(&requests))
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := message.NewLightState{BasicState: message.BasicState{On: true, Bri: 100}}
+	if err = c.SetAction("0", state); err != nil {
+		t.Fatal(err)
+	}
+	body, err := json.Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"PUT /api/<username>/groups/0/action " + string(body)}; !reflect.DeepEqual(requests, want) {
+		t.Errorf("sent %q, want %q", requests, want)
+	}
+}
+
+func TestSetActionPartiallyApplied(t *testing.T) {
+	rejected := &message.APIError{Type: message.ErrorTypeInvalidValue, Address: "/groups/1/action/bri",
+		Description: "invalid value, 300, for parameter, bri"}
+	bridge := middleware.RoundTripperFunc(func(req *middleware.Request) *middleware.Response {
+		results := req.Resp.(*[]message.Result)
+		*results = []message.Result{
+			{Success: map[string]json.RawMessage{"/groups/1/action/on": json.RawMessage(`true`)}},
+			{Error: rejected},
+		}
+		return &middleware.Response{Err: rejected}
+	})
+	c, err := NewClient(bridge)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.SetAction("1", message.NewLightState{BasicState: message.BasicState{On: true, Bri: 300}})
+	e, ok := err.(*ActionError)
+	if !ok {
+		t.Fatalf("SetAction() = %v, want an *ActionError", err)
+	}
+	if e.ID != "1" || !reflect.DeepEqual(e.Applied, []string{"on"}) ||
+		!reflect.DeepEqual(e.Rejected, map[string]*message.APIError{"bri": rejected}) {
+		t.Errorf("SetAction() = %+v, want on applied and bri rejected", e)
+	}
+}
@@ -0,0 +1,14 @@
+package groups
+
+import (
+	"github.com/drombosky/disco-dance-party/hue/internal/resource"
+	"github.com/drombosky/disco-dance-party/hue/logging"
+)
+
+// Option represents a setting of a client created by NewClient.
+type Option func(o *resource.Options)
+
+// WithLogger sets the logger the client logs to. Defaults to logging nothing.
+func WithLogger(logger logging.Logger) Option {
+	return resource.WithLogger(logger)
+}
@@ -2,28 +2,193 @@
 package hue
 
 import (
+	"context"
+
 	"github.com/drombosky/disco-dance-party/hue/message"
 )
 
 // Client represents an interface for interacting with the Philips Hue bridge.
 type Client interface {
 	Do(method string, address string, message []byte, resp interface{}) (err error)
+	// DoContext is like Do, but the request is canceled if ctx is done before it completes.
+	DoContext(ctx context.Context, method string, address string, message []byte, resp interface{}) (err error)
 }
 
 // Lights represents an interface for a client to control lights via the Hue bridge.
 type Lights interface {
 	// GetAll gets a list of all lights that have been discovered by the Philips Hue bridge.
 	GetAll() (resp map[string]message.Light, err error)
+	// GetAllContext is like GetAll, but the request is canceled if ctx is done before it completes.
+	GetAllContext(ctx context.Context) (resp map[string]message.Light, err error)
 	// GetNew gets a list of lights that were discovered the last time a search for new lights was performed. The list of
 	// new lights is always deleted when a new search is started.
 	GetNew() (resp *message.GetNewResp, err error)
+	// GetNewContext is like GetNew, but the request is canceled if ctx is done before it completes.
+	GetNewContext(ctx context.Context) (resp *message.GetNewResp, err error)
 	// Get gets the attributes and state of a given light.
 	Get(id string) (resp *message.Light, err error)
+	// GetContext is like Get, but the request is canceled if ctx is done before it completes.
+	GetContext(ctx context.Context, id string) (resp *message.Light, err error)
 	// Rename is used to rename lights. A light can have its name changed when in any state, including when it is
 	// unreachable or off.
 	Rename(id, name string) (err error)
+	// RenameContext is like Rename, but the request is canceled if ctx is done before it completes.
+	RenameContext(ctx context.Context, id, name string) (err error)
 	// Set allows the user to turn the light on and off, modify the hue and effects.
 	Set(id string, state message.NewLightState) (err error)
+	// SetContext is like Set, but the request is canceled if ctx is done before it completes.
+	SetContext(ctx context.Context, id string, state message.NewLightState) (err error)
 	// Delete deletes a light from the Philips Hue bridge.
 	Delete(id string) (err error)
+	// DeleteContext is like Delete, but the request is canceled if ctx is done before it completes.
+	DeleteContext(ctx context.Context, id string) (err error)
+}
+
+// Groups represents an interface for a client to control groups of lights, such as rooms and zones, via the Hue bridge.
+type Groups interface {
+	// GetAll gets a list of all groups that have been created on the Philips Hue bridge.
+	GetAll() (resp map[string]message.Group, err error)
+	// GetAllContext is like GetAll, but the request is canceled if ctx is done before it completes.
+	GetAllContext(ctx context.Context) (resp map[string]message.Group, err error)
+	// Get gets the attributes and state of a given group. Group 0 holds every light known to the bridge.
+	Get(id string) (resp *message.Group, err error)
+	// GetContext is like Get, but the request is canceled if ctx is done before it completes.
+	GetContext(ctx context.Context, id string) (resp *message.Group, err error)
+	// Create creates a group and returns its ID.
+	Create(group message.NewGroup) (id string, err error)
+	// CreateContext is like Create, but the request is canceled if ctx is done before it completes.
+	CreateContext(ctx context.Context, group message.NewGroup) (id string, err error)
+	// Update changes the name, lights or class of a group.
+	Update(id string, attributes message.GroupAttributes) (err error)
+	// UpdateContext is like Update, but the request is canceled if ctx is done before it completes.
+	UpdateContext(ctx context.Context, id string, attributes message.GroupAttributes) (err error)
+	// SetAction sets the state of every light in a group at once.
+	SetAction(id string, state message.NewLightState) (err error)
+	// SetActionContext is like SetAction, but the request is canceled if ctx is done before it completes.
+	SetActionContext(ctx context.Context, id string, state message.NewLightState) (err error)
+	// Delete deletes a group from the Philips Hue bridge. The lights in the group are not deleted.
+	Delete(id string) (err error)
+	// DeleteContext is like Delete, but the request is canceled if ctx is done before it completes.
+	DeleteContext(ctx context.Context, id string) (err error)
+}
+
+// Scenes represents an interface for a client to store and recall scenes via the Hue bridge.
+type Scenes interface {
+	// GetAll gets a list of all scenes stored on the Philips Hue bridge, without their light states.
+	GetAll() (resp map[string]message.Scene, err error)
+	// GetAllContext is like GetAll, but the request is canceled if ctx is done before it completes.
+	GetAllContext(ctx context.Context) (resp map[string]message.Scene, err error)
+	// Get gets the attributes and light states of a given scene.
+	Get(id string) (resp *message.Scene, err error)
+	// GetContext is like Get, but the request is canceled if ctx is done before it completes.
+	GetContext(ctx context.Context, id string) (resp *message.Scene, err error)
+	// Create creates a scene and returns its ID. If the scene has no light states the current state of its lights is
+	// stored.
+	Create(scene message.NewScene) (id string, err error)
+	// CreateContext is like Create, but the request is canceled if ctx is done before it completes.
+	CreateContext(ctx context.Context, scene message.NewScene) (id string, err error)
+	// Update changes the name or lights of a scene, or stores the current state of its lights.
+	Update(id string, attributes message.SceneAttributes) (err error)
+	// UpdateContext is like Update, but the request is canceled if ctx is done before it completes.
+	UpdateContext(ctx context.Context, id string, attributes message.SceneAttributes) (err error)
+	// SetLightState changes the state stored in a scene for one of its lights.
+	SetLightState(id, lightID string, state message.SceneLightState) (err error)
+	// SetLightStateContext is like SetLightState, but the request is canceled if ctx is done before it completes.
+	SetLightStateContext(ctx context.Context, id, lightID string, state message.SceneLightState) (err error)
+	// Delete deletes a scene from the Philips Hue bridge.
+	Delete(id string) (err error)
+	// DeleteContext is like Delete, but the request is canceled if ctx is done before it completes.
+	DeleteContext(ctx context.Context, id string) (err error)
+	// Recall sets the lights of a scene to their stored states through the action of a group.
+	Recall(id, group string) (err error)
+	// RecallContext is like Recall, but the request is canceled if ctx is done before it completes.
+	RecallContext(ctx context.Context, id, group string) (err error)
+}
+
+// Schedules represents an interface for a client to manage the commands the Hue bridge sends at a given time.
+type Schedules interface {
+	// GetAll gets a list of all schedules on the Philips Hue bridge.
+	GetAll() (resp map[string]message.Schedule, err error)
+	// GetAllContext is like GetAll, but the request is canceled if ctx is done before it completes.
+	GetAllContext(ctx context.Context) (resp map[string]message.Schedule, err error)
+	// Get gets the attributes of a given schedule.
+	Get(id string) (resp *message.Schedule, err error)
+	// GetContext is like Get, but the request is canceled if ctx is done before it completes.
+	GetContext(ctx context.Context, id string) (resp *message.Schedule, err error)
+	// Create creates a schedule and returns its ID.
+	Create(schedule message.NewSchedule) (id string, err error)
+	// CreateContext is like Create, but the request is canceled if ctx is done before it completes.
+	CreateContext(ctx context.Context, schedule message.NewSchedule) (id string, err error)
+	// Update changes the attributes of a schedule.
+	Update(id string, attributes message.ScheduleAttributes) (err error)
+	// UpdateContext is like Update, but the request is canceled if ctx is done before it completes.
+	UpdateContext(ctx context.Context, id string, attributes message.ScheduleAttributes) (err error)
+	// Delete deletes a schedule from the Philips Hue bridge.
+	Delete(id string) (err error)
+	// DeleteContext is like Delete, but the request is canceled if ctx is done before it completes.
+	DeleteContext(ctx context.Context, id string) (err error)
+}
+
+// Sensors represents an interface for a client to read and update sensors via the Hue bridge.
+type Sensors interface {
+	// GetAll gets a list of all sensors known to the Philips Hue bridge.
+	GetAll() (resp map[string]message.Sensor, err error)
+	// GetAllContext is like GetAll, but the request is canceled if ctx is done before it completes.
+	GetAllContext(ctx context.Context) (resp map[string]message.Sensor, err error)
+	// GetNew gets the status of the last search for new sensors.
+	GetNew() (resp *message.GetNewResp, err error)
+	// GetNewContext is like GetNew, but the request is canceled if ctx is done before it completes.
+	GetNewContext(ctx context.Context) (resp *message.GetNewResp, err error)
+	// Search starts a search for new sensors.
+	Search() (err error)
+	// SearchContext is like Search, but the request is canceled if ctx is done before it completes.
+	SearchContext(ctx context.Context) (err error)
+	// Get gets the attributes, state and configuration of a given sensor.
+	Get(id string) (resp *message.Sensor, err error)
+	// GetContext is like Get, but the request is canceled if ctx is done before it completes.
+	GetContext(ctx context.Context, id string) (resp *message.Sensor, err error)
+	// Create creates a CLIP sensor and returns its ID.
+	Create(sensor message.NewSensor) (id string, err error)
+	// CreateContext is like Create, but the request is canceled if ctx is done before it completes.
+	CreateContext(ctx context.Context, sensor message.NewSensor) (id string, err error)
+	// Rename changes the name of a sensor.
+	Rename(id, name string) (err error)
+	// RenameContext is like Rename, but the request is canceled if ctx is done before it completes.
+	RenameContext(ctx context.Context, id, name string) (err error)
+	// SetFlag sets the flag of a CLIPGenericFlag sensor.
+	SetFlag(id string, flag bool) (err error)
+	// SetFlagContext is like SetFlag, but the request is canceled if ctx is done before it completes.
+	SetFlagContext(ctx context.Context, id string, flag bool) (err error)
+	// SetStatus sets the status of a CLIPGenericStatus sensor.
+	SetStatus(id string, status int) (err error)
+	// SetStatusContext is like SetStatus, but the request is canceled if ctx is done before it completes.
+	SetStatusContext(ctx context.Context, id string, status int) (err error)
+	// Delete deletes a sensor from the Philips Hue bridge.
+	Delete(id string) (err error)
+	// DeleteContext is like Delete, but the request is canceled if ctx is done before it completes.
+	DeleteContext(ctx context.Context, id string) (err error)
+}
+
+// Rules represents an interface for a client to read and update rules via the Hue bridge.
+type Rules interface {
+	// GetAll gets a list of all rules on the Philips Hue bridge.
+	GetAll() (resp map[string]message.Rule, err error)
+	// GetAllContext is like GetAll, but the request is canceled if ctx is done before it completes.
+	GetAllContext(ctx context.Context) (resp map[string]message.Rule, err error)
+	// Get gets the attributes, conditions and actions of a given rule.
+	Get(id string) (resp *message.Rule, err error)
+	// GetContext is like Get, but the request is canceled if ctx is done before it completes.
+	GetContext(ctx context.Context, id string) (resp *message.Rule, err error)
+	// Create validates a rule against the resources of the bridge, creates it and returns its ID.
+	Create(rule message.NewRule) (id string, err error)
+	// CreateContext is like Create, but the request is canceled if ctx is done before it completes.
+	CreateContext(ctx context.Context, rule message.NewRule) (id string, err error)
+	// Update changes the attributes of a rule.
+	Update(id string, attributes message.RuleAttributes) (err error)
+	// UpdateContext is like Update, but the request is canceled if ctx is done before it completes.
+	UpdateContext(ctx context.Context, id string, attributes message.RuleAttributes) (err error)
+	// Delete deletes a rule from the Philips Hue bridge.
+	Delete(id string) (err error)
+	// DeleteContext is like Delete, but the request is canceled if ctx is done before it completes.
+	DeleteContext(ctx context.Context, id string) (err error)
 }
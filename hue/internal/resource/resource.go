@@ -0,0 +1,99 @@
+// Package resource holds what the clients for each kind of resource on a Philips Hue bridge, such as groups or rules,
+// have in common: their settings, logging each request and creating resources.
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue"
+	"github.com/drombosky/disco-dance-party/hue/logging"
+	"github.com/drombosky/disco-dance-party/hue/message"
+)
+
+// Options represents the settings of a client.
+type Options struct {
+	Logger logging.Logger
+	// Username is the username commands stored on the bridge are addressed to, if the client needs it.
+	Username string
+}
+
+// WithLogger returns an option setting the logger the client logs to. Defaults to logging nothing.
+func WithLogger(logger logging.Logger) func(o *Options) {
+	return func(o *Options) {
+		o.Logger = logger
+	}
+}
+
+// WithUsername returns an option setting the username commands stored on the bridge are addressed to.
+func WithUsername(username string) func(o *Options) {
+	return func(o *Options) {
+		o.Username = username
+	}
+}
+
+// UsernameOf returns the username set by WithUsername, or else the username hueClient talks to the bridge as if it
+// reports it like client.Client does.
+func (o *Options) UsernameOf(hueClient hue.Client) string {
+	if o.Username != "" {
+		return o.Username
+	}
+	if c, ok := hueClient.(interface {
+		Username() string
+	}); ok {
+		return c.Username()
+	}
+	return ""
+}
+
+// Client represents a client for one kind of resource. It is safe for concurrent use by multiple goroutines if its
+// hue.Client is.
+type Client struct {
+	hue.Client
+	logger logging.Logger
+	pkg    string
+	field  string
+}
+
+// NewClient returns a client that sends requests with hueClient and logs them to logger as coming from the package
+// with the import path pkg. The ID of the resource a request is for is logged as field, e.g. logging.Group.
+func NewClient(hueClient hue.Client, logger logging.Logger, pkg, field string) *Client {
+	return &Client{Client: hueClient, logger: logging.OrNop(logger), pkg: pkg, field: field}
+}
+
+// Log logs a request sent at start by operation for the resource with the given ID, or for every resource if id is
+// empty.
+func (c *Client) Log(operation, id string, start time.Time, err error, format string, args ...interface{}) {
+	fields := logging.Fields{
+		logging.Package:   c.pkg,
+		logging.Operation: operation,
+		logging.Latency:   time.Since(start),
+	}
+	if id != "" {
+		fields[c.field] = id
+	}
+	if err != nil {
+		fields[logging.Error] = err
+	}
+	c.logger.Debug(fmt.Sprintf(format, args...), fields)
+}
+
+// Create creates a resource by posting it to address and returns its ID, or message.ErrNoID if the bridge did not
+// return it. The request is logged as sent by CreateContext.
+func (c *Client) Create(ctx context.Context, address string, resource interface{}) (id string, err error) {
+	body, err := json.Marshal(resource)
+	if err != nil {
+		return "", err
+	}
+
+	start := time.Now()
+	results := []message.Result{}
+	err = c.DoContext(ctx, "POST", address, body, &results)
+	c.Log("(c *Client) CreateContext", "", start, err, "Create %v", string(body))
+	if err != nil {
+		return "", err
+	}
+	return message.CreatedID(results)
+}
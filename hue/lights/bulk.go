@@ -0,0 +1,77 @@
+package lights
+
+import (
+	"context"
+	"sync"
+
+	"github.com/drombosky/disco-dance-party/hue"
+	"github.com/drombosky/disco-dance-party/hue/message"
+)
+
+// DefaultConcurrency is the number of lights SetMany sets at once.
+const DefaultConcurrency = 4
+
+// SetMany sets the state of each light in states, at most concurrency at once, and returns the result of each light
+// keyed by light ID: nil if it was set, or the error returned by lights.SetContext. Lights that were not set before
+// ctx was done have the error of ctx.
+func SetMany(ctx context.Context, lights hue.Lights, states map[string]message.NewLightState,
+	concurrency int) (results map[string]error) {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	type result struct {
+		id  string
+		err error
+	}
+	ids := make(chan string)
+	done := make(chan result, len(states))
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency && i < len(states); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range ids {
+				err := ctx.Err()
+				if err == nil {
+					err = lights.SetContext(ctx, id, states[id])
+				}
+				done <- result{id: id, err: err}
+			}
+		}()
+	}
+	for id := range states {
+		ids <- id
+	}
+	close(ids)
+	wg.Wait()
+	close(done)
+
+	results = make(map[string]error, len(states))
+	for r := range done {
+		results[r.id] = r.err
+	}
+	return results
+}
+
+// SetMany sets the state of several lights, DefaultConcurrency at once, and returns the result of each light.
+func (c *Client) SetMany(states map[string]message.NewLightState) (results map[string]error) {
+	return c.SetManyContext(context.Background(), states)
+}
+
+// SetManyContext is like SetMany, but the requests are canceled if ctx is done before they complete.
+func (c *Client) SetManyContext(ctx context.Context,
+	states map[string]message.NewLightState) (results map[string]error) {
+	return SetMany(ctx, c, states, DefaultConcurrency)
+}
+
+// SetMany sets the state of several lights, DefaultConcurrency times the number of bridges at once, and returns the
+// result of each light.
+func (c *MultiClient) SetMany(states map[string]message.NewLightState) (results map[string]error) {
+	return c.SetManyContext(context.Background(), states)
+}
+
+// SetManyContext is like SetMany, but the requests are canceled if ctx is done before they complete.
+func (c *MultiClient) SetManyContext(ctx context.Context,
+	states map[string]message.NewLightState) (results map[string]error) {
+	return SetMany(ctx, c, states, DefaultConcurrency*len(c.bridges))
+}
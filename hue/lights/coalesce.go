@@ -0,0 +1,266 @@
+package lights
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/drombosky/disco-dance-party/hue"
+	"github.com/drombosky/disco-dance-party/hue/logging"
+	"github.com/drombosky/disco-dance-party/hue/message"
+)
+
+// ErrClosed is returned when a state is set on a CoalescingClient that has been closed.
+var ErrClosed = errors.New("Coalescing client closed")
+
+// CoalesceStats represents the counters of a CoalescingClient.
+type CoalesceStats struct {
+	// Queued is the number of states passed to Set.
+	Queued uint64
+	// Sent is the number of states sent to the bridge.
+	Sent uint64
+	// Dropped is the number of states that were merged into a newer state for the same light before being sent.
+	Dropped uint64
+	// Failed is the number of states the bridge did not accept.
+	Failed uint64
+}
+
+// CoalescingClient represents a client that sends light states in the background, keeping only the latest pending
+// state of each light. When states are produced faster than the bridge accepts them, a pending state is merged with
+// the newer one so the bridge always receives the freshest frame. Every other command is passed straight through.
+type CoalescingClient struct {
+	hue.Lights
+	onError func(id string, err error)
+	logger  logging.Logger
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	pending  map[string]message.NewLightState
+	order    []string
+	inflight map[string]bool
+	closed   bool
+	stats    CoalesceStats
+	wg       sync.WaitGroup
+}
+
+// NewCoalescingClient takes a hue.Lights and returns a client that sends light states using the given number of
+// workers. Errors returned by the bridge are passed to onError, which may be nil. Close must be called once the
+// client is no longer used.
+func NewCoalescingClient(lights hue.Lights, workers int, onError func(id string, err error),
+	opts ...Option) (client *CoalescingClient, err error) {
+	o := newOptions(opts)
+	if workers <= 0 {
+		workers = 1
+	}
+	client = &CoalescingClient{
+		Lights:   lights,
+		onError:  onError,
+		logger:   o.logger,
+		pending:  map[string]message.NewLightState{},
+		inflight: map[string]bool{},
+	}
+	client.cond = sync.NewCond(&client.mu)
+	client.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go client.work()
+	}
+	return client, nil
+}
+
+// Set queues a state for a light and returns without waiting for it to be sent. If a state for the light is already
+// pending, the two are merged.
+func (c *CoalescingClient) Set(id string, state message.NewLightState) (err error) {
+	return c.SetContext(context.Background(), id, state)
+}
+
+// SetContext is like Set. The state is sent in the background, so ctx only applies to queuing it.
+func (c *CoalescingClient) SetContext(ctx context.Context, id string, state message.NewLightState) (err error) {
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return ErrClosed
+	}
+
+	c.stats.Queued++
+	if older, ok := c.pending[id]; ok {
+		c.pending[id] = merge(older, state)
+		c.stats.Dropped++
+		return nil
+	}
+	c.pending[id] = state
+	c.order = append(c.order, id)
+	c.cond.Signal()
+	return nil
+}
+
+// Stats returns the counters of the client.
+func (c *CoalescingClient) Stats() CoalesceStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// Close sends the pending states and stops the workers.
+func (c *CoalescingClient) Close() error {
+	c.mu.Lock()
+	c.closed = true
+	c.cond.Broadcast()
+	c.mu.Unlock()
+	c.wg.Wait()
+	return nil
+}
+
+// work sends pending states until the client is closed and nothing is pending.
+func (c *CoalescingClient) work() {
+	defer c.wg.Done()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for {
+		id, ok := c.next()
+		if !ok {
+			if c.closed && len(c.order) == 0 {
+				return
+			}
+			c.cond.Wait()
+			continue
+		}
+
+		// A light only has one state in flight at a time so states are applied in order.
+		state := c.pending[id]
+		delete(c.pending, id)
+		c.inflight[id] = true
+		c.mu.Unlock()
+		err := c.Lights.Set(id, state)
+		c.mu.Lock()
+		delete(c.inflight, id)
+		c.stats.Sent++
+		if err != nil {
+			c.stats.Failed++
+			c.logger.Debug(fmt.Sprintf("Set state for %v failed: %v", id, err), logging.Fields{
+				logging.Package:   "github.com/drombosky/disco-dance-party/hue/lights",
+				logging.Operation: "(c *CoalescingClient) work",
+				logging.Light:     id,
+				logging.Error:     err,
+			})
+			if c.onError != nil {
+				c.mu.Unlock()
+				c.onError(id, err)
+				c.mu.Lock()
+			}
+		}
+		c.cond.Broadcast()
+	}
+}
+
+// next removes and returns the first pending light that has no state in flight. The caller must hold c.mu.
+func (c *CoalescingClient) next() (id string, ok bool) {
+	for i, id := range c.order {
+		if !c.inflight[id] {
+			c.order = append(c.order[:i:i], c.order[i+1:]...)
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// merge returns the state that has the effect of sending older followed by newer. Fields set in newer win. Brightness
+// and color increments in newer are folded into absolute values in older. Hue and saturation are merged separately,
+// but an xy or color temperature set by newer replaces the whole color of older, since it switches the color mode and
+// the bridge gives xy precedence over ct and ct over hue and saturation. Likewise a hue or saturation set by newer
+// drops an xy or color temperature of older.
+func merge(older, newer message.NewLightState) message.NewLightState {
+	merged := newer
+
+	switch {
+	case newer.Bri != 0:
+	case older.Bri != 0:
+		merged.Bri = clamp(older.Bri+newer.BriInc, 1, 254)
+		merged.BriInc = 0
+	default:
+		merged.BriInc = older.BriInc + newer.BriInc
+	}
+
+	switch {
+	case newer.Xy != [2]float64{} || newer.Ct != 0:
+	case newer.Hue != 0 || newer.Sat != 0:
+		merged.Hue, merged.HueInc = mergeHue(older, newer)
+		merged.Sat, merged.SatInc = mergeSat(older, newer)
+	default:
+		merged.Hue, merged.HueInc = mergeHue(older, newer)
+		merged.Sat, merged.SatInc = mergeSat(older, newer)
+		merged.Ct, merged.CtInc = older.Ct, older.CtInc+newer.CtInc
+		if older.Ct != 0 {
+			merged.Ct, merged.CtInc = clamp(older.Ct+newer.CtInc, 153, 500), 0
+		}
+		merged.Xy = older.Xy
+		merged.XyInc = [2]float64{older.XyInc[0] + newer.XyInc[0], older.XyInc[1] + newer.XyInc[1]}
+		if older.Xy != [2]float64{} {
+			for i := range merged.Xy {
+				merged.Xy[i] = clampFloat(older.Xy[i]+newer.XyInc[i], 0, 1)
+			}
+			merged.XyInc = [2]float64{}
+		}
+	}
+
+	// An alert is a one-off effect, so keep one that has not been sent yet.
+	if newer.Alert == "" {
+		merged.Alert = older.Alert
+	}
+	if newer.Effect == "" {
+		merged.Effect = older.Effect
+	}
+	if newer.TransitionTime == 0 {
+		merged.TransitionTime = older.TransitionTime
+	}
+	return merged
+}
+
+// mergeHue returns the hue and hue increment that have the effect of sending older followed by newer.
+func mergeHue(older, newer message.NewLightState) (hue, hueInc int) {
+	switch {
+	case newer.Hue != 0:
+		return newer.Hue, newer.HueInc
+	case older.Hue != 0:
+		return (older.Hue + newer.HueInc%65536 + 65536) % 65536, 0
+	default:
+		return 0, older.HueInc + newer.HueInc
+	}
+}
+
+// mergeSat returns the saturation and saturation increment that have the effect of sending older followed by newer.
+func mergeSat(older, newer message.NewLightState) (sat, satInc int) {
+	switch {
+	case newer.Sat != 0:
+		return newer.Sat, newer.SatInc
+	case older.Sat != 0:
+		return clamp(older.Sat+newer.SatInc, 0, 254), 0
+	default:
+		return 0, older.SatInc + newer.SatInc
+	}
+}
+
+// clamp limits v to the range [min, max].
+func clamp(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// clampFloat limits v to the range [min, max].
+func clampFloat(v, min, max float64) float64 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
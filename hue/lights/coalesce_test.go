@@ -0,0 +1,188 @@
+package lights
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/drombosky/disco-dance-party/hue/message"
+	"github.com/drombosky/disco-dance-party/hue/mockHue"
+)
+
+// state returns a light state with the given basic state and increments.
+func state(basic message.BasicState, incs ...func(s *message.NewLightState)) message.NewLightState {
+	s := message.NewLightState{BasicState: basic}
+	for _, inc := range incs {
+		inc(&s)
+	}
+	return s
+}
+
+// briInc, hueInc, satInc, ctInc, xyInc and transition set a field of a light state that is not in its basic state.
+func briInc(v int) func(s *message.NewLightState) {
+	return func(s *message.NewLightState) { s.BriInc = v }
+}
+
+func hueInc(v int) func(s *message.NewLightState) {
+	return func(s *message.NewLightState) { s.HueInc = v }
+}
+
+func satInc(v int) func(s *message.NewLightState) {
+	return func(s *message.NewLightState) { s.SatInc = v }
+}
+
+func ctInc(v int) func(s *message.NewLightState) {
+	return func(s *message.NewLightState) { s.CtInc = v }
+}
+
+func xyInc(x, y float64) func(s *message.NewLightState) {
+	return func(s *message.NewLightState) { s.XyInc = [2]float64{x, y} }
+}
+
+func transition(v int) func(s *message.NewLightState) {
+	return func(s *message.NewLightState) { s.TransitionTime = v }
+}
+
+func TestMerge(t *testing.T) {
+	xy := [2]float64{0.3, 0.4}
+	newerXy := [2]float64{0.5, 0.4}
+	merges := []struct {
+		name         string
+		older, newer message.NewLightState
+		want         message.NewLightState
+	}{
+		// Every pair of color fields.
+		{"hue then hue", state(message.BasicState{Hue: 1000}), state(message.BasicState{Hue: 2000}),
+			state(message.BasicState{Hue: 2000})},
+		{"hue then sat", state(message.BasicState{Hue: 1000}), state(message.BasicState{Sat: 200}),
+			state(message.BasicState{Hue: 1000, Sat: 200})},
+		{"hue then xy", state(message.BasicState{Hue: 1000}), state(message.BasicState{Xy: newerXy}),
+			state(message.BasicState{Xy: newerXy})},
+		{"hue then ct", state(message.BasicState{Hue: 1000}), state(message.BasicState{Ct: 300}),
+			state(message.BasicState{Ct: 300})},
+		{"sat then hue", state(message.BasicState{Sat: 100}), state(message.BasicState{Hue: 2000}),
+			state(message.BasicState{Hue: 2000, Sat: 100})},
+		{"sat then sat", state(message.BasicState{Sat: 100}), state(message.BasicState{Sat: 200}),
+			state(message.BasicState{Sat: 200})},
+		{"sat then xy", state(message.BasicState{Sat: 100}), state(message.BasicState{Xy: newerXy}),
+			state(message.BasicState{Xy: newerXy})},
+		{"sat then ct", state(message.BasicState{Sat: 100}), state(message.BasicState{Ct: 300}),
+			state(message.BasicState{Ct: 300})},
+		{"xy then hue", state(message.BasicState{Xy: xy}), state(message.BasicState{Hue: 2000}),
+			state(message.BasicState{Hue: 2000})},
+		{"xy then sat", state(message.BasicState{Xy: xy}), state(message.BasicState{Sat: 200}),
+			state(message.BasicState{Sat: 200})},
+		{"xy then xy", state(message.BasicState{Xy: xy}), state(message.BasicState{Xy: newerXy}),
+			state(message.BasicState{Xy: newerXy})},
+		{"xy then ct", state(message.BasicState{Xy: xy}), state(message.BasicState{Ct: 300}),
+			state(message.BasicState{Ct: 300})},
+		{"ct then hue", state(message.BasicState{Ct: 200}), state(message.BasicState{Hue: 2000}),
+			state(message.BasicState{Hue: 2000})},
+		{"ct then sat", state(message.BasicState{Ct: 200}), state(message.BasicState{Sat: 200}),
+			state(message.BasicState{Sat: 200})},
+		{"ct then xy", state(message.BasicState{Ct: 200}), state(message.BasicState{Xy: newerXy}),
+			state(message.BasicState{Xy: newerXy})},
+		{"ct then ct", state(message.BasicState{Ct: 200}), state(message.BasicState{Ct: 300}),
+			state(message.BasicState{Ct: 300})},
+		{"hue and sat then sat", state(message.BasicState{Hue: 1000, Sat: 100}), state(message.BasicState{Sat: 200}),
+			state(message.BasicState{Hue: 1000, Sat: 200})},
+
+		// Increments are folded into absolute values or added up.
+		{"bri then bri_inc", state(message.BasicState{Bri: 100}), state(message.BasicState{}, briInc(-20)),
+			state(message.BasicState{Bri: 80})},
+		{"bri_inc then bri_inc", state(message.BasicState{}, briInc(10)), state(message.BasicState{}, briInc(20)),
+			state(message.BasicState{}, briInc(30))},
+		{"bri then bri_inc past the maximum", state(message.BasicState{Bri: 250}),
+			state(message.BasicState{}, briInc(20)), state(message.BasicState{Bri: 254})},
+		{"hue then hue_inc wraps", state(message.BasicState{Hue: 65000}), state(message.BasicState{}, hueInc(1000)),
+			state(message.BasicState{Hue: 464})},
+		{"hue then sat and hue_inc", state(message.BasicState{Hue: 1000}),
+			state(message.BasicState{Sat: 200}, hueInc(500)), state(message.BasicState{Hue: 1500, Sat: 200})},
+		{"hue_inc then sat", state(message.BasicState{}, hueInc(500)), state(message.BasicState{Sat: 200}),
+			state(message.BasicState{Sat: 200}, hueInc(500))},
+		{"sat then sat_inc", state(message.BasicState{Sat: 200}), state(message.BasicState{}, satInc(100)),
+			state(message.BasicState{Sat: 254})},
+		{"ct then ct_inc", state(message.BasicState{Ct: 200}), state(message.BasicState{}, ctInc(-100)),
+			state(message.BasicState{Ct: 153})},
+		{"xy then xy_inc", state(message.BasicState{Xy: xy}), state(message.BasicState{}, xyInc(0.1, -0.1)),
+			state(message.BasicState{Xy: [2]float64{0.4, 0.3}})},
+		{"xy_inc then xy_inc", state(message.BasicState{}, xyInc(0.1, 0.1)),
+			state(message.BasicState{}, xyInc(0.1, -0.1)), state(message.BasicState{}, xyInc(0.2, 0))},
+
+		// Other fields.
+		{"alert is kept", state(message.BasicState{Alert: "select"}), state(message.BasicState{Bri: 10}),
+			state(message.BasicState{Alert: "select", Bri: 10})},
+		{"effect is replaced", state(message.BasicState{Effect: "colorloop"}),
+			state(message.BasicState{Effect: "none"}), state(message.BasicState{Effect: "none"})},
+		{"transition time is kept", state(message.BasicState{Bri: 10}, transition(10)),
+			state(message.BasicState{Bri: 20}), state(message.BasicState{Bri: 20}, transition(10))},
+		{"on is replaced", state(message.BasicState{On: true, Bri: 10}), state(message.BasicState{On: false}),
+			state(message.BasicState{Bri: 10})},
+	}
+	for _, m := range merges {
+		got := merge(m.older, m.newer)
+		// Floating point increments are compared to the nearest thousandth.
+		for i := range got.Xy {
+			got.Xy[i] = float64(int(got.Xy[i]*1000+0.5)) / 1000
+			got.XyInc[i] = float64(int(got.XyInc[i]*1000+0.5)) / 1000
+		}
+		if !reflect.DeepEqual(got, m.want) {
+			t.Errorf("%v: merge(%+v, %+v) = %+v, want %+v", m.name, m.older, m.newer, got, m.want)
+		}
+	}
+}
+
+func TestCoalescingClientConcurrentSet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The bridge is slow, so states pile up and are merged.
+	var mu sync.Mutex
+	last := map[string]int{}
+	bridge := mockHue.NewMockLights(ctrl)
+	bridge.EXPECT().Set(gomock.Any(), gomock.Any()).AnyTimes().Do(func(id string, state message.NewLightState) {
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		last[id] = state.Bri
+		mu.Unlock()
+	}).Return(nil)
+
+	client, err := NewCoalescingClient(bridge, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lights := []string{"1", "2", "3", "4", "5"}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				client.Set(lights[(i+j)%len(lights)], state(message.BasicState{Bri: 1 + j}))
+				client.Stats()
+			}
+		}(i)
+	}
+	wg.Wait()
+	// The last state set on each light is the one it ends up with.
+	for _, id := range lights {
+		client.Set(id, state(message.BasicState{Bri: 254}))
+	}
+	client.Close()
+
+	stats := client.Stats()
+	if stats.Queued != 20*50+5 || stats.Sent+stats.Dropped != stats.Queued {
+		t.Errorf("Stats() = %+v, want %v queued, each sent or dropped", stats, 20*50+5)
+	}
+	for _, id := range lights {
+		if last[id] != 254 {
+			t.Errorf("light %v was left at brightness %v, want 254", id, last[id])
+		}
+	}
+	if err = client.Set("1", state(message.BasicState{Bri: 1})); err != ErrClosed {
+		t.Errorf("Set() after Close() = %v, want %v", err, ErrClosed)
+	}
+}
@@ -0,0 +1,26 @@
+package lights
+
+import (
+	"fmt"
+
+	"github.com/drombosky/disco-dance-party/hue/message"
+)
+
+// SetError represents a Set that the Philips Hue bridge rejected in whole or in part. Applied and Rejected list the
+// state fields, e.g. "on".
+type SetError struct {
+	// ID of the light.
+	ID string
+	message.PartialError
+}
+
+// Error satisfies the error interface.
+func (e *SetError) Error() string {
+	return fmt.Sprintf("Set state for %v: %v", e.ID, e.PartialError.Error())
+}
+
+// newSetError builds a *SetError from the results of a Set. If the results could not be decoded the error returned by
+// the client is reported against the address it refers to.
+func newSetError(id string, results []message.Result, err *message.APIError) *SetError {
+	return &SetError{ID: id, PartialError: message.NewPartialError(results, err)}
+}
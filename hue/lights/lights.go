@@ -3,32 +3,42 @@
 package lights
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-
-	log "github.com/Sirupsen/logrus"
+	"time"
 
 	"github.com/drombosky/disco-dance-party/hue"
+	"github.com/drombosky/disco-dance-party/hue/internal/resource"
+	"github.com/drombosky/disco-dance-party/hue/logging"
 	"github.com/drombosky/disco-dance-party/hue/message"
 )
 
-// Client represents a client to control lights via the Philips Hue bridge.
+// Client represents a client to control lights via the Philips Hue bridge. It is safe for concurrent use by multiple
+// goroutines if its hue.Client is.
 type Client struct {
-	client hue.Client
+	client *resource.Client
 }
 
 // NewClient takes a *hue.Client and returns a client for interacting with lights.
-func NewClient(hueClient hue.Client) (client *Client, err error) {
-	return &Client{client: hueClient}, nil
+func NewClient(hueClient hue.Client, opts ...Option) (client *Client, err error) {
+	o := newOptions(opts)
+	return &Client{client: resource.NewClient(hueClient, o.logger, "github.com/drombosky/disco-dance-party/hue/lights",
+		logging.Light)}, nil
 }
 
 // GetAll gets a list of all lights that have been discovered by the Philips Hue bridge.
 func (c *Client) GetAll() (resp map[string]message.Light, err error) {
-	log.WithFields(log.Fields{
-		"package": "github.com/drombosky/disco-dance-party/hue/light",
-		"method":  "(c *Client) GetNew",
-	}).Debugf("Get all")
-	if err = c.client.Do("GET", "/api/<username>/lights", nil, resp); err != nil {
+	return c.GetAllContext(context.Background())
+}
+
+// GetAllContext is like GetAll, but the request is canceled if ctx is done before it completes.
+func (c *Client) GetAllContext(ctx context.Context) (resp map[string]message.Light, err error) {
+	start := time.Now()
+	resp = map[string]message.Light{}
+	err = c.client.DoContext(ctx, "GET", "/api/<username>/lights", nil, &resp)
+	c.client.Log("(c *Client) GetAllContext", "", start, err, "Get all")
+	if err != nil {
 		return nil, err
 	}
 	return resp, nil
@@ -37,11 +47,16 @@ func (c *Client) GetAll() (resp map[string]message.Light, err error) {
 // GetNew gets a list of lights that were discovered the last time a search for new lights was performed. The list of
 // new lights is always deleted when a new search is started.
 func (c *Client) GetNew() (resp *message.GetNewResp, err error) {
-	log.WithFields(log.Fields{
-		"package": "github.com/drombosky/disco-dance-party/hue/light",
-		"method":  "(c *Client) GetNew",
-	}).Debugf("Get new")
-	if err = c.client.Do("GET", "/api/<username>/lights/new", nil, resp); err != nil {
+	return c.GetNewContext(context.Background())
+}
+
+// GetNewContext is like GetNew, but the request is canceled if ctx is done before it completes.
+func (c *Client) GetNewContext(ctx context.Context) (resp *message.GetNewResp, err error) {
+	start := time.Now()
+	resp = &message.GetNewResp{}
+	err = c.client.DoContext(ctx, "GET", "/api/<username>/lights/new", nil, resp)
+	c.client.Log("(c *Client) GetNewContext", "", start, err, "Get new")
+	if err != nil {
 		return nil, err
 	}
 	return resp, nil
@@ -49,11 +64,16 @@ func (c *Client) GetNew() (resp *message.GetNewResp, err error) {
 
 // Get gets the attributes and state of a given light.
 func (c *Client) Get(id string) (resp *message.Light, err error) {
-	log.WithFields(log.Fields{
-		"package": "github.com/drombosky/disco-dance-party/hue/light",
-		"method":  "(c *Client) Get",
-	}).Debugf("Get %v", id)
-	if err = c.client.Do("GET", fmt.Sprintf("/api/<username>/lights/%v", id), nil, resp); err != nil {
+	return c.GetContext(context.Background(), id)
+}
+
+// GetContext is like Get, but the request is canceled if ctx is done before it completes.
+func (c *Client) GetContext(ctx context.Context, id string) (resp *message.Light, err error) {
+	start := time.Now()
+	resp = &message.Light{}
+	err = c.client.DoContext(ctx, "GET", fmt.Sprintf("/api/<username>/lights/%v", id), nil, resp)
+	c.client.Log("(c *Client) GetContext", id, start, err, "Get %v", id)
+	if err != nil {
 		return nil, err
 	}
 	return resp, nil
@@ -62,6 +82,11 @@ func (c *Client) Get(id string) (resp *message.Light, err error) {
 // Rename is used to rename lights. A light can have its name changed when in any state, including when it is
 // unreachable or off.
 func (c *Client) Rename(id, name string) (err error) {
+	return c.RenameContext(context.Background(), id, name)
+}
+
+// RenameContext is like Rename, but the request is canceled if ctx is done before it completes.
+func (c *Client) RenameContext(ctx context.Context, id, name string) (err error) {
 	type Body struct {
 		Name string `json:"name"`
 	}
@@ -69,31 +94,38 @@ func (c *Client) Rename(id, name string) (err error) {
 	if err != nil {
 		return err
 	}
-	log.WithFields(log.Fields{
-		"package":  "github.com/drombosky/disco-dance-party/hue/light",
-		"function": "(c *Client) Rename",
-		"request":  string(message),
-	}).Debugf("Rename %v to %v", id, name)
 
-	if err = c.client.Do("PUT", fmt.Sprintf("/api/<username>/lights/%v", id), message, nil); err != nil {
+	start := time.Now()
+	err = c.client.DoContext(ctx, "PUT", fmt.Sprintf("/api/<username>/lights/%v", id), message, nil)
+	c.client.Log("(c *Client) RenameContext", id, start, err, "Rename %v to %v", id, name)
+	if err != nil {
 		return err
 	}
 	return nil
 }
 
-// Set allows the user to turn the light on and off, modify the hue and effects.
+// Set allows the user to turn the light on and off, modify the hue and effects. If the bridge rejects any of the
+// fields a *SetError reporting which fields were applied and which were rejected is returned.
 func (c *Client) Set(id string, state message.NewLightState) (err error) {
-	message, err := json.Marshal(state)
+	return c.SetContext(context.Background(), id, state)
+}
+
+// SetContext is like Set, but the request is canceled if ctx is done before it completes.
+func (c *Client) SetContext(ctx context.Context, id string, state message.NewLightState) (err error) {
+	body, err := json.Marshal(state)
 	if err != nil {
 		return err
 	}
-	log.WithFields(log.Fields{
-		"package":  "github.com/drombosky/disco-dance-party/hue/light",
-		"function": "(c *Client) SetState",
-		"request":  string(message),
-	}).Debugf("Set state for %v to %v", id, string(message))
 
-	if err = c.client.Do("PUT", fmt.Sprintf("/api/<username>/lights/%v/state", id), message, nil); err != nil {
+	start := time.Now()
+	results := []message.Result{}
+	address := fmt.Sprintf("/api/<username>/lights/%v/state", id)
+	err = c.client.DoContext(ctx, "PUT", address, body, &results)
+	c.client.Log("(c *Client) SetContext", id, start, err, "Set state for %v to %v", id, string(body))
+	if err != nil {
+		if apiErr, ok := err.(*message.APIError); ok {
+			return newSetError(id, results, apiErr)
+		}
 		return err
 	}
 	return nil
@@ -101,12 +133,15 @@ func (c *Client) Set(id string, state message.NewLightState) (err error) {
 
 // Delete deletes a light from the Philips Hue bridge.
 func (c *Client) Delete(id string) (err error) {
-	log.WithFields(log.Fields{
-		"package":  "github.com/drombosky/disco-dance-party/hue/light",
-		"function": "(c *Client) Delete",
-	}).Debugf("Delete %v", id)
+	return c.DeleteContext(context.Background(), id)
+}
 
-	if err = c.client.Do("PUT", fmt.Sprintf("/api/<username>/lights/%v/state", id), nil, nil); err != nil {
+// DeleteContext is like Delete, but the request is canceled if ctx is done before it completes.
+func (c *Client) DeleteContext(ctx context.Context, id string) (err error) {
+	start := time.Now()
+	err = c.client.DoContext(ctx, "DELETE", fmt.Sprintf("/api/<username>/lights/%v", id), nil, nil)
+	c.client.Log("(c *Client) DeleteContext", id, start, err, "Delete %v", id)
+	if err != nil {
 		return err
 	}
 	return nil
@@ -0,0 +1,52 @@
+package lights
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue/client"
+	"github.com/drombosky/disco-dance-party/hue/message"
+)
+
+func TestSetContextCanceled(t *testing.T) {
+	received := make(chan struct{}, 1)
+	hang := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+		<-hang
+	}))
+	defer server.Close()
+	defer close(hang)
+	// The bridge is at 10.0.0.2, which the transport connects to the server instead.
+	transport := &http.Transport{DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, strings.TrimPrefix(server.URL, "http://"))
+	}}
+	hueClient, err := client.NewClient(client.WithAddress("10.0.0.2"), client.WithUsername("user"),
+		client.WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewClient(hueClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-received
+		cancel()
+	}()
+	start := time.Now()
+	err = c.SetContext(ctx, "1", message.NewLightState{BasicState: message.BasicState{On: true}})
+	if err != context.Canceled {
+		t.Errorf("SetContext() = %v, want %v", err, context.Canceled)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("SetContext() returned after %v, want it to return once canceled", elapsed)
+	}
+}
@@ -0,0 +1,214 @@
+package lights
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/drombosky/disco-dance-party/hue"
+	"github.com/drombosky/disco-dance-party/hue/logging"
+	"github.com/drombosky/disco-dance-party/hue/message"
+)
+
+// Separator separates the bridge ID from the light ID in the light IDs used by MultiClient.
+const Separator = "/"
+
+// InvalidLightIDError represents an error when a light ID does not name a known bridge.
+type InvalidLightIDError struct {
+	ID string
+}
+
+// Error satisfies the error interface.
+func (e *InvalidLightIDError) Error() string {
+	return fmt.Sprintf("%v is not a valid light ID, expected <bridge ID>%v<light ID> for a known bridge", e.ID, Separator)
+}
+
+// MultiClient represents a client to control the lights of several Philips Hue bridges as if they were connected to a
+// single bridge. Light IDs are namespaced by bridge ID, e.g. 001788fffe1a2b3c/1.
+type MultiClient struct {
+	bridges map[string]hue.Lights
+	logger  logging.Logger
+}
+
+// NewMultiClient takes a map of bridge IDs to lights clients and returns a client for interacting with the lights of
+// all of the bridges.
+func NewMultiClient(bridges map[string]hue.Lights, opts ...Option) (client *MultiClient, err error) {
+	o := newOptions(opts)
+	client = &MultiClient{bridges: map[string]hue.Lights{}, logger: o.logger}
+	for id, lights := range bridges {
+		if id == "" || strings.Contains(id, Separator) {
+			return nil, &InvalidLightIDError{ID: id}
+		}
+		client.bridges[id] = lights
+	}
+	return client, nil
+}
+
+// ID returns the namespaced ID of a light connected to a bridge.
+func ID(bridgeID, lightID string) string {
+	return bridgeID + Separator + lightID
+}
+
+// GetAll gets a list of all lights that have been discovered by every Philips Hue bridge.
+func (c *MultiClient) GetAll() (resp map[string]message.Light, err error) {
+	return c.GetAllContext(context.Background())
+}
+
+// GetAllContext is like GetAll, but the requests are canceled if ctx is done before they complete. The bridges are
+// queried concurrently.
+func (c *MultiClient) GetAllContext(ctx context.Context) (resp map[string]message.Light, err error) {
+	c.logger.Debug(fmt.Sprintf("Get all from %v bridges", len(c.bridges)), logging.Fields{
+		logging.Package:   "github.com/drombosky/disco-dance-party/hue/lights",
+		logging.Operation: "(c *MultiClient) GetAllContext",
+	})
+	all := make([]map[string]message.Light, len(c.bridges))
+	err = c.each(func(i int, lights hue.Lights) (err error) {
+		all[i], err = lights.GetAllContext(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp = map[string]message.Light{}
+	for i, bridgeID := range c.bridgeIDs() {
+		for lightID, light := range all[i] {
+			resp[ID(bridgeID, lightID)] = light
+		}
+	}
+	return resp, nil
+}
+
+// GetNew gets the status of the last search for new lights across every Philips Hue bridge. LastScan is "active" if a
+// scan is on-going on any bridge, otherwise the most recent scan time or "none" if no bridge has been scanned.
+func (c *MultiClient) GetNew() (resp *message.GetNewResp, err error) {
+	return c.GetNewContext(context.Background())
+}
+
+// GetNewContext is like GetNew, but the requests are canceled if ctx is done before they complete. The bridges are
+// queried concurrently.
+func (c *MultiClient) GetNewContext(ctx context.Context) (resp *message.GetNewResp, err error) {
+	all := make([]*message.GetNewResp, len(c.bridges))
+	err = c.each(func(i int, lights hue.Lights) (err error) {
+		all[i], err = lights.GetNewContext(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp = &message.GetNewResp{LastScan: "none"}
+	for _, r := range all {
+		switch {
+		case r == nil || r.LastScan == "none":
+		case r.LastScan == "active":
+			resp.LastScan = "active"
+		case resp.LastScan == "none" || (resp.LastScan != "active" && r.LastScan > resp.LastScan):
+			// ISO 8601 times sort lexically.
+			resp.LastScan = r.LastScan
+		}
+	}
+	return resp, nil
+}
+
+// Get gets the attributes and state of a given light.
+func (c *MultiClient) Get(id string) (resp *message.Light, err error) {
+	return c.GetContext(context.Background(), id)
+}
+
+// GetContext is like Get, but the request is canceled if ctx is done before it completes.
+func (c *MultiClient) GetContext(ctx context.Context, id string) (resp *message.Light, err error) {
+	lights, lightID, err := c.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	return lights.GetContext(ctx, lightID)
+}
+
+// Rename is used to rename lights. A light can have its name changed when in any state, including when it is
+// unreachable or off.
+func (c *MultiClient) Rename(id, name string) (err error) {
+	return c.RenameContext(context.Background(), id, name)
+}
+
+// RenameContext is like Rename, but the request is canceled if ctx is done before it completes.
+func (c *MultiClient) RenameContext(ctx context.Context, id, name string) (err error) {
+	lights, lightID, err := c.lookup(id)
+	if err != nil {
+		return err
+	}
+	return lights.RenameContext(ctx, lightID, name)
+}
+
+// Set allows the user to turn the light on and off, modify the hue and effects.
+func (c *MultiClient) Set(id string, state message.NewLightState) (err error) {
+	return c.SetContext(context.Background(), id, state)
+}
+
+// SetContext is like Set, but the request is canceled if ctx is done before it completes.
+func (c *MultiClient) SetContext(ctx context.Context, id string, state message.NewLightState) (err error) {
+	lights, lightID, err := c.lookup(id)
+	if err != nil {
+		return err
+	}
+	return lights.SetContext(ctx, lightID, state)
+}
+
+// Delete deletes a light from its Philips Hue bridge.
+func (c *MultiClient) Delete(id string) (err error) {
+	return c.DeleteContext(context.Background(), id)
+}
+
+// DeleteContext is like Delete, but the request is canceled if ctx is done before it completes.
+func (c *MultiClient) DeleteContext(ctx context.Context, id string) (err error) {
+	lights, lightID, err := c.lookup(id)
+	if err != nil {
+		return err
+	}
+	return lights.DeleteContext(ctx, lightID)
+}
+
+// lookup splits a namespaced light ID and returns the client of its bridge.
+func (c *MultiClient) lookup(id string) (lights hue.Lights, lightID string, err error) {
+	parts := strings.SplitN(id, Separator, 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, "", &InvalidLightIDError{ID: id}
+	}
+	lights, ok := c.bridges[parts[0]]
+	if !ok {
+		return nil, "", &InvalidLightIDError{ID: id}
+	}
+	return lights, parts[1], nil
+}
+
+// each calls fn concurrently for the client of every bridge, passing the index of its ID in bridgeIDs, and returns the
+// error of the first bridge in that order that failed.
+func (c *MultiClient) each(fn func(i int, lights hue.Lights) error) (err error) {
+	ids := c.bridgeIDs()
+	errs := make([]error, len(ids))
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, lights hue.Lights) {
+			defer wg.Done()
+			errs[i] = fn(i, lights)
+		}(i, c.bridges[id])
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bridgeIDs returns the bridge IDs in a stable order.
+func (c *MultiClient) bridgeIDs() []string {
+	ids := make([]string, 0, len(c.bridges))
+	for id := range c.bridges {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
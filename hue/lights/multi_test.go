@@ -0,0 +1,97 @@
+package lights_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/drombosky/disco-dance-party/hue"
+	"github.com/drombosky/disco-dance-party/hue/lights"
+	"github.com/drombosky/disco-dance-party/hue/message"
+	"github.com/drombosky/disco-dance-party/hue/mockHue"
+)
+
+func TestMultiClientGetAllQueriesBridgesConcurrently(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Each bridge only answers once every bridge has been asked, which never happens if they are asked in turn.
+	var asked sync.WaitGroup
+	asked.Add(2)
+	wait := func(ctx context.Context) {
+		asked.Done()
+		asked.Wait()
+	}
+	a := mockHue.NewMockLights(ctrl)
+	a.EXPECT().GetAllContext(gomock.Any()).Do(wait).Return(map[string]message.Light{"1": {Name: "a1"}}, nil)
+	b := mockHue.NewMockLights(ctrl)
+	b.EXPECT().GetAllContext(gomock.Any()).Do(wait).Return(map[string]message.Light{"1": {Name: "b1"}}, nil)
+
+	client, err := lights.NewMultiClient(map[string]hue.Lights{"a": a, "b": b})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan map[string]message.Light)
+	go func() {
+		resp, err := client.GetAll()
+		if err != nil {
+			t.Error(err)
+		}
+		done <- resp
+	}()
+	select {
+	case resp := <-done:
+		want := map[string]message.Light{"a/1": {Name: "a1"}, "b/1": {Name: "b1"}}
+		if !reflect.DeepEqual(resp, want) {
+			t.Errorf("GetAll() = %v, want %v", resp, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("GetAll() did not query the bridges concurrently")
+	}
+}
+
+func TestMultiClientGetNew(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	failed := errors.New("unreachable")
+	scans := []struct {
+		a, b *message.GetNewResp
+		err  error
+		want string
+	}{
+		{&message.GetNewResp{LastScan: "none"}, &message.GetNewResp{LastScan: "none"}, nil, "none"},
+		{&message.GetNewResp{LastScan: "2017-01-02T10:00:00"}, &message.GetNewResp{LastScan: "none"}, nil,
+			"2017-01-02T10:00:00"},
+		{&message.GetNewResp{LastScan: "2017-01-02T10:00:00"}, &message.GetNewResp{LastScan: "2017-03-04T10:00:00"},
+			nil, "2017-03-04T10:00:00"},
+		{&message.GetNewResp{LastScan: "active"}, &message.GetNewResp{LastScan: "2017-03-04T10:00:00"}, nil,
+			"active"},
+		{&message.GetNewResp{LastScan: "active"}, nil, failed, ""},
+	}
+	for _, s := range scans {
+		a := mockHue.NewMockLights(ctrl)
+		a.EXPECT().GetNewContext(gomock.Any()).Return(s.a, nil)
+		b := mockHue.NewMockLights(ctrl)
+		b.EXPECT().GetNewContext(gomock.Any()).Return(s.b, s.err)
+		client, err := lights.NewMultiClient(map[string]hue.Lights{"a": a, "b": b})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := client.GetNew()
+		if err != s.err {
+			t.Errorf("GetNew() error = %v, want %v", err, s.err)
+			continue
+		}
+		if err == nil && resp.LastScan != s.want {
+			t.Errorf("GetNew() = %v, want %v", resp.LastScan, s.want)
+		}
+	}
+}
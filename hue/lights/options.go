@@ -0,0 +1,30 @@
+package lights
+
+import (
+	"github.com/drombosky/disco-dance-party/hue/logging"
+)
+
+// Option represents a setting of a client created by NewClient, NewMultiClient or NewCoalescingClient.
+type Option func(o *options)
+
+// options represents the settings of a client.
+type options struct {
+	logger logging.Logger
+}
+
+// WithLogger sets the logger the client logs to. Defaults to logging nothing.
+func WithLogger(logger logging.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// newOptions applies opts to the default settings.
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	o.logger = logging.OrNop(o.logger)
+	return o
+}
@@ -0,0 +1,163 @@
+// Package logging defines the logger the hue packages log to, so applications can route bridge logs into their own
+// logging pipeline. Each package takes a Logger in its constructor or config and logs nothing if it is not given one.
+// Adapters are provided for logrus and the standard library log package. Entries use the same field names in every
+// package, e.g. Operation for the function logging and Latency for how long a request took.
+package logging
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Names of the fields used by every package.
+const (
+	// Package is the import path of the package that logged the entry.
+	Package = "package"
+	// Operation is the function that logged the entry, e.g. (c *Client) SetContext.
+	Operation = "operation"
+	// Light is the ID of the light the entry is about.
+	Light = "light"
+	// Group is the ID of the group the entry is about.
+	Group = "group"
+	// Scene is the ID of the scene the entry is about.
+	Scene = "scene"
+	// Schedule is the ID of the schedule the entry is about.
+	Schedule = "schedule"
+	// Sensor is the ID of the sensor the entry is about.
+	Sensor = "sensor"
+	// Rule is the ID of the rule the entry is about.
+	Rule = "rule"
+	// Event is the ID of the CLIP v2 event the entry is about.
+	Event = "event"
+	// Area is the ID of the entertainment area the entry is about.
+	Area = "area"
+	// Attempt is the number of times a request has been sent, counting from 1.
+	Attempt = "attempt"
+	// Latency is how long the operation took, e.g. a request to the bridge or the wait for a rate limit, as a
+	// time.Duration.
+	Latency = "latency"
+	// Bridge is the ID of the bridge the entry is about.
+	Bridge = "bridge"
+	// Method is the HTTP method of a request.
+	Method = "method"
+	// Address is the address of a request, e.g. /api/<username>/lights/1.
+	Address = "address"
+	// Host is the host or URL a request was sent to.
+	Host = "host"
+	// Request is the body of a request.
+	Request = "request"
+	// Response is the body of a response.
+	Response = "response"
+	// Error is the error an operation failed with.
+	Error = "error"
+)
+
+// Fields represents the structured data of a log entry, keyed by field name.
+type Fields map[string]interface{}
+
+// Logger represents a destination for log entries.
+type Logger interface {
+	// Debug logs detail that is only useful when diagnosing a problem, such as every request sent.
+	Debug(msg string, fields Fields)
+	// Info logs a noteworthy event, such as the bridge moving to a new address.
+	Info(msg string, fields Fields)
+	// Error logs a failure that could not be returned to the caller, such as a failed background update.
+	Error(msg string, fields Fields)
+}
+
+// Nop returns a logger that discards every entry.
+func Nop() Logger {
+	return nop{}
+}
+
+// OrNop returns logger, or a logger that discards every entry if logger is nil.
+func OrNop(logger Logger) Logger {
+	if logger == nil {
+		return nop{}
+	}
+	return logger
+}
+
+// nop is a logger that discards every entry.
+type nop struct{}
+
+func (nop) Debug(msg string, fields Fields) {}
+func (nop) Info(msg string, fields Fields)  {}
+func (nop) Error(msg string, fields Fields) {}
+
+// Logrus returns a logger that logs to a logrus logger, or the standard logrus logger if logger is nil.
+func Logrus(logger *logrus.Logger) Logger {
+	if logger == nil {
+		logger = logrus.StandardLogger()
+	}
+	return logrusLogger{logger: logger}
+}
+
+// logrusLogger adapts a logrus logger.
+type logrusLogger struct {
+	logger *logrus.Logger
+}
+
+func (l logrusLogger) Debug(msg string, fields Fields) {
+	l.logger.WithFields(logrus.Fields(fields)).Debug(msg)
+}
+
+func (l logrusLogger) Info(msg string, fields Fields) {
+	l.logger.WithFields(logrus.Fields(fields)).Info(msg)
+}
+
+func (l logrusLogger) Error(msg string, fields Fields) {
+	l.logger.WithFields(logrus.Fields(fields)).Error(msg)
+}
+
+// Std returns a logger that logs to a standard library logger, or the standard logger of the log package if logger is
+// nil. Entries are written as the level, the message and the fields in order of name, e.g.
+//
+//	DEBUG Set state light=1 operation=(c *Client) SetContext
+//
+// Debug entries are only written if debug is true.
+func Std(logger *log.Logger, debug bool) Logger {
+	return stdLogger{logger: logger, debug: debug}
+}
+
+// stdLogger adapts a standard library logger.
+type stdLogger struct {
+	logger *log.Logger
+	debug  bool
+}
+
+func (l stdLogger) Debug(msg string, fields Fields) {
+	if l.debug {
+		l.print("DEBUG", msg, fields)
+	}
+}
+
+func (l stdLogger) Info(msg string, fields Fields) {
+	l.print("INFO", msg, fields)
+}
+
+func (l stdLogger) Error(msg string, fields Fields) {
+	l.print("ERROR", msg, fields)
+}
+
+// print writes an entry.
+func (l stdLogger) print(level, msg string, fields Fields) {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	entry := []string{level, msg}
+	for _, name := range names {
+		entry = append(entry, fmt.Sprintf("%v=%v", name, fields[name]))
+	}
+	if l.logger == nil {
+		log.Print(strings.Join(entry, " "))
+		return
+	}
+	l.logger.Print(strings.Join(entry, " "))
+}
@@ -0,0 +1,88 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestOrNop(t *testing.T) {
+	if _, ok := OrNop(nil).(nop); !ok {
+		t.Errorf("OrNop(nil) = %#v, want a logger that discards every entry", OrNop(nil))
+	}
+	logger := Std(nil, false)
+	if OrNop(logger) != logger {
+		t.Errorf("OrNop(%#v) = %#v, want the same logger", logger, OrNop(logger))
+	}
+}
+
+func TestStd(t *testing.T) {
+	fields := Fields{Operation: "(c *Client) SetContext", Light: "1"}
+	for _, tc := range []struct {
+		debug bool
+		want  []string
+	}{
+		{false, []string{
+			"INFO Set state light=1 operation=(c *Client) SetContext",
+			"ERROR Set state light=1 operation=(c *Client) SetContext",
+		}},
+		{true, []string{
+			"DEBUG Set state light=1 operation=(c *Client) SetContext",
+			"INFO Set state light=1 operation=(c *Client) SetContext",
+			"ERROR Set state light=1 operation=(c *Client) SetContext",
+		}},
+	} {
+		buf := &bytes.Buffer{}
+		logger := Std(log.New(buf, "", 0), tc.debug)
+		logger.Debug("Set state", fields)
+		logger.Info("Set state", fields)
+		logger.Error("Set state", fields)
+		if want := strings.Join(tc.want, "\n") + "\n"; buf.String() != want {
+			t.Errorf("Std(debug %v) wrote %q, want %q", tc.debug, buf.String(), want)
+		}
+	}
+}
+
+func TestLogrus(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := logrus.New()
+	l.Out = buf
+	l.Formatter = &logrus.JSONFormatter{}
+	l.Level = logrus.DebugLevel
+	logger := Logrus(l)
+	logger.Debug("Set state", Fields{Light: "1"})
+	logger.Info("Bridge moved", Fields{Bridge: "001788fffeaabbcc"})
+	logger.Error("Update failed", Fields{Attempt: 3})
+
+	want := []map[string]interface{}{
+		{"level": "debug", "msg": "Set state", Light: "1"},
+		{"level": "info", "msg": "Bridge moved", Bridge: "001788fffeaabbcc"},
+		{"level": "error", "msg": "Update failed", Attempt: float64(3)},
+	}
+	decoder := json.NewDecoder(buf)
+	for _, w := range want {
+		entry := map[string]interface{}{}
+		if err := decoder.Decode(&entry); err != nil {
+			t.Fatal(err)
+		}
+		delete(entry, "time")
+		if len(entry) != len(w) {
+			t.Errorf("entry = %v, want %v", entry, w)
+			continue
+		}
+		for name, value := range w {
+			if entry[name] != value {
+				t.Errorf("entry = %v, want %v", entry, w)
+				break
+			}
+		}
+	}
+
+	if Logrus(nil).(logrusLogger).logger != logrus.StandardLogger() {
+		t.Error("Logrus(nil) does not log to the standard logrus logger")
+	}
+}
@@ -0,0 +1,101 @@
+package message
+
+// GroupType represents the kind of a group of lights.
+type GroupType string
+
+// Types of groups. Only LightGroup, Room, Zone and Entertainment groups can be created; Luminaire and LightSource
+// groups are created by the bridge for multisource luminaires.
+const (
+	// GroupTypeLightGroup is a group of any lights. It is the default when creating a group.
+	GroupTypeLightGroup GroupType = "LightGroup"
+	// GroupTypeRoom is a room. A light can only be in one room.
+	GroupTypeRoom GroupType = "Room"
+	// GroupTypeZone is a zone, a group of lights that may span rooms. A light can be in several zones.
+	GroupTypeZone GroupType = "Zone"
+	// GroupTypeEntertainment is an entertainment area, a group of lights that can be streamed to.
+	GroupTypeEntertainment GroupType = "Entertainment"
+	// GroupTypeLuminaire is a multisource luminaire.
+	GroupTypeLuminaire GroupType = "Luminaire"
+	// GroupTypeLightSource is a light source of a multisource luminaire.
+	GroupTypeLightSource GroupType = "LightSource"
+)
+
+// Classes of rooms and zones. The class picks the icon the Hue apps show for the group.
+const (
+	GroupClassLivingRoom  = "Living room"
+	GroupClassKitchen     = "Kitchen"
+	GroupClassDining      = "Dining"
+	GroupClassBedroom     = "Bedroom"
+	GroupClassKidsBedroom = "Kids bedroom"
+	GroupClassBathroom    = "Bathroom"
+	GroupClassNursery     = "Nursery"
+	GroupClassRecreation  = "Recreation"
+	GroupClassOffice      = "Office"
+	GroupClassGym         = "Gym"
+	GroupClassHallway     = "Hallway"
+	GroupClassToilet      = "Toilet"
+	GroupClassFrontDoor   = "Front door"
+	GroupClassGarage      = "Garage"
+	GroupClassTerrace     = "Terrace"
+	GroupClassGarden      = "Garden"
+	GroupClassDriveway    = "Driveway"
+	GroupClassCarport     = "Carport"
+	GroupClassOther       = "Other"
+)
+
+// Classes of entertainment groups.
+const (
+	GroupClassTV   = "TV"
+	GroupClassFree = "Free"
+)
+
+// GroupState represents whether the lights of a group are on.
+type GroupState struct {
+	// True if every light in the group is on.
+	AllOn bool `json:"all_on"`
+	// True if any light in the group is on.
+	AnyOn bool `json:"any_on"`
+}
+
+// Group represents the attributes and state of a group of lights.
+type Group struct {
+	// A unique, editable name given to the group.
+	Name string `json:"name"`
+	// The IDs of the lights in the group.
+	Lights []string `json:"lights"`
+	// The IDs of the sensors in the group.
+	Sensors []string `json:"sensors,omitempty"`
+	// The kind of group.
+	Type GroupType `json:"type"`
+	// Whether the lights of the group are on.
+	State GroupState `json:"state"`
+	// True if the group is deleted by the bridge once it is no longer referenced, e.g. by a scene.
+	Recycle bool `json:"recycle,omitempty"`
+	// The class of a room, zone or entertainment group, e.g. GroupClassLivingRoom.
+	Class string `json:"class,omitempty"`
+	// The last state sent to the group. It is not the state of the lights, which may have been changed individually.
+	Action LightState `json:"action"`
+}
+
+// NewGroup represents a group to be created on the Hue hub.
+type NewGroup struct {
+	// A name for the group. Defaults to "Group" followed by a number.
+	Name string `json:"name,omitempty"`
+	// The IDs of the lights in the group. Rooms may be created without lights; other groups need at least one.
+	Lights []string `json:"lights"`
+	// The kind of group. Defaults to GroupTypeLightGroup.
+	Type GroupType `json:"type,omitempty"`
+	// The class of a room, zone or entertainment group. Defaults to GroupClassOther.
+	Class string `json:"class,omitempty"`
+}
+
+// GroupAttributes represents the attributes of a group to be changed. Empty attributes are left unchanged.
+type GroupAttributes struct {
+	// The new name of the group.
+	Name string `json:"name,omitempty"`
+	// The IDs of the lights that replace the lights in the group, or nil to leave them unchanged. A pointer to an empty
+	// list removes every light, which the bridge only allows for rooms.
+	Lights *[]string `json:"lights,omitempty"`
+	// The new class of a room, zone or entertainment group.
+	Class string `json:"class,omitempty"`
+}
@@ -1,6 +1,8 @@
 // Package message contains the message definitions that can be sent to and from the Philips Hue bridge.
 package message
 
+import "encoding/json"
+
 // BasicState represents the basic light state provided during sets and returned during gets.
 type BasicState struct {
 	// On/Off state of the light. On=true, Off=false
@@ -74,6 +76,27 @@ type NewLightState struct {
 	XyInc [2]float64 `json:"xy_inc,omitempty"`
 }
 
+// MarshalJSON satisfies the json.Marshaler interface. Zero xy and xy_inc values are left out, since encoding/json never
+// omits arrays and the bridge would otherwise be sent a color of [0, 0] and a stop of the color transition with every
+// state.
+func (s NewLightState) MarshalJSON() ([]byte, error) {
+	// state has the fields but not the methods of NewLightState, so it is encoded field by field. The pointers take
+	// precedence over the arrays since they are less deeply nested.
+	type state NewLightState
+	encoded := struct {
+		state
+		Xy    *[2]float64 `json:"xy,omitempty"`
+		XyInc *[2]float64 `json:"xy_inc,omitempty"`
+	}{state: state(s)}
+	if s.Xy != [2]float64{} {
+		encoded.Xy = &s.Xy
+	}
+	if s.XyInc != [2]float64{} {
+		encoded.XyInc = &s.XyInc
+	}
+	return json.Marshal(encoded)
+}
+
 // Light represents the complete state of a light including the light's state type, name, model ID, and software
 // version.
 type Light struct {
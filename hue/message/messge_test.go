@@ -0,0 +1,53 @@
+package message_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/drombosky/disco-dance-party/hue/message"
+)
+
+func TestNewLightStateMarshalJSON(t *testing.T) {
+	states := []struct {
+		state message.NewLightState
+		want  string
+	}{
+		{message.NewLightState{BasicState: message.BasicState{On: true, Bri: 100}}, `{"on":true,"bri":100}`},
+		{message.NewLightState{BasicState: message.BasicState{Xy: [2]float64{0.3, 0.4}}},
+			`{"on":false,"xy":[0.3,0.4]}`},
+		{message.NewLightState{BasicState: message.BasicState{On: true}, XyInc: [2]float64{0.1, 0}},
+			`{"on":true,"xy_inc":[0.1,0]}`},
+		{message.NewLightState{TransitionTime: 10, BriInc: -20}, `{"on":false,"transitiontime":10,"bri_inc":-20}`},
+	}
+	for _, s := range states {
+		body, err := json.Marshal(s.state)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(body) != s.want {
+			t.Errorf("json.Marshal(%+v) = %s, want %s", s.state, body, s.want)
+		}
+
+		decoded := message.NewLightState{}
+		if err = json.Unmarshal(body, &decoded); err != nil || decoded != s.state {
+			t.Errorf("json.Unmarshal(%s) = %+v, %v, want %+v", body, decoded, err, s.state)
+		}
+	}
+}
+
+func TestNewLightStateOmitsZeroXy(t *testing.T) {
+	// A zero xy would set the color to [0, 0] and a zero xy_inc would stop the color transition of the light.
+	body, err := json.Marshal(message.NewLightState{BasicState: message.BasicState{On: true, Bri: 50}, BriInc: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := map[string]interface{}{}
+	if err = json.Unmarshal(body, &fields); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"xy", "xy_inc"} {
+		if _, ok := fields[name]; ok {
+			t.Errorf("json.Marshal() = %s, want no %v", body, name)
+		}
+	}
+}
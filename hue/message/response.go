@@ -0,0 +1,164 @@
+package message
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ErrNoID is returned when the Philips Hue bridge accepted a new resource but did not return its ID.
+var ErrNoID = errors.New("Bridge did not return the ID of the new resource")
+
+// ErrorType represents the type of an error returned by the Philips Hue bridge.
+type ErrorType int
+
+// Error types returned by the Philips Hue bridge.
+const (
+	// ErrorTypeUnauthorizedUser is returned when the request uses a username that is not whitelisted.
+	ErrorTypeUnauthorizedUser ErrorType = 1
+	// ErrorTypeInvalidJSON is returned when the body of the request is not valid JSON.
+	ErrorTypeInvalidJSON ErrorType = 2
+	// ErrorTypeResourceNotAvailable is returned when the addressed resource does not exist.
+	ErrorTypeResourceNotAvailable ErrorType = 3
+	// ErrorTypeMethodNotAvailable is returned when the resource does not support the request method.
+	ErrorTypeMethodNotAvailable ErrorType = 4
+	// ErrorTypeMissingParameters is returned when a required parameter is missing from the body.
+	ErrorTypeMissingParameters ErrorType = 5
+	// ErrorTypeParameterNotAvailable is returned when a parameter in the body does not exist for the resource.
+	ErrorTypeParameterNotAvailable ErrorType = 6
+	// ErrorTypeInvalidValue is returned when a parameter has an invalid value.
+	ErrorTypeInvalidValue ErrorType = 7
+	// ErrorTypeParameterNotModifiable is returned when a parameter is read only.
+	ErrorTypeParameterNotModifiable ErrorType = 8
+	// ErrorTypeTooManyItems is returned when a list in the body has too many items.
+	ErrorTypeTooManyItems ErrorType = 11
+	// ErrorTypePortalConnectionRequired is returned when the command requires the bridge to be connected to the portal.
+	ErrorTypePortalConnectionRequired ErrorType = 12
+	// ErrorTypeLinkButtonNotPressed is returned when a user is created without the link button being pressed.
+	ErrorTypeLinkButtonNotPressed ErrorType = 101
+	// ErrorTypeDHCPCannotBeDisabled is returned when disabling DHCP is not allowed.
+	ErrorTypeDHCPCannotBeDisabled ErrorType = 110
+	// ErrorTypeInvalidUpdateState is returned when the requested software update state is not allowed.
+	ErrorTypeInvalidUpdateState ErrorType = 111
+	// ErrorTypeDeviceOff is returned when a parameter cannot be modified because the device is off.
+	ErrorTypeDeviceOff ErrorType = 201
+	// ErrorTypeGroupTableFull is returned when no more groups can be created.
+	ErrorTypeGroupTableFull ErrorType = 301
+	// ErrorTypeDeviceGroupTableFull is returned when a light cannot be added to any more groups.
+	ErrorTypeDeviceGroupTableFull ErrorType = 302
+	// ErrorTypeDeviceUnreachable is returned when a light in the request cannot be reached.
+	ErrorTypeDeviceUnreachable ErrorType = 304
+	// ErrorTypeGroupNotModifiable is returned when a group of this type cannot be updated or deleted.
+	ErrorTypeGroupNotModifiable ErrorType = 305
+	// ErrorTypeLightAlreadyUsed is returned when a light is already part of another group of the same type.
+	ErrorTypeLightAlreadyUsed ErrorType = 306
+	// ErrorTypeSceneNotCreated is returned when a scene could not be created.
+	ErrorTypeSceneNotCreated ErrorType = 402
+	// ErrorTypeSceneBufferFull is returned when the scene buffer of the bridge is full.
+	ErrorTypeSceneBufferFull ErrorType = 403
+	// ErrorTypeSensorTypeNotAllowed is returned when a sensor of this type cannot be created.
+	ErrorTypeSensorTypeNotAllowed ErrorType = 501
+	// ErrorTypeSensorListFull is returned when no more sensors can be created.
+	ErrorTypeSensorListFull ErrorType = 502
+	// ErrorTypeRuleEngineFull is returned when no more rules can be created.
+	ErrorTypeRuleEngineFull ErrorType = 601
+	// ErrorTypeConditionError is returned when a rule condition is invalid.
+	ErrorTypeConditionError ErrorType = 607
+	// ErrorTypeActionError is returned when a rule action is invalid.
+	ErrorTypeActionError ErrorType = 608
+	// ErrorTypeUnableToActivate is returned when a rule could not be activated.
+	ErrorTypeUnableToActivate ErrorType = 609
+	// ErrorTypeScheduleListFull is returned when no more schedules can be created.
+	ErrorTypeScheduleListFull ErrorType = 701
+	// ErrorTypeInvalidTimeZone is returned when the time zone of a schedule is invalid.
+	ErrorTypeInvalidTimeZone ErrorType = 702
+	// ErrorTypeScheduleTimeConflict is returned when both time and localtime are set on a schedule.
+	ErrorTypeScheduleTimeConflict ErrorType = 703
+	// ErrorTypeCannotCreateSchedule is returned when a schedule could not be created.
+	ErrorTypeCannotCreateSchedule ErrorType = 704
+	// ErrorTypeScheduleTimeInPast is returned when a schedule is enabled with a time in the past.
+	ErrorTypeScheduleTimeInPast ErrorType = 705
+	// ErrorTypeInternalError is returned when the bridge failed internally.
+	ErrorTypeInternalError ErrorType = 901
+)
+
+// APIError represents an error returned by the Philips Hue bridge in the body of a response.
+type APIError struct {
+	// The type of the error.
+	Type ErrorType `json:"type"`
+	// The address of the resource or parameter the error refers to, e.g. /lights/1/state/bri.
+	Address string `json:"address"`
+	// A human readable description of the error.
+	Description string `json:"description"`
+}
+
+// Error satisfies the error interface.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Error %v at %v: %v", e.Type, e.Address, e.Description)
+}
+
+// Result represents one entry of the array the Philips Hue bridge returns for requests that modify resources. Exactly
+// one of Success and Error is set.
+type Result struct {
+	// The values that were changed keyed by address, e.g. {"/lights/1/state/on": true}, or the attributes of a created
+	// resource, e.g. {"id": "1"}.
+	Success map[string]json.RawMessage `json:"success,omitempty"`
+	// The error that occurred.
+	Error *APIError `json:"error,omitempty"`
+}
+
+// CreatedID returns the ID of the resource the bridge created, as reported by the results of the request that created
+// it, or ErrNoID if it is not reported.
+func CreatedID(results []Result) (id string, err error) {
+	for _, result := range results {
+		if raw, ok := result.Success["id"]; ok {
+			if err = json.Unmarshal(raw, &id); err != nil {
+				return "", err
+			}
+			return id, nil
+		}
+	}
+	return "", ErrNoID
+}
+
+// PartialError represents a change of the fields of a resource, such as the state of a light, that the Philips Hue
+// bridge rejected in whole or in part. Fields are named by the last element of their address, e.g. "on".
+type PartialError struct {
+	// Applied lists the fields the bridge applied.
+	Applied []string
+	// Rejected maps the fields the bridge rejected to the error it returned for them.
+	Rejected map[string]*APIError
+}
+
+// NewPartialError builds a PartialError from the results of a change. If the results could not be decoded err, the
+// error returned by the client, is reported against the field it refers to.
+func NewPartialError(results []Result, err *APIError) PartialError {
+	e := PartialError{Applied: []string{}, Rejected: map[string]*APIError{}}
+	if len(results) == 0 {
+		e.Rejected[path.Base(err.Address)] = err
+		return e
+	}
+	for _, result := range results {
+		if result.Error != nil {
+			e.Rejected[path.Base(result.Error.Address)] = result.Error
+		}
+		for address := range result.Success {
+			e.Applied = append(e.Applied, path.Base(address))
+		}
+	}
+	sort.Strings(e.Applied)
+	return e
+}
+
+// Error satisfies the error interface.
+func (e PartialError) Error() string {
+	rejected := make([]string, 0, len(e.Rejected))
+	for field, err := range e.Rejected {
+		rejected = append(rejected, fmt.Sprintf("%v (%v)", field, err.Description))
+	}
+	sort.Strings(rejected)
+	return fmt.Sprintf("Rejected %v, applied %v", strings.Join(rejected, ", "), strings.Join(e.Applied, ", "))
+}
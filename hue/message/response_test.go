@@ -0,0 +1,55 @@
+package message
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCreatedID(t *testing.T) {
+	for _, tc := range []struct {
+		body string
+		id   string
+		err  error
+	}{
+		{`[{"success":{"id":"3"}}]`, "3", nil},
+		{`[{"success":{"/groups/3/lights":["1"]}},{"success":{"id":"3"}}]`, "3", nil},
+		{`[{"success":{"/groups/3/lights":["1"]}}]`, "", ErrNoID},
+		{`[]`, "", ErrNoID},
+	} {
+		results := []Result{}
+		if err := json.Unmarshal([]byte(tc.body), &results); err != nil {
+			t.Fatal(err)
+		}
+		if id, err := CreatedID(results); id != tc.id || err != tc.err {
+			t.Errorf("CreatedID(%v) = %q, %v, want %q, %v", tc.body, id, err, tc.id, tc.err)
+		}
+	}
+}
+
+func TestNewPartialError(t *testing.T) {
+	results := []Result{}
+	body := `[{"success":{"/lights/1/state/on":true}},` +
+		`{"error":{"type":7,"address":"/lights/1/state/bri","description":"invalid value, 300, for parameter, bri"}},` +
+		`{"success":{"/lights/1/state/alert":"select"}}]`
+	if err := json.Unmarshal([]byte(body), &results); err != nil {
+		t.Fatal(err)
+	}
+	e := NewPartialError(results, nil)
+	if want := []string{"alert", "on"}; !reflect.DeepEqual(e.Applied, want) {
+		t.Errorf("Applied = %v, want %v", e.Applied, want)
+	}
+	if len(e.Rejected) != 1 || e.Rejected["bri"] == nil || e.Rejected["bri"].Type != ErrorTypeInvalidValue {
+		t.Errorf("Rejected = %v, want bri", e.Rejected)
+	}
+	if want := "Rejected bri (invalid value, 300, for parameter, bri), applied alert, on"; e.Error() != want {
+		t.Errorf("Error() = %q, want %q", e.Error(), want)
+	}
+
+	// Without results the error returned by the client is reported.
+	apiErr := &APIError{Type: ErrorTypeDeviceOff, Address: "/lights/1/state/bri", Description: "device is off"}
+	e = NewPartialError(nil, apiErr)
+	if len(e.Applied) != 0 || e.Rejected["bri"] != apiErr {
+		t.Errorf("NewPartialError(nil) = %+v, want bri rejected", e)
+	}
+}
@@ -0,0 +1,122 @@
+package message
+
+// Operators of rule conditions.
+const (
+	// OperatorEq is true while the attribute equals the value.
+	OperatorEq = "eq"
+	// OperatorGt is true while the attribute is greater than the value.
+	OperatorGt = "gt"
+	// OperatorLt is true while the attribute is less than the value.
+	OperatorLt = "lt"
+	// OperatorDx is true when the attribute changes. It has no value.
+	OperatorDx = "dx"
+	// OperatorDdx is true when the attribute changed the duration given by the value ago, e.g. PT00:00:30.
+	OperatorDdx = "ddx"
+	// OperatorStable is true while the attribute has not changed for the duration given by the value.
+	OperatorStable = "stable"
+	// OperatorNotStable is true while the attribute has changed within the duration given by the value.
+	OperatorNotStable = "not stable"
+	// OperatorIn is true while /config/localtime is in the interval given by the value, e.g. T20:00:00/T08:00:00.
+	OperatorIn = "in"
+	// OperatorNotIn is true while /config/localtime is not in the interval given by the value.
+	OperatorNotIn = "not in"
+)
+
+// Condition represents a condition of a rule.
+type Condition struct {
+	// The address of the attribute, relative to the user, e.g. /sensors/2/state/buttonevent.
+	Address string `json:"address"`
+	// How the attribute is compared, e.g. OperatorEq.
+	Operator string `json:"operator"`
+	// The value the attribute is compared to, as a string, e.g. "1002".
+	Value string `json:"value,omitempty"`
+}
+
+// LightAction represents a change of the state of a light or group sent by the bridge itself, e.g. as the action of a
+// rule. Unlike NewLightState, fields left nil or empty are not sent, so an action that only dims a light does not also
+// turn it off or stop its color transition.
+type LightAction struct {
+	// Whether the light is on.
+	On *bool `json:"on,omitempty"`
+	// Brightness of the light, from 1 to 254.
+	Bri *int `json:"bri,omitempty"`
+	// Hue of the light, from 0 to 65535.
+	Hue *int `json:"hue,omitempty"`
+	// Saturation of the light, from 0 to 254.
+	Sat *int `json:"sat,omitempty"`
+	// The x and y coordinates of a color in CIE color space, both between 0 and 1.
+	Xy *[2]float64 `json:"xy,omitempty"`
+	// The Mired color temperature of the light, from 153 (6500K) to 500 (2000K).
+	Ct *int `json:"ct,omitempty"`
+	// The alert effect, "none", "select" or "lselect".
+	Alert string `json:"alert,omitempty"`
+	// The dynamic effect of the light, either "none" or "colorloop".
+	Effect string `json:"effect,omitempty"`
+	// The duration of the transition to the state, as a multiple of 100ms.
+	TransitionTime *int `json:"transitiontime,omitempty"`
+	// Increments of the brightness, saturation, hue, color temperature and color. Zero stops an ongoing transition.
+	BriInc *int        `json:"bri_inc,omitempty"`
+	SatInc *int        `json:"sat_inc,omitempty"`
+	HueInc *int        `json:"hue_inc,omitempty"`
+	CtInc  *int        `json:"ct_inc,omitempty"`
+	XyInc  *[2]float64 `json:"xy_inc,omitempty"`
+}
+
+// Rule represents a rule, commands the bridge sends when all of its conditions become true.
+type Rule struct {
+	// A unique, editable name given to the rule.
+	Name string `json:"name"`
+	// The username of the application that created the rule.
+	Owner string `json:"owner"`
+	// When the rule was created.
+	Created Time `json:"created"`
+	// When the rule last ran its actions.
+	LastTriggered Time `json:"lasttriggered"`
+	// How often the rule ran its actions since the bridge started.
+	TimesTriggered int `json:"timestriggered"`
+	// StatusEnabled, StatusDisabled, or "resourcedeleted" if a resource in a condition was deleted.
+	Status string `json:"status"`
+	// True if the rule is deleted by the bridge once it is no longer referenced.
+	Recycle bool `json:"recycle,omitempty"`
+	// The conditions of the rule, all of which must be true.
+	Conditions []Condition `json:"conditions"`
+	// The commands sent when the conditions become true. Their addresses are relative to the user.
+	Actions []Command `json:"actions"`
+}
+
+// NewRule represents a rule to be created on the Hue hub.
+type NewRule struct {
+	// A name for the rule, at most 32 characters.
+	Name string `json:"name,omitempty"`
+	// StatusEnabled or StatusDisabled. Defaults to StatusEnabled.
+	Status string `json:"status,omitempty"`
+	// True if the rule may be deleted by the bridge once it is no longer referenced.
+	Recycle bool `json:"recycle,omitempty"`
+	// The conditions of the rule.
+	Conditions []Condition `json:"conditions"`
+	// The commands sent when the conditions become true.
+	Actions []Command `json:"actions"`
+}
+
+// RuleAttributes represents the attributes of a rule to be changed. Empty attributes are left unchanged.
+type RuleAttributes struct {
+	// The new name of the rule.
+	Name string `json:"name,omitempty"`
+	// StatusEnabled or StatusDisabled.
+	Status string `json:"status,omitempty"`
+	// The conditions that replace the conditions of the rule.
+	Conditions []Condition `json:"conditions,omitempty"`
+	// The commands that replace the actions of the rule.
+	Actions []Command `json:"actions,omitempty"`
+}
+
+// Datastore represents every resource of a Philips Hue bridge, as returned by /api/<username>.
+type Datastore struct {
+	Lights    map[string]Light       `json:"lights"`
+	Groups    map[string]Group       `json:"groups"`
+	Scenes    map[string]Scene       `json:"scenes"`
+	Schedules map[string]Schedule    `json:"schedules"`
+	Sensors   map[string]Sensor      `json:"sensors"`
+	Rules     map[string]Rule        `json:"rules"`
+	Config    map[string]interface{} `json:"config"`
+}
@@ -0,0 +1,98 @@
+package message
+
+// SceneType represents whether a scene is tied to a group.
+type SceneType string
+
+// Types of scenes.
+const (
+	// SceneTypeLightScene is a scene of a list of lights. It is the default when creating a scene.
+	SceneTypeLightScene SceneType = "LightScene"
+	// SceneTypeGroupScene is a scene of a group. Its lights are the lights of the group and it is deleted with it.
+	SceneTypeGroupScene SceneType = "GroupScene"
+)
+
+// SceneAppData represents data stored in a scene by the application that created it.
+type SceneAppData struct {
+	// The version of the data.
+	Version int `json:"version,omitempty"`
+	// The data, at most 16 characters.
+	Data string `json:"data,omitempty"`
+}
+
+// Scene represents the attributes of a scene, a set of stored light states that can be recalled at once.
+type Scene struct {
+	// A unique, editable name given to the scene.
+	Name string `json:"name"`
+	// The kind of scene.
+	Type SceneType `json:"type"`
+	// The ID of the group of a group scene.
+	Group string `json:"group,omitempty"`
+	// The IDs of the lights in the scene.
+	Lights []string `json:"lights"`
+	// The username of the application that created the scene.
+	Owner string `json:"owner"`
+	// True if the scene is deleted by the bridge once it is no longer referenced, e.g. by a schedule.
+	Recycle bool `json:"recycle"`
+	// True if the scene is used by a rule or schedule and cannot be deleted.
+	Locked bool `json:"locked"`
+	// Data stored by the application that created the scene.
+	AppData SceneAppData `json:"appdata"`
+	// Reserved for the picture of the scene.
+	Picture string `json:"picture,omitempty"`
+	// When the scene was last changed in ISO 8601:2004 format (YYYY-MM-DDThh:mm:ss).
+	LastUpdated string `json:"lastupdated"`
+	// The version of the scene. Version 1 scenes store their light states in the lights; version 2 in the bridge.
+	Version int `json:"version"`
+	// The stored state of each light keyed by light ID. It is only returned when getting a single scene.
+	LightStates map[string]LightState `json:"lightstates,omitempty"`
+}
+
+// NewScene represents a scene to be created on the Hue hub.
+type NewScene struct {
+	// A name for the scene.
+	Name string `json:"name"`
+	// The kind of scene. Defaults to SceneTypeLightScene.
+	Type SceneType `json:"type,omitempty"`
+	// The ID of the group of a group scene.
+	Group string `json:"group,omitempty"`
+	// The IDs of the lights in a light scene.
+	Lights []string `json:"lights,omitempty"`
+	// True if the scene may be deleted by the bridge once it is no longer referenced.
+	Recycle bool `json:"recycle"`
+	// Data stored by the application creating the scene.
+	AppData *SceneAppData `json:"appdata,omitempty"`
+	// The state of each light keyed by light ID. If it is empty the current state of each light is stored instead.
+	LightStates map[string]SceneLightState `json:"lightstates,omitempty"`
+}
+
+// SceneLightState represents the state a scene stores for a light. Unlike NewLightState, fields left nil are not sent,
+// so a state that only sets the color does not also turn the light off, and it has no alerts or increments, which the
+// bridge does not store.
+type SceneLightState struct {
+	// Whether the light is on.
+	On *bool `json:"on,omitempty"`
+	// Brightness of the light, from 1 to 254.
+	Bri *int `json:"bri,omitempty"`
+	// Hue of the light, from 0 to 65535.
+	Hue *int `json:"hue,omitempty"`
+	// Saturation of the light, from 0 to 254.
+	Sat *int `json:"sat,omitempty"`
+	// The x and y coordinates of a color in CIE color space, both between 0 and 1.
+	Xy *[2]float64 `json:"xy,omitempty"`
+	// The Mired color temperature of the light, from 153 (6500K) to 500 (2000K).
+	Ct *int `json:"ct,omitempty"`
+	// The dynamic effect of the light, either "none" or "colorloop".
+	Effect string `json:"effect,omitempty"`
+	// The duration of the transition to the state when the scene is recalled, as a multiple of 100ms.
+	TransitionTime *int `json:"transitiontime,omitempty"`
+}
+
+// SceneAttributes represents the attributes of a scene to be changed. Empty attributes are left unchanged.
+type SceneAttributes struct {
+	// The new name of the scene.
+	Name string `json:"name,omitempty"`
+	// The IDs of the lights that replace the lights in a light scene.
+	Lights []string `json:"lights,omitempty"`
+	// If true the current state of each light in the scene is stored, replacing the stored states.
+	StoreLightState bool `json:"storelightstate,omitempty"`
+}
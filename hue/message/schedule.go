@@ -0,0 +1,88 @@
+package message
+
+import (
+	"strings"
+)
+
+// Command represents a request the Philips Hue bridge sends to itself, such as when a schedule fires or the action of
+// a rule runs.
+type Command struct {
+	// The address of the resource. Schedules use the full address, e.g. /api/<username>/groups/1/action, where the
+	// placeholder <username> is replaced when the schedules package sends the schedule. Rules use the address relative
+	// to the user, e.g. /groups/1/action.
+	Address string `json:"address"`
+	// The HTTP method of the request: PUT, POST or DELETE.
+	Method string `json:"method"`
+	// The body of the request, encoded as JSON, e.g. a NewLightState. When read from the bridge it is a
+	// map[string]interface{}.
+	Body interface{} `json:"body"`
+}
+
+// WithUsername returns a copy of c with the placeholder <username> in its address replaced by username.
+func (c Command) WithUsername(username string) Command {
+	c.Address = strings.Replace(c.Address, "<username>", username, -1)
+	return c
+}
+
+// Statuses of schedules and rules.
+const (
+	StatusEnabled  = "enabled"
+	StatusDisabled = "disabled"
+)
+
+// Schedule represents a command the bridge sends at a given time.
+type Schedule struct {
+	// A unique, editable name given to the schedule.
+	Name string `json:"name"`
+	// A description of the schedule.
+	Description string `json:"description"`
+	// The command sent when the schedule fires.
+	Command Command `json:"command"`
+	// When the schedule fires, as a time pattern in the time zone of the bridge, e.g. W124/T20:00:00. The schedules
+	// package converts time patterns to and from Go types.
+	LocalTime string `json:"localtime"`
+	// When the schedule was created in UTC, in ISO 8601:2004 format (YYYY-MM-DDThh:mm:ss).
+	Created string `json:"created"`
+	// StatusEnabled or StatusDisabled.
+	Status string `json:"status"`
+	// True if the schedule is deleted once it has fired. Only used by absolute time patterns.
+	AutoDelete bool `json:"autodelete,omitempty"`
+	// True if the schedule is deleted by the bridge once it is no longer referenced.
+	Recycle bool `json:"recycle"`
+	// When a timer was started in UTC, in ISO 8601:2004 format (YYYY-MM-DDThh:mm:ss).
+	StartTime string `json:"starttime,omitempty"`
+}
+
+// NewSchedule represents a schedule to be created on the Hue hub.
+type NewSchedule struct {
+	// A name for the schedule, at most 32 characters. Defaults to "schedule".
+	Name string `json:"name,omitempty"`
+	// A description of the schedule, at most 64 characters.
+	Description string `json:"description,omitempty"`
+	// The command sent when the schedule fires.
+	Command Command `json:"command"`
+	// When the schedule fires, as a time pattern in the time zone of the bridge.
+	LocalTime string `json:"localtime"`
+	// StatusEnabled or StatusDisabled. Defaults to StatusEnabled.
+	Status string `json:"status,omitempty"`
+	// If false the schedule is kept once it has fired. Defaults to true for absolute time patterns.
+	AutoDelete *bool `json:"autodelete,omitempty"`
+	// True if the schedule may be deleted by the bridge once it is no longer referenced.
+	Recycle bool `json:"recycle,omitempty"`
+}
+
+// ScheduleAttributes represents the attributes of a schedule to be changed. Empty attributes are left unchanged.
+type ScheduleAttributes struct {
+	// The new name of the schedule.
+	Name string `json:"name,omitempty"`
+	// The new description of the schedule.
+	Description string `json:"description,omitempty"`
+	// The new command sent when the schedule fires.
+	Command *Command `json:"command,omitempty"`
+	// The new time pattern of the schedule. Setting it restarts a timer.
+	LocalTime string `json:"localtime,omitempty"`
+	// StatusEnabled or StatusDisabled. Enabling a timer restarts it.
+	Status string `json:"status,omitempty"`
+	// Whether the schedule is deleted once it has fired.
+	AutoDelete *bool `json:"autodelete,omitempty"`
+}
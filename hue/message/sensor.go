@@ -0,0 +1,209 @@
+package message
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Types of sensors. ZLL sensors are Zigbee devices such as the Hue dimmer switch and motion sensor; CLIP sensors are
+// created by applications to store values that rules can react to.
+const (
+	SensorTypeDaylight          = "Daylight"
+	SensorTypeZLLSwitch         = "ZLLSwitch"
+	SensorTypeZLLPresence       = "ZLLPresence"
+	SensorTypeZLLTemperature    = "ZLLTemperature"
+	SensorTypeZLLLightLevel     = "ZLLLightLevel"
+	SensorTypeCLIPGenericFlag   = "CLIPGenericFlag"
+	SensorTypeCLIPGenericStatus = "CLIPGenericStatus"
+)
+
+// timeLayout is the layout of times reported by the bridge.
+const timeLayout = "2006-01-02T15:04:05"
+
+// Time represents a time reported by the Philips Hue bridge in UTC, in ISO 8601:2004 format (YYYY-MM-DDThh:mm:ss). The
+// bridge reports "none" for times that never happened, which is decoded as the zero time.
+type Time struct {
+	time.Time
+}
+
+// MarshalJSON satisfies the json.Marshaler interface.
+func (t Time) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return json.Marshal("none")
+	}
+	return json.Marshal(t.UTC().Format(timeLayout))
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface.
+func (t *Time) UnmarshalJSON(data []byte) (err error) {
+	var s string
+	if err = json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s == "none" || s == "" {
+		t.Time = time.Time{}
+		return nil
+	}
+	t.Time, err = time.ParseInLocation(timeLayout, s, time.UTC)
+	return err
+}
+
+// SensorConfig represents the configuration of a sensor. Which fields are reported depends on the type of sensor.
+type SensorConfig struct {
+	// True if the sensor is enabled.
+	On bool `json:"on"`
+	// True if the bridge can reach the sensor.
+	Reachable bool `json:"reachable,omitempty"`
+	// The battery level of the sensor in percent.
+	Battery int `json:"battery,omitempty"`
+	// The alert effect of the sensor, e.g. "none".
+	Alert string `json:"alert,omitempty"`
+	// True if the LED of a motion sensor lights up when it detects motion.
+	LEDIndication bool `json:"ledindication,omitempty"`
+	// The sensitivity of a motion sensor, up to SensitivityMax.
+	Sensitivity int `json:"sensitivity,omitempty"`
+	// The highest sensitivity of a motion sensor.
+	SensitivityMax int `json:"sensitivitymax,omitempty"`
+	// The light level below which a light level sensor reports dark.
+	TholdDark int `json:"tholddark,omitempty"`
+	// The difference above TholdDark above which a light level sensor reports daylight.
+	TholdOffset int `json:"tholdoffset,omitempty"`
+	// True if the location of the daylight sensor has been set.
+	Configured bool `json:"configured,omitempty"`
+	// Minutes the daylight sensor reports daylight after sunrise, between -120 and 120.
+	SunriseOffset int `json:"sunriseoffset,omitempty"`
+	// Minutes the daylight sensor reports daylight after sunset, between -120 and 120.
+	SunsetOffset int `json:"sunsetoffset,omitempty"`
+}
+
+// Sensor represents the attributes, state and configuration of a sensor.
+type Sensor struct {
+	// A unique, editable name given to the sensor.
+	Name string `json:"name"`
+	// The type of sensor, e.g. SensorTypeZLLSwitch.
+	Type string `json:"type"`
+	// The model of the sensor.
+	ModelID string `json:"modelid"`
+	// The manufacturer name.
+	ManufacturerName string `json:"manufacturername"`
+	// An identifier for the software version running on the sensor.
+	SwVersion string `json:"swversion"`
+	// Unique id of the sensor, e.g. the MAC address of the device with an endpoint.
+	UniqueID string `json:"uniqueid,omitempty"`
+	// The state of the sensor. Its fields depend on the type of sensor; use DecodeState to read them.
+	State json.RawMessage `json:"state"`
+	// The configuration of the sensor.
+	Config SensorConfig `json:"config"`
+	// True if the sensor is deleted by the bridge once it is no longer referenced.
+	Recycle bool `json:"recycle,omitempty"`
+}
+
+// DecodeState decodes the state of the sensor into state, the state type matching the type of sensor, e.g. a
+// *SwitchState for a ZLLSwitch.
+func (s *Sensor) DecodeState(state interface{}) (err error) {
+	return json.Unmarshal(s.State, state)
+}
+
+// Button events of a ZLLSwitch, the last three digits of its button event.
+const (
+	ButtonInitialPress = 0
+	ButtonHold         = 1
+	ButtonShortRelease = 2
+	ButtonLongRelease  = 3
+)
+
+// SwitchState represents the state of a ZLLSwitch, such as the Hue dimmer switch.
+type SwitchState struct {
+	// The last button event, the button number times 1000 plus the event, e.g. 1002 for a short press of button 1.
+	ButtonEvent int `json:"buttonevent"`
+	// When the button event happened.
+	LastUpdated Time `json:"lastupdated"`
+}
+
+// Button returns the number of the button of the last button event, starting at 1.
+func (s SwitchState) Button() int {
+	return s.ButtonEvent / 1000
+}
+
+// Event returns what happened to the button, e.g. ButtonShortRelease.
+func (s SwitchState) Event() int {
+	return s.ButtonEvent % 1000
+}
+
+// PresenceState represents the state of a ZLLPresence sensor, the motion sensor.
+type PresenceState struct {
+	// True if motion was detected.
+	Presence bool `json:"presence"`
+	// When the presence last changed.
+	LastUpdated Time `json:"lastupdated"`
+}
+
+// TemperatureState represents the state of a ZLLTemperature sensor.
+type TemperatureState struct {
+	// The temperature in hundredths of a degree Celsius, e.g. 2150 for 21.5°C.
+	Temperature int `json:"temperature"`
+	// When the temperature was last measured.
+	LastUpdated Time `json:"lastupdated"`
+}
+
+// Celsius returns the temperature in degrees Celsius.
+func (s TemperatureState) Celsius() float64 {
+	return float64(s.Temperature) / 100
+}
+
+// LightLevelState represents the state of a ZLLLightLevel sensor.
+type LightLevelState struct {
+	// The light level as 10000 log10(lux) + 1.
+	LightLevel int `json:"lightlevel"`
+	// True if the light level is below the TholdDark of the sensor.
+	Dark bool `json:"dark"`
+	// True if the light level is above TholdDark plus TholdOffset.
+	Daylight bool `json:"daylight"`
+	// When the light level was last measured.
+	LastUpdated Time `json:"lastupdated"`
+}
+
+// DaylightState represents the state of the Daylight sensor of the bridge, which reports whether the sun is up at the
+// configured location.
+type DaylightState struct {
+	// True between sunrise and sunset, adjusted by the offsets of the sensor. Nil until the location is configured.
+	Daylight *bool `json:"daylight"`
+	// When daylight last changed.
+	LastUpdated Time `json:"lastupdated"`
+}
+
+// GenericFlagState represents the state of a CLIPGenericFlag sensor.
+type GenericFlagState struct {
+	// The flag.
+	Flag bool `json:"flag"`
+	// When the flag was last set.
+	LastUpdated Time `json:"lastupdated"`
+}
+
+// GenericStatusState represents the state of a CLIPGenericStatus sensor.
+type GenericStatusState struct {
+	// The status.
+	Status int `json:"status"`
+	// When the status was last set.
+	LastUpdated Time `json:"lastupdated"`
+}
+
+// NewSensor represents a CLIP sensor to be created on the Hue hub.
+type NewSensor struct {
+	// A name for the sensor.
+	Name string `json:"name"`
+	// The type of sensor, e.g. SensorTypeCLIPGenericFlag.
+	Type string `json:"type"`
+	// The model of the sensor, chosen by the application.
+	ModelID string `json:"modelid"`
+	// The manufacturer name, chosen by the application.
+	ManufacturerName string `json:"manufacturername"`
+	// An identifier for the software version of the sensor, chosen by the application.
+	SwVersion string `json:"swversion"`
+	// A unique id for the sensor, chosen by the application.
+	UniqueID string `json:"uniqueid"`
+	// The initial state of the sensor, e.g. {"flag": false}. Defaults to the default state of the type.
+	State map[string]interface{} `json:"state,omitempty"`
+	// True if the sensor may be deleted by the bridge once it is no longer referenced.
+	Recycle bool `json:"recycle,omitempty"`
+}
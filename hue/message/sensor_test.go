@@ -0,0 +1,110 @@
+package message
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimeUnmarshalJSON(t *testing.T) {
+	for _, tc := range []struct {
+		data string
+		want time.Time
+		ok   bool
+	}{
+		{`"none"`, time.Time{}, true},
+		{`""`, time.Time{}, true},
+		{`"2017-03-04T05:06:07"`, time.Date(2017, 3, 4, 5, 6, 7, 0, time.UTC), true},
+		{`"yesterday"`, time.Time{}, false},
+		{`1488603967`, time.Time{}, false},
+	} {
+		// A time that was set before must be replaced, including by the zero time.
+		decoded := Time{Time: time.Now()}
+		err := json.Unmarshal([]byte(tc.data), &decoded)
+		if !tc.ok {
+			if err == nil {
+				t.Errorf("Unmarshal(%v) = %v, want an error", tc.data, decoded)
+			}
+			continue
+		}
+		if err != nil || !decoded.Equal(tc.want) || (!decoded.IsZero() && decoded.Location() != time.UTC) {
+			t.Errorf("Unmarshal(%v) = %v, %v, want %v", tc.data, decoded, err, tc.want)
+		}
+	}
+
+	// Decoded times are encoded the same way.
+	for _, data := range []string{`"none"`, `"2017-03-04T05:06:07"`} {
+		decoded := Time{}
+		if err := json.Unmarshal([]byte(data), &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if encoded, err := json.Marshal(decoded); err != nil || string(encoded) != data {
+			t.Errorf("Marshal(Unmarshal(%v)) = %s, %v", data, encoded, err)
+		}
+	}
+}
+
+func TestDecodeState(t *testing.T) {
+	updated := Time{Time: time.Date(2017, 3, 4, 5, 6, 7, 0, time.UTC)}
+
+	sensor := Sensor{State: json.RawMessage(`{"buttonevent":3002,"lastupdated":"2017-03-04T05:06:07"}`)}
+	switchState := SwitchState{}
+	if err := sensor.DecodeState(&switchState); err != nil {
+		t.Fatal(err)
+	}
+	if switchState.Button() != 3 || switchState.Event() != ButtonShortRelease ||
+		!switchState.LastUpdated.Equal(updated.Time) {
+		t.Errorf("switch state = %+v, want a short release of button 3", switchState)
+	}
+
+	sensor = Sensor{State: json.RawMessage(`{"presence":true,"lastupdated":"2017-03-04T05:06:07"}`)}
+	presence := PresenceState{}
+	err := sensor.DecodeState(&presence)
+	if err != nil || !presence.Presence || !presence.LastUpdated.Equal(updated.Time) {
+		t.Errorf("presence state = %+v, %v, want presence", presence, err)
+	}
+
+	sensor = Sensor{State: json.RawMessage(`{"temperature":2150,"lastupdated":"none"}`)}
+	temperature := TemperatureState{}
+	if err := sensor.DecodeState(&temperature); err != nil || temperature.Celsius() != 21.5 ||
+		!temperature.LastUpdated.IsZero() {
+		t.Errorf("temperature state = %+v, %v, want 21.5°C never updated", temperature, err)
+	}
+
+	sensor = Sensor{State: json.RawMessage(`{"lightlevel":20000,"dark":false,"daylight":true,"lastupdated":"none"}`)}
+	lightLevel := LightLevelState{}
+	if err := sensor.DecodeState(&lightLevel); err != nil || lightLevel.LightLevel != 20000 || !lightLevel.Daylight {
+		t.Errorf("light level state = %+v, %v, want daylight at 20000", lightLevel, err)
+	}
+
+	// The daylight sensor reports null until its location is configured.
+	for _, tc := range []struct {
+		data       string
+		configured bool
+	}{
+		{`{"daylight":null,"lastupdated":"none"}`, false},
+		{`{"daylight":true,"lastupdated":"2017-03-04T05:06:07"}`, true},
+	} {
+		sensor = Sensor{State: json.RawMessage(tc.data)}
+		daylight := DaylightState{}
+		if err := sensor.DecodeState(&daylight); err != nil {
+			t.Fatal(err)
+		}
+		if tc.configured && (daylight.Daylight == nil || !*daylight.Daylight) ||
+			!tc.configured && daylight.Daylight != nil {
+			t.Errorf("DecodeState(%v) = %+v, want daylight set %v", tc.data, daylight, tc.configured)
+		}
+	}
+
+	sensor = Sensor{State: json.RawMessage(`{"flag":true,"lastupdated":"none"}`)}
+	flag := GenericFlagState{}
+	if err := sensor.DecodeState(&flag); err != nil || !flag.Flag {
+		t.Errorf("flag state = %+v, %v, want the flag set", flag, err)
+	}
+
+	sensor = Sensor{State: json.RawMessage(`{"status":2,"lastupdated":"none"}`)}
+	status := GenericStatusState{}
+	if err := sensor.DecodeState(&status); err != nil || status.Status != 2 {
+		t.Errorf("status state = %+v, %v, want status 2", status, err)
+	}
+}
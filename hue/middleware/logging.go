@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"fmt"
+
+	"github.com/drombosky/disco-dance-party/hue/logging"
+)
+
+// Logging returns a middleware that logs each request and the body of its response to logger at debug level. If logger
+// is nil nothing is logged.
+func Logging(logger logging.Logger) Middleware {
+	logger = logging.OrNop(logger)
+	return Func(func(req *Request, next RoundTripper) (resp *Response) {
+		resp = next.RoundTrip(req)
+
+		fields := logging.Fields{
+			logging.Package:   "github.com/drombosky/disco-dance-party/hue/middleware",
+			logging.Operation: "Logging",
+			logging.Address:   req.Address,
+			logging.Method:    req.Method,
+			logging.Request:   string(req.Message),
+			logging.Latency:   resp.Duration,
+		}
+		fields[logging.Response] = string(resp.Body)
+		if resp.Err != nil {
+			fields[logging.Error] = resp.Err
+			logger.Debug(fmt.Sprintf("%v %v failed: %v", req.Method, req.Address, resp.Err), fields)
+			return resp
+		}
+		logger.Debug(fmt.Sprintf("%v %v %v", req.Method, req.Address, string(resp.Body)), fields)
+		return resp
+	})
+}
@@ -0,0 +1,60 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/drombosky/disco-dance-party/hue/logging"
+	"github.com/drombosky/disco-dance-party/hue/middleware"
+)
+
+// results is the body the bridge answers a change of state with.
+const results = `[{"success":{"/lights/1/state/on":true}}]`
+
+// bridge answers every request with results, decoding it like client.Client does.
+var bridge = middleware.RoundTripperFunc(func(req *middleware.Request) *middleware.Response {
+	if req.Resp == nil {
+		return &middleware.Response{}
+	}
+	return &middleware.Response{Err: json.Unmarshal([]byte(results), req.Resp)}
+})
+
+// recordingLogger keeps the fields of every message logged.
+type recordingLogger struct {
+	fields []logging.Fields
+}
+
+func (l *recordingLogger) Debug(msg string, fields logging.Fields) {
+	l.fields = append(l.fields, fields)
+}
+func (l *recordingLogger) Info(msg string, fields logging.Fields) {
+	l.fields = append(l.fields, fields)
+}
+func (l *recordingLogger) Error(msg string, fields logging.Fields) {
+	l.fields = append(l.fields, fields)
+}
+
+func TestLoggingLogsTheResponseBody(t *testing.T) {
+	logger := &recordingLogger{}
+	client := middleware.Chain(bridge, middleware.Logging(logger))
+
+	// The response is logged whether or not the caller decodes it.
+	resp := []map[string]interface{}{}
+	if err := client.Do("PUT", "/api/<username>/lights/1/state", []byte(`{"on":true}`), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp) != 1 {
+		t.Errorf("Do() decoded %v, want one result", resp)
+	}
+	if err := client.Do("PUT", "/api/<username>/lights/1/state", []byte(`{"on":true}`), nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(logger.fields) != 2 {
+		t.Fatalf("logged %v messages, want 2", len(logger.fields))
+	}
+	for i, fields := range logger.fields {
+		if fields[logging.Response] != results {
+			t.Errorf("message %v logged response %v, want %v", i, fields[logging.Response], results)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// Package middleware composes behavior, such as logging, metrics or fault injection, around the requests of a
+// hue.Client. A Middleware wraps a hue.Client in another, and Chain applies several in order. Middlewares that only
+// need to look at or change a request and its response can be written with Func, which presents each request as a
+// Request and its outcome as a Response, in the spirit of http.RoundTripper.
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue"
+)
+
+// Middleware represents a function that wraps a hue.Client to add behavior around its requests.
+type Middleware func(next hue.Client) hue.Client
+
+// Request represents a request sent through a hue.Client.
+type Request struct {
+	// Context of the request. Defaults to context.Background().
+	Context context.Context
+	// The HTTP method, e.g. PUT.
+	Method string
+	// The address of the resource, e.g. /api/<username>/lights/1.
+	Address string
+	// The body of the request, or nil.
+	Message []byte
+	// Resp is the value the response is decoded into, or nil if the response is not needed.
+	Resp interface{}
+}
+
+// Response represents the outcome of a request.
+type Response struct {
+	// Resp is the value the response was decoded into, the same as Request.Resp.
+	Resp interface{}
+	// Body is the body of the response, or nil if there was none. It is set even if Request.Resp is nil.
+	Body json.RawMessage
+	// Err is the error returned for the request, or nil.
+	Err error
+	// Duration is how long the request took.
+	Duration time.Duration
+}
+
+// RoundTripper represents a step that handles a request, either by answering it or by passing it on.
+type RoundTripper interface {
+	RoundTrip(req *Request) (resp *Response)
+}
+
+// RoundTripperFunc adapts a function to a RoundTripper. It also satisfies hue.Client, so it can be returned by a
+// Middleware.
+type RoundTripperFunc func(req *Request) (resp *Response)
+
+// RoundTrip calls f(req).
+func (f RoundTripperFunc) RoundTrip(req *Request) (resp *Response) {
+	return f(req)
+}
+
+// Do handles a request with f.
+func (f RoundTripperFunc) Do(method string, address string, message []byte, resp interface{}) (err error) {
+	return f.DoContext(context.Background(), method, address, message, resp)
+}
+
+// DoContext handles a request with f.
+func (f RoundTripperFunc) DoContext(ctx context.Context, method string, address string, message []byte,
+	resp interface{}) (err error) {
+	return f(&Request{Context: ctx, Method: method, Address: address, Message: message, Resp: resp}).Err
+}
+
+// Send sends req through client and returns its outcome. The response is decoded into its body first, so the body is
+// available even if req.Resp is nil, and then into req.Resp.
+func Send(client hue.Client, req *Request) (resp *Response) {
+	ctx := req.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	start := time.Now()
+	var body json.RawMessage
+	err := client.DoContext(ctx, req.Method, req.Address, req.Message, &body)
+	duration := time.Since(start)
+	if _, ok := err.(*json.SyntaxError); ok && req.Resp == nil {
+		// The response is not JSON, which only matters to callers that decode it.
+		err, body = nil, nil
+	}
+	if req.Resp != nil && len(body) > 0 {
+		// The client may decode a response even when it returns an error, e.g. the results of a partially applied
+		// change, so the body is decoded either way.
+		if decodeErr := json.Unmarshal(body, req.Resp); err == nil {
+			err = decodeErr
+		}
+	}
+	return &Response{Resp: req.Resp, Body: body, Err: err, Duration: duration}
+}
+
+// Func returns a middleware that handles each request with f. f passes the request on by calling next.RoundTrip, and
+// can change the request before and the response after.
+func Func(f func(req *Request, next RoundTripper) (resp *Response)) Middleware {
+	return func(next hue.Client) hue.Client {
+		rt := clientRoundTripper{client: next}
+		return RoundTripperFunc(func(req *Request) *Response {
+			return f(req, rt)
+		})
+	}
+}
+
+// clientRoundTripper adapts a hue.Client to a RoundTripper.
+type clientRoundTripper struct {
+	client hue.Client
+}
+
+// RoundTrip sends req through the client.
+func (c clientRoundTripper) RoundTrip(req *Request) (resp *Response) {
+	return Send(c.client, req)
+}
+
+// Chain returns client wrapped in middlewares. The first middleware is the outermost, so it sees each request first
+// and its response last.
+func Chain(client hue.Client, middlewares ...Middleware) hue.Client {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		client = middlewares[i](client)
+	}
+	return client
+}
@@ -0,0 +1,62 @@
+package middleware_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/drombosky/disco-dance-party/hue/middleware"
+)
+
+// tracing returns a middleware that records name in calls before and after passing each request on.
+func tracing(name string, calls *[]string) middleware.Middleware {
+	return middleware.Func(func(req *middleware.Request, next middleware.RoundTripper) *middleware.Response {
+		*calls = append(*calls, name+" request")
+		resp := next.RoundTrip(req)
+		*calls = append(*calls, name+" response")
+		return resp
+	})
+}
+
+// recordingBridge returns a bridge that records each request in calls.
+func recordingBridge(calls *[]string) middleware.RoundTripperFunc {
+	return func(req *middleware.Request) *middleware.Response {
+		*calls = append(*calls, "bridge "+req.Address)
+		return bridge(req)
+	}
+}
+
+func TestChainOrder(t *testing.T) {
+	calls := []string{}
+	client := middleware.Chain(recordingBridge(&calls), tracing("first", &calls), tracing("second", &calls),
+		tracing("third", &calls))
+	if err := client.Do("PUT", "/api/<username>/lights/1/state", []byte(`{"on":true}`), nil); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"first request", "second request", "third request",
+		"bridge /api/<username>/lights/1/state",
+		"third response", "second response", "first response",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestFuncShortCircuits(t *testing.T) {
+	calls := []string{}
+	refused := errors.New("refused")
+	refuse := middleware.Func(func(req *middleware.Request, next middleware.RoundTripper) *middleware.Response {
+		calls = append(calls, "refuse request")
+		return &middleware.Response{Err: refused}
+	})
+	client := middleware.Chain(recordingBridge(&calls), tracing("first", &calls), refuse, tracing("third", &calls))
+	if err := client.Do("PUT", "/api/<username>/lights/1/state", []byte(`{"on":true}`), nil); err != refused {
+		t.Errorf("Do() = %v, want %v", err, refused)
+	}
+	// The middlewares after the one that answered and the bridge never see the request.
+	want := []string{"first request", "refuse request", "first response"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
@@ -4,6 +4,7 @@
 package mockHue
 
 import (
+	context "context"
 	message "github.com/drombosky/disco-dance-party/hue/message"
 	gomock "github.com/golang/mock/gomock"
 )
@@ -39,6 +40,16 @@ func (_mr *_MockClientRecorder) Do(arg0, arg1, arg2, arg3 interface{}) *gomock.C
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Do", arg0, arg1, arg2, arg3)
 }
 
+func (_m *MockClient) DoContext(ctx context.Context, method string, address string, message []byte, resp interface{}) error {
+	ret := _m.ctrl.Call(_m, "DoContext", ctx, method, address, message, resp)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockClientRecorder) DoContext(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "DoContext", arg0, arg1, arg2, arg3, arg4)
+}
+
 // Mock of Lights interface
 type MockLights struct {
 	ctrl     *gomock.Controller
@@ -71,6 +82,17 @@ func (_mr *_MockLightsRecorder) GetAll() *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetAll")
 }
 
+func (_m *MockLights) GetAllContext(ctx context.Context) (map[string]message.Light, error) {
+	ret := _m.ctrl.Call(_m, "GetAllContext", ctx)
+	ret0, _ := ret[0].(map[string]message.Light)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockLightsRecorder) GetAllContext(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetAllContext", arg0)
+}
+
 func (_m *MockLights) GetNew() (*message.GetNewResp, error) {
 	ret := _m.ctrl.Call(_m, "GetNew")
 	ret0, _ := ret[0].(*message.GetNewResp)
@@ -82,6 +104,17 @@ func (_mr *_MockLightsRecorder) GetNew() *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetNew")
 }
 
+func (_m *MockLights) GetNewContext(ctx context.Context) (*message.GetNewResp, error) {
+	ret := _m.ctrl.Call(_m, "GetNewContext", ctx)
+	ret0, _ := ret[0].(*message.GetNewResp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockLightsRecorder) GetNewContext(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetNewContext", arg0)
+}
+
 func (_m *MockLights) Get(id string) (*message.Light, error) {
 	ret := _m.ctrl.Call(_m, "Get", id)
 	ret0, _ := ret[0].(*message.Light)
@@ -93,6 +126,17 @@ func (_mr *_MockLightsRecorder) Get(arg0 interface{}) *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Get", arg0)
 }
 
+func (_m *MockLights) GetContext(ctx context.Context, id string) (*message.Light, error) {
+	ret := _m.ctrl.Call(_m, "GetContext", ctx, id)
+	ret0, _ := ret[0].(*message.Light)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockLightsRecorder) GetContext(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetContext", arg0, arg1)
+}
+
 func (_m *MockLights) Rename(id string, name string) error {
 	ret := _m.ctrl.Call(_m, "Rename", id, name)
 	ret0, _ := ret[0].(error)
@@ -103,6 +147,16 @@ func (_mr *_MockLightsRecorder) Rename(arg0, arg1 interface{}) *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Rename", arg0, arg1)
 }
 
+func (_m *MockLights) RenameContext(ctx context.Context, id string, name string) error {
+	ret := _m.ctrl.Call(_m, "RenameContext", ctx, id, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockLightsRecorder) RenameContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "RenameContext", arg0, arg1, arg2)
+}
+
 func (_m *MockLights) Set(id string, state message.NewLightState) error {
 	ret := _m.ctrl.Call(_m, "Set", id, state)
 	ret0, _ := ret[0].(error)
@@ -113,6 +167,16 @@ func (_mr *_MockLightsRecorder) Set(arg0, arg1 interface{}) *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Set", arg0, arg1)
 }
 
+func (_m *MockLights) SetContext(ctx context.Context, id string, state message.NewLightState) error {
+	ret := _m.ctrl.Call(_m, "SetContext", ctx, id, state)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockLightsRecorder) SetContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetContext", arg0, arg1, arg2)
+}
+
 func (_m *MockLights) Delete(id string) error {
 	ret := _m.ctrl.Call(_m, "Delete", id)
 	ret0, _ := ret[0].(error)
@@ -122,3 +186,790 @@ func (_m *MockLights) Delete(id string) error {
 func (_mr *_MockLightsRecorder) Delete(arg0 interface{}) *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Delete", arg0)
 }
+
+func (_m *MockLights) DeleteContext(ctx context.Context, id string) error {
+	ret := _m.ctrl.Call(_m, "DeleteContext", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockLightsRecorder) DeleteContext(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "DeleteContext", arg0, arg1)
+}
+
+// Mock of Groups interface
+type MockGroups struct {
+	ctrl     *gomock.Controller
+	recorder *_MockGroupsRecorder
+}
+
+// Recorder for MockGroups (not exported)
+type _MockGroupsRecorder struct {
+	mock *MockGroups
+}
+
+func NewMockGroups(ctrl *gomock.Controller) *MockGroups {
+	mock := &MockGroups{ctrl: ctrl}
+	mock.recorder = &_MockGroupsRecorder{mock}
+	return mock
+}
+
+func (_m *MockGroups) EXPECT() *_MockGroupsRecorder {
+	return _m.recorder
+}
+
+func (_m *MockGroups) GetAll() (map[string]message.Group, error) {
+	ret := _m.ctrl.Call(_m, "GetAll")
+	ret0, _ := ret[0].(map[string]message.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockGroupsRecorder) GetAll() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetAll")
+}
+
+func (_m *MockGroups) GetAllContext(ctx context.Context) (map[string]message.Group, error) {
+	ret := _m.ctrl.Call(_m, "GetAllContext", ctx)
+	ret0, _ := ret[0].(map[string]message.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockGroupsRecorder) GetAllContext(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetAllContext", arg0)
+}
+
+func (_m *MockGroups) Get(id string) (*message.Group, error) {
+	ret := _m.ctrl.Call(_m, "Get", id)
+	ret0, _ := ret[0].(*message.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockGroupsRecorder) Get(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Get", arg0)
+}
+
+func (_m *MockGroups) GetContext(ctx context.Context, id string) (*message.Group, error) {
+	ret := _m.ctrl.Call(_m, "GetContext", ctx, id)
+	ret0, _ := ret[0].(*message.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockGroupsRecorder) GetContext(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetContext", arg0, arg1)
+}
+
+func (_m *MockGroups) Create(group message.NewGroup) (string, error) {
+	ret := _m.ctrl.Call(_m, "Create", group)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockGroupsRecorder) Create(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Create", arg0)
+}
+
+func (_m *MockGroups) CreateContext(ctx context.Context, group message.NewGroup) (string, error) {
+	ret := _m.ctrl.Call(_m, "CreateContext", ctx, group)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockGroupsRecorder) CreateContext(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "CreateContext", arg0, arg1)
+}
+
+func (_m *MockGroups) Update(id string, attributes message.GroupAttributes) error {
+	ret := _m.ctrl.Call(_m, "Update", id, attributes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockGroupsRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Update", arg0, arg1)
+}
+
+func (_m *MockGroups) UpdateContext(ctx context.Context, id string, attributes message.GroupAttributes) error {
+	ret := _m.ctrl.Call(_m, "UpdateContext", ctx, id, attributes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockGroupsRecorder) UpdateContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "UpdateContext", arg0, arg1, arg2)
+}
+
+func (_m *MockGroups) SetAction(id string, state message.NewLightState) error {
+	ret := _m.ctrl.Call(_m, "SetAction", id, state)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockGroupsRecorder) SetAction(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetAction", arg0, arg1)
+}
+
+func (_m *MockGroups) SetActionContext(ctx context.Context, id string, state message.NewLightState) error {
+	ret := _m.ctrl.Call(_m, "SetActionContext", ctx, id, state)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockGroupsRecorder) SetActionContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetActionContext", arg0, arg1, arg2)
+}
+
+func (_m *MockGroups) Delete(id string) error {
+	ret := _m.ctrl.Call(_m, "Delete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockGroupsRecorder) Delete(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Delete", arg0)
+}
+
+func (_m *MockGroups) DeleteContext(ctx context.Context, id string) error {
+	ret := _m.ctrl.Call(_m, "DeleteContext", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockGroupsRecorder) DeleteContext(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "DeleteContext", arg0, arg1)
+}
+
+// Mock of Scenes interface
+type MockScenes struct {
+	ctrl     *gomock.Controller
+	recorder *_MockScenesRecorder
+}
+
+// Recorder for MockScenes (not exported)
+type _MockScenesRecorder struct {
+	mock *MockScenes
+}
+
+func NewMockScenes(ctrl *gomock.Controller) *MockScenes {
+	mock := &MockScenes{ctrl: ctrl}
+	mock.recorder = &_MockScenesRecorder{mock}
+	return mock
+}
+
+func (_m *MockScenes) EXPECT() *_MockScenesRecorder {
+	return _m.recorder
+}
+
+func (_m *MockScenes) GetAll() (map[string]message.Scene, error) {
+	ret := _m.ctrl.Call(_m, "GetAll")
+	ret0, _ := ret[0].(map[string]message.Scene)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockScenesRecorder) GetAll() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetAll")
+}
+
+func (_m *MockScenes) GetAllContext(ctx context.Context) (map[string]message.Scene, error) {
+	ret := _m.ctrl.Call(_m, "GetAllContext", ctx)
+	ret0, _ := ret[0].(map[string]message.Scene)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockScenesRecorder) GetAllContext(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetAllContext", arg0)
+}
+
+func (_m *MockScenes) Get(id string) (*message.Scene, error) {
+	ret := _m.ctrl.Call(_m, "Get", id)
+	ret0, _ := ret[0].(*message.Scene)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockScenesRecorder) Get(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Get", arg0)
+}
+
+func (_m *MockScenes) GetContext(ctx context.Context, id string) (*message.Scene, error) {
+	ret := _m.ctrl.Call(_m, "GetContext", ctx, id)
+	ret0, _ := ret[0].(*message.Scene)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockScenesRecorder) GetContext(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetContext", arg0, arg1)
+}
+
+func (_m *MockScenes) Create(scene message.NewScene) (string, error) {
+	ret := _m.ctrl.Call(_m, "Create", scene)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockScenesRecorder) Create(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Create", arg0)
+}
+
+func (_m *MockScenes) CreateContext(ctx context.Context, scene message.NewScene) (string, error) {
+	ret := _m.ctrl.Call(_m, "CreateContext", ctx, scene)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockScenesRecorder) CreateContext(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "CreateContext", arg0, arg1)
+}
+
+func (_m *MockScenes) Update(id string, attributes message.SceneAttributes) error {
+	ret := _m.ctrl.Call(_m, "Update", id, attributes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockScenesRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Update", arg0, arg1)
+}
+
+func (_m *MockScenes) UpdateContext(ctx context.Context, id string, attributes message.SceneAttributes) error {
+	ret := _m.ctrl.Call(_m, "UpdateContext", ctx, id, attributes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockScenesRecorder) UpdateContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "UpdateContext", arg0, arg1, arg2)
+}
+
+func (_m *MockScenes) SetLightState(id string, lightID string, state message.SceneLightState) error {
+	ret := _m.ctrl.Call(_m, "SetLightState", id, lightID, state)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockScenesRecorder) SetLightState(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetLightState", arg0, arg1, arg2)
+}
+
+func (_m *MockScenes) SetLightStateContext(ctx context.Context, id string, lightID string, state message.SceneLightState) error {
+	ret := _m.ctrl.Call(_m, "SetLightStateContext", ctx, id, lightID, state)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockScenesRecorder) SetLightStateContext(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetLightStateContext", arg0, arg1, arg2, arg3)
+}
+
+func (_m *MockScenes) Delete(id string) error {
+	ret := _m.ctrl.Call(_m, "Delete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockScenesRecorder) Delete(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Delete", arg0)
+}
+
+func (_m *MockScenes) DeleteContext(ctx context.Context, id string) error {
+	ret := _m.ctrl.Call(_m, "DeleteContext", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockScenesRecorder) DeleteContext(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "DeleteContext", arg0, arg1)
+}
+
+func (_m *MockScenes) Recall(id string, group string) error {
+	ret := _m.ctrl.Call(_m, "Recall", id, group)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockScenesRecorder) Recall(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Recall", arg0, arg1)
+}
+
+func (_m *MockScenes) RecallContext(ctx context.Context, id string, group string) error {
+	ret := _m.ctrl.Call(_m, "RecallContext", ctx, id, group)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockScenesRecorder) RecallContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "RecallContext", arg0, arg1, arg2)
+}
+
+// Mock of Schedules interface
+type MockSchedules struct {
+	ctrl     *gomock.Controller
+	recorder *_MockSchedulesRecorder
+}
+
+// Recorder for MockSchedules (not exported)
+type _MockSchedulesRecorder struct {
+	mock *MockSchedules
+}
+
+func NewMockSchedules(ctrl *gomock.Controller) *MockSchedules {
+	mock := &MockSchedules{ctrl: ctrl}
+	mock.recorder = &_MockSchedulesRecorder{mock}
+	return mock
+}
+
+func (_m *MockSchedules) EXPECT() *_MockSchedulesRecorder {
+	return _m.recorder
+}
+
+func (_m *MockSchedules) GetAll() (map[string]message.Schedule, error) {
+	ret := _m.ctrl.Call(_m, "GetAll")
+	ret0, _ := ret[0].(map[string]message.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockSchedulesRecorder) GetAll() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetAll")
+}
+
+func (_m *MockSchedules) GetAllContext(ctx context.Context) (map[string]message.Schedule, error) {
+	ret := _m.ctrl.Call(_m, "GetAllContext", ctx)
+	ret0, _ := ret[0].(map[string]message.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockSchedulesRecorder) GetAllContext(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetAllContext", arg0)
+}
+
+func (_m *MockSchedules) Get(id string) (*message.Schedule, error) {
+	ret := _m.ctrl.Call(_m, "Get", id)
+	ret0, _ := ret[0].(*message.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockSchedulesRecorder) Get(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Get", arg0)
+}
+
+func (_m *MockSchedules) GetContext(ctx context.Context, id string) (*message.Schedule, error) {
+	ret := _m.ctrl.Call(_m, "GetContext", ctx, id)
+	ret0, _ := ret[0].(*message.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockSchedulesRecorder) GetContext(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetContext", arg0, arg1)
+}
+
+func (_m *MockSchedules) Create(schedule message.NewSchedule) (string, error) {
+	ret := _m.ctrl.Call(_m, "Create", schedule)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockSchedulesRecorder) Create(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Create", arg0)
+}
+
+func (_m *MockSchedules) CreateContext(ctx context.Context, schedule message.NewSchedule) (string, error) {
+	ret := _m.ctrl.Call(_m, "CreateContext", ctx, schedule)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockSchedulesRecorder) CreateContext(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "CreateContext", arg0, arg1)
+}
+
+func (_m *MockSchedules) Update(id string, attributes message.ScheduleAttributes) error {
+	ret := _m.ctrl.Call(_m, "Update", id, attributes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockSchedulesRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Update", arg0, arg1)
+}
+
+func (_m *MockSchedules) UpdateContext(ctx context.Context, id string, attributes message.ScheduleAttributes) error {
+	ret := _m.ctrl.Call(_m, "UpdateContext", ctx, id, attributes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockSchedulesRecorder) UpdateContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "UpdateContext", arg0, arg1, arg2)
+}
+
+func (_m *MockSchedules) Delete(id string) error {
+	ret := _m.ctrl.Call(_m, "Delete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockSchedulesRecorder) Delete(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Delete", arg0)
+}
+
+func (_m *MockSchedules) DeleteContext(ctx context.Context, id string) error {
+	ret := _m.ctrl.Call(_m, "DeleteContext", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockSchedulesRecorder) DeleteContext(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "DeleteContext", arg0, arg1)
+}
+
+// Mock of Sensors interface
+type MockSensors struct {
+	ctrl     *gomock.Controller
+	recorder *_MockSensorsRecorder
+}
+
+// Recorder for MockSensors (not exported)
+type _MockSensorsRecorder struct {
+	mock *MockSensors
+}
+
+func NewMockSensors(ctrl *gomock.Controller) *MockSensors {
+	mock := &MockSensors{ctrl: ctrl}
+	mock.recorder = &_MockSensorsRecorder{mock}
+	return mock
+}
+
+func (_m *MockSensors) EXPECT() *_MockSensorsRecorder {
+	return _m.recorder
+}
+
+func (_m *MockSensors) GetAll() (map[string]message.Sensor, error) {
+	ret := _m.ctrl.Call(_m, "GetAll")
+	ret0, _ := ret[0].(map[string]message.Sensor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockSensorsRecorder) GetAll() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetAll")
+}
+
+func (_m *MockSensors) GetAllContext(ctx context.Context) (map[string]message.Sensor, error) {
+	ret := _m.ctrl.Call(_m, "GetAllContext", ctx)
+	ret0, _ := ret[0].(map[string]message.Sensor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockSensorsRecorder) GetAllContext(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetAllContext", arg0)
+}
+
+func (_m *MockSensors) GetNew() (*message.GetNewResp, error) {
+	ret := _m.ctrl.Call(_m, "GetNew")
+	ret0, _ := ret[0].(*message.GetNewResp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockSensorsRecorder) GetNew() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetNew")
+}
+
+func (_m *MockSensors) GetNewContext(ctx context.Context) (*message.GetNewResp, error) {
+	ret := _m.ctrl.Call(_m, "GetNewContext", ctx)
+	ret0, _ := ret[0].(*message.GetNewResp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockSensorsRecorder) GetNewContext(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetNewContext", arg0)
+}
+
+func (_m *MockSensors) Search() error {
+	ret := _m.ctrl.Call(_m, "Search")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockSensorsRecorder) Search() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Search")
+}
+
+func (_m *MockSensors) SearchContext(ctx context.Context) error {
+	ret := _m.ctrl.Call(_m, "SearchContext", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockSensorsRecorder) SearchContext(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SearchContext", arg0)
+}
+
+func (_m *MockSensors) Get(id string) (*message.Sensor, error) {
+	ret := _m.ctrl.Call(_m, "Get", id)
+	ret0, _ := ret[0].(*message.Sensor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockSensorsRecorder) Get(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Get", arg0)
+}
+
+func (_m *MockSensors) GetContext(ctx context.Context, id string) (*message.Sensor, error) {
+	ret := _m.ctrl.Call(_m, "GetContext", ctx, id)
+	ret0, _ := ret[0].(*message.Sensor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockSensorsRecorder) GetContext(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetContext", arg0, arg1)
+}
+
+func (_m *MockSensors) Create(sensor message.NewSensor) (string, error) {
+	ret := _m.ctrl.Call(_m, "Create", sensor)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockSensorsRecorder) Create(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Create", arg0)
+}
+
+func (_m *MockSensors) CreateContext(ctx context.Context, sensor message.NewSensor) (string, error) {
+	ret := _m.ctrl.Call(_m, "CreateContext", ctx, sensor)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockSensorsRecorder) CreateContext(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "CreateContext", arg0, arg1)
+}
+
+func (_m *MockSensors) Rename(id string, name string) error {
+	ret := _m.ctrl.Call(_m, "Rename", id, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockSensorsRecorder) Rename(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Rename", arg0, arg1)
+}
+
+func (_m *MockSensors) RenameContext(ctx context.Context, id string, name string) error {
+	ret := _m.ctrl.Call(_m, "RenameContext", ctx, id, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockSensorsRecorder) RenameContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "RenameContext", arg0, arg1, arg2)
+}
+
+func (_m *MockSensors) SetFlag(id string, flag bool) error {
+	ret := _m.ctrl.Call(_m, "SetFlag", id, flag)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockSensorsRecorder) SetFlag(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetFlag", arg0, arg1)
+}
+
+func (_m *MockSensors) SetFlagContext(ctx context.Context, id string, flag bool) error {
+	ret := _m.ctrl.Call(_m, "SetFlagContext", ctx, id, flag)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockSensorsRecorder) SetFlagContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetFlagContext", arg0, arg1, arg2)
+}
+
+func (_m *MockSensors) SetStatus(id string, status int) error {
+	ret := _m.ctrl.Call(_m, "SetStatus", id, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockSensorsRecorder) SetStatus(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetStatus", arg0, arg1)
+}
+
+func (_m *MockSensors) SetStatusContext(ctx context.Context, id string, status int) error {
+	ret := _m.ctrl.Call(_m, "SetStatusContext", ctx, id, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockSensorsRecorder) SetStatusContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetStatusContext", arg0, arg1, arg2)
+}
+
+func (_m *MockSensors) Delete(id string) error {
+	ret := _m.ctrl.Call(_m, "Delete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockSensorsRecorder) Delete(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Delete", arg0)
+}
+
+func (_m *MockSensors) DeleteContext(ctx context.Context, id string) error {
+	ret := _m.ctrl.Call(_m, "DeleteContext", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockSensorsRecorder) DeleteContext(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "DeleteContext", arg0, arg1)
+}
+
+// Mock of Rules interface
+type MockRules struct {
+	ctrl     *gomock.Controller
+	recorder *_MockRulesRecorder
+}
+
+// Recorder for MockRules (not exported)
+type _MockRulesRecorder struct {
+	mock *MockRules
+}
+
+func NewMockRules(ctrl *gomock.Controller) *MockRules {
+	mock := &MockRules{ctrl: ctrl}
+	mock.recorder = &_MockRulesRecorder{mock}
+	return mock
+}
+
+func (_m *MockRules) EXPECT() *_MockRulesRecorder {
+	return _m.recorder
+}
+
+func (_m *MockRules) GetAll() (map[string]message.Rule, error) {
+	ret := _m.ctrl.Call(_m, "GetAll")
+	ret0, _ := ret[0].(map[string]message.Rule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockRulesRecorder) GetAll() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetAll")
+}
+
+func (_m *MockRules) GetAllContext(ctx context.Context) (map[string]message.Rule, error) {
+	ret := _m.ctrl.Call(_m, "GetAllContext", ctx)
+	ret0, _ := ret[0].(map[string]message.Rule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockRulesRecorder) GetAllContext(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetAllContext", arg0)
+}
+
+func (_m *MockRules) Get(id string) (*message.Rule, error) {
+	ret := _m.ctrl.Call(_m, "Get", id)
+	ret0, _ := ret[0].(*message.Rule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockRulesRecorder) Get(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Get", arg0)
+}
+
+func (_m *MockRules) GetContext(ctx context.Context, id string) (*message.Rule, error) {
+	ret := _m.ctrl.Call(_m, "GetContext", ctx, id)
+	ret0, _ := ret[0].(*message.Rule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockRulesRecorder) GetContext(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetContext", arg0, arg1)
+}
+
+func (_m *MockRules) Create(rule message.NewRule) (string, error) {
+	ret := _m.ctrl.Call(_m, "Create", rule)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockRulesRecorder) Create(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Create", arg0)
+}
+
+func (_m *MockRules) CreateContext(ctx context.Context, rule message.NewRule) (string, error) {
+	ret := _m.ctrl.Call(_m, "CreateContext", ctx, rule)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockRulesRecorder) CreateContext(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "CreateContext", arg0, arg1)
+}
+
+func (_m *MockRules) Update(id string, attributes message.RuleAttributes) error {
+	ret := _m.ctrl.Call(_m, "Update", id, attributes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockRulesRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Update", arg0, arg1)
+}
+
+func (_m *MockRules) UpdateContext(ctx context.Context, id string, attributes message.RuleAttributes) error {
+	ret := _m.ctrl.Call(_m, "UpdateContext", ctx, id, attributes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockRulesRecorder) UpdateContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "UpdateContext", arg0, arg1, arg2)
+}
+
+func (_m *MockRules) Delete(id string) error {
+	ret := _m.ctrl.Call(_m, "Delete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockRulesRecorder) Delete(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Delete", arg0)
+}
+
+func (_m *MockRules) DeleteContext(ctx context.Context, id string) error {
+	ret := _m.ctrl.Call(_m, "DeleteContext", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockRulesRecorder) DeleteContext(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "DeleteContext", arg0, arg1)
+}
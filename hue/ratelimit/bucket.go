@@ -0,0 +1,195 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned for commands that were abandoned because the client was closed.
+var ErrClosed = errors.New("Rate limiter closed")
+
+// waiter represents a command waiting for a token.
+type waiter struct {
+	// ready receives nil once the command may be sent, or ErrClosed. It is buffered so the bucket never blocks.
+	ready chan error
+}
+
+// bucket represents a token bucket that releases waiting commands in priority order.
+type bucket struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    float64
+	tokens   float64
+	last     time.Time
+	queues   [High + 1][]*waiter
+	closed   bool
+	// wake is signaled when a command starts waiting.
+	wake chan struct{}
+	// done is closed when the bucket is closed.
+	done chan struct{}
+}
+
+// newBucket returns a bucket that allows rate commands per second and starts releasing waiting commands.
+func newBucket(rate float64, burst int) *bucket {
+	b := &bucket{
+		interval: time.Duration(float64(time.Second) / rate),
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		last:     time.Now(),
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// wait blocks until the command may be sent or ctx is done.
+func (b *bucket) wait(ctx context.Context, priority Priority) error {
+	w := &waiter{ready: make(chan error, 1)}
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrClosed
+	}
+	b.queues[priority] = append(b.queues[priority], w)
+	b.mu.Unlock()
+
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+
+	select {
+	case err := <-w.ready:
+		return err
+	case <-ctx.Done():
+		b.abandon(w)
+		return ctx.Err()
+	}
+}
+
+// abandon removes a waiter whose command gave up. If run released it at the same moment, the token it was given is
+// returned to the bucket, since the command is not sent.
+func (b *bucket) abandon(w *waiter) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.remove(w) {
+		return
+	}
+	// The waiter is released while b.mu is held, so anything it was sent is already buffered.
+	select {
+	case err := <-w.ready:
+		if err == nil {
+			b.tokens++
+			if b.tokens > b.burst {
+				b.tokens = b.burst
+			}
+			select {
+			case b.wake <- struct{}{}:
+			default:
+			}
+		}
+	default:
+	}
+}
+
+// run releases waiting commands as tokens become available until the bucket is closed.
+func (b *bucket) run() {
+	for {
+		b.mu.Lock()
+		b.refill(time.Now())
+		for b.tokens >= 1 {
+			w := b.pop()
+			if w == nil {
+				break
+			}
+			b.tokens--
+			w.ready <- nil
+		}
+		delay := time.Duration(-1)
+		if b.pending() > 0 {
+			delay = time.Duration((1 - b.tokens) * float64(b.interval))
+		}
+		b.mu.Unlock()
+
+		var next <-chan time.Time
+		if delay >= 0 {
+			next = time.After(delay)
+		}
+		select {
+		case <-next:
+		case <-b.wake:
+		case <-b.done:
+			return
+		}
+	}
+}
+
+// refill adds the tokens earned since the last refill. The caller must hold b.mu.
+func (b *bucket) refill(now time.Time) {
+	b.tokens += float64(now.Sub(b.last)) / float64(b.interval)
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+}
+
+// pop removes and returns the first waiter of the highest priority, or nil if none are waiting. The caller must hold
+// b.mu.
+func (b *bucket) pop() *waiter {
+	for priority := High; priority >= Normal; priority-- {
+		if queue := b.queues[priority]; len(queue) > 0 {
+			b.queues[priority] = queue[1:]
+			return queue[0]
+		}
+	}
+	return nil
+}
+
+// remove removes a waiter that gave up and returns whether it was still waiting. The caller must hold b.mu.
+func (b *bucket) remove(w *waiter) (removed bool) {
+	for priority, queue := range b.queues {
+		for i, queued := range queue {
+			if queued == w {
+				b.queues[priority] = append(queue[:i:i], queue[i+1:]...)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pending returns the number of waiting commands. The caller must hold b.mu.
+func (b *bucket) pending() int {
+	n := 0
+	for _, queue := range b.queues {
+		n += len(queue)
+	}
+	return n
+}
+
+// depth returns the number of waiting commands.
+func (b *bucket) depth() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.pending()
+}
+
+// close abandons the waiting commands and stops releasing commands.
+func (b *bucket) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for priority, queue := range b.queues {
+		for _, w := range queue {
+			w.ready <- ErrClosed
+		}
+		b.queues[priority] = nil
+	}
+	close(b.done)
+}
@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAbandonRefundsGrantedToken(t *testing.T) {
+	b := newBucket(1, 1)
+	defer b.close()
+
+	// The waiter is released at the same moment its context is done.
+	w := &waiter{ready: make(chan error, 1)}
+	b.mu.Lock()
+	b.refill(time.Now())
+	before := b.tokens
+	b.queues[Normal] = append(b.queues[Normal], w)
+	b.pop()
+	b.tokens--
+	w.ready <- nil
+	b.mu.Unlock()
+
+	b.abandon(w)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < before {
+		t.Errorf("tokens = %v after abandoning a released command, want the token refunded to %v", b.tokens, before)
+	}
+}
+
+func TestHigherPriorityGoesFirst(t *testing.T) {
+	// A token is released every 50ms, so the commands queue up behind the first.
+	b := newBucket(20, 1)
+	defer b.close()
+	if err := b.wait(context.Background(), Normal); err != nil {
+		t.Fatal(err)
+	}
+
+	released := make(chan string, 4)
+	// start queues a command and waits until queued commands are waiting.
+	start := func(name string, priority Priority, queued int) {
+		go func() {
+			if err := b.wait(context.Background(), priority); err != nil {
+				released <- err.Error()
+				return
+			}
+			released <- name
+		}()
+		for deadline := time.Now().Add(time.Second); b.depth() < queued && time.Now().Before(deadline); {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	start("normal 1", Normal, 1)
+	start("normal 2", Normal, 2)
+	start("high", High, 3)
+
+	want := []string{"high", "normal 1", "normal 2"}
+	for i, name := range want {
+		select {
+		case got := <-released:
+			if got != name {
+				t.Errorf("release %v = %v, want %v", i, got, name)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("release %v did not happen", i)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// Package ratelimit is a library for keeping the commands sent to a Philips Hue bridge within the rates it can handle.
+// Philips recommends sending at most 10 light commands and 1 group command per second; commands sent faster than that
+// are dropped by the bridge.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue"
+	"github.com/drombosky/disco-dance-party/hue/logging"
+)
+
+// Priority represents the lane a command waits in. Commands with a higher priority are sent before any waiting
+// commands with a lower priority.
+type Priority int
+
+const (
+	// Normal is the priority of commands that can wait, such as animation frames.
+	Normal Priority = iota
+	// High is the priority of commands that should be sent as soon as possible, such as interactive commands.
+	High
+)
+
+// priorityKey is the context key for the priority of a command.
+type priorityKey struct{}
+
+// WithPriority returns a copy of ctx that sends commands with the given priority.
+func WithPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+// priorityOf returns the priority carried by ctx, or Normal if it has none.
+func priorityOf(ctx context.Context) Priority {
+	if priority, ok := ctx.Value(priorityKey{}).(Priority); ok && priority >= Normal && priority <= High {
+		return priority
+	}
+	return Normal
+}
+
+var (
+	// lightStateRegexp matches the addresses of light state commands.
+	lightStateRegexp = regexp.MustCompile(`^/api/[^/]+/lights/[^/]+/state$`)
+	// groupActionRegexp matches the addresses of group action commands.
+	groupActionRegexp = regexp.MustCompile(`^/api/[^/]+/groups/[^/]+/action$`)
+)
+
+// Config represents the rates a Client limits commands to.
+type Config struct {
+	// LightRate is the number of light state commands sent per second. Defaults to 10.
+	LightRate float64
+	// GroupRate is the number of group action commands sent per second. Defaults to 1.
+	GroupRate float64
+	// Burst is the number of commands of each kind that may be sent at once after a quiet period. Defaults to 1.
+	Burst int
+	// Logger is logged to. Defaults to logging nothing.
+	Logger logging.Logger
+}
+
+// Client represents a hue.Client that limits the rate of light state and group action commands. Each kind of command
+// has its own token bucket; other commands are not limited.
+type Client struct {
+	client hue.Client
+	lights *bucket
+	groups *bucket
+	logger logging.Logger
+}
+
+// NewClient takes a hue.Client and returns a client that limits the rate of commands sent through it. Close must be
+// called once the client is no longer used.
+func NewClient(hueClient hue.Client, config Config) (client *Client, err error) {
+	if config.LightRate <= 0 {
+		config.LightRate = 10
+	}
+	if config.GroupRate <= 0 {
+		config.GroupRate = 1
+	}
+	if config.Burst <= 0 {
+		config.Burst = 1
+	}
+	return &Client{
+		client: hueClient,
+		lights: newBucket(config.LightRate, config.Burst),
+		groups: newBucket(config.GroupRate, config.Burst),
+		logger: logging.OrNop(config.Logger),
+	}, nil
+}
+
+// Do sends a command to the Philips Hue bridge once the rate limit allows it.
+func (c *Client) Do(method string, address string, message []byte, resp interface{}) (err error) {
+	return c.DoContext(context.Background(), method, address, message, resp)
+}
+
+// DoContext sends a command to the Philips Hue bridge once the rate limit allows it. The command waits with the
+// priority set by WithPriority, and is abandoned if ctx is done before it is sent.
+func (c *Client) DoContext(ctx context.Context, method string, address string, message []byte,
+	resp interface{}) (err error) {
+	var b *bucket
+	switch {
+	case lightStateRegexp.MatchString(address):
+		b = c.lights
+	case groupActionRegexp.MatchString(address):
+		b = c.groups
+	}
+	if b != nil {
+		start := time.Now()
+		if err = b.wait(ctx, priorityOf(ctx)); err != nil {
+			return err
+		}
+		waited := time.Since(start)
+		c.logger.Debug(fmt.Sprintf("Waited %v to send %v %v", waited, method, address), logging.Fields{
+			logging.Package:   "github.com/drombosky/disco-dance-party/hue/ratelimit",
+			logging.Operation: "(c *Client) DoContext",
+			logging.Method:    method,
+			logging.Address:   address,
+			logging.Latency:   waited,
+		})
+	}
+	return c.client.DoContext(ctx, method, address, message, resp)
+}
+
+// QueueDepth returns the number of commands waiting to be sent.
+func (c *Client) QueueDepth() int {
+	return c.lights.depth() + c.groups.depth()
+}
+
+// Close stops the client. Commands waiting to be sent are abandoned with ErrClosed.
+func (c *Client) Close() error {
+	c.lights.close()
+	c.groups.close()
+	return nil
+}
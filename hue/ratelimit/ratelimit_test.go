@@ -0,0 +1,131 @@
+package ratelimit_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue/middleware"
+	"github.com/drombosky/disco-dance-party/hue/ratelimit"
+)
+
+// These tests send commands from many goroutines at once and are meant to be run with go test -race.
+
+// counter returns a hue.Client that counts the commands sent through it.
+func counter(sent *int32) middleware.RoundTripperFunc {
+	return middleware.RoundTripperFunc(func(req *middleware.Request) *middleware.Response {
+		atomic.AddInt32(sent, 1)
+		return &middleware.Response{}
+	})
+}
+
+func TestConcurrentDoContext(t *testing.T) {
+	var sent int32
+	client, err := ratelimit.NewClient(counter(&sent), ratelimit.Config{LightRate: 500, GroupRate: 500})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var wg sync.WaitGroup
+	var abandoned int32
+	for i := 0; i < 60; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx := context.Background()
+			address := "/api/user/lights/1/state"
+			switch i % 3 {
+			case 1:
+				ctx = ratelimit.WithPriority(ctx, ratelimit.High)
+				address = "/api/user/groups/1/action"
+			case 2:
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, time.Millisecond)
+				defer cancel()
+			}
+			err := client.DoContext(ctx, "PUT", address, []byte(`{"on":true}`), nil)
+			switch {
+			case err == context.DeadlineExceeded:
+				atomic.AddInt32(&abandoned, 1)
+			case err != nil:
+				t.Errorf("DoContext() = %v", err)
+			}
+			client.QueueDepth()
+		}(i)
+	}
+	wg.Wait()
+	if int(sent+abandoned) != 60 {
+		t.Errorf("sent %v and abandoned %v commands, want 60 in all", sent, abandoned)
+	}
+	if depth := client.QueueDepth(); depth != 0 {
+		t.Errorf("QueueDepth() = %v, want 0", depth)
+	}
+}
+
+func TestCloseAbandonsWaitingCommands(t *testing.T) {
+	var sent int32
+	client, err := ratelimit.NewClient(counter(&sent), ratelimit.Config{LightRate: 0.1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		go func() {
+			errs <- client.Do("PUT", "/api/user/lights/1/state", []byte(`{"on":true}`), nil)
+		}()
+	}
+	// The first command uses the burst; the others wait for a token.
+	for deadline := time.Now().Add(5 * time.Second); client.QueueDepth() != 4; {
+		if time.Now().After(deadline) {
+			t.Fatalf("QueueDepth() = %v, want 4", client.QueueDepth())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	client.Close()
+
+	closed := 0
+	for i := 0; i < 5; i++ {
+		if err := <-errs; err == ratelimit.ErrClosed {
+			closed++
+		}
+	}
+	if closed != 4 || atomic.LoadInt32(&sent) != 1 {
+		t.Errorf("%v commands abandoned and %v sent, want 4 and 1", closed, sent)
+	}
+}
+
+func TestSustainedRate(t *testing.T) {
+	var sent int32
+	client, err := ratelimit.NewClient(counter(&sent), ratelimit.Config{LightRate: 50, GroupRate: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// The first command of each kind uses the burst; each one after it waits for the next token.
+	for _, tc := range []struct {
+		address  string
+		commands int
+		want     time.Duration
+	}{
+		{"/api/user/lights/1/state", 26, 25 * 20 * time.Millisecond},
+		{"/api/user/groups/1/action", 6, 5 * 100 * time.Millisecond},
+	} {
+		start := time.Now()
+		for i := 0; i < tc.commands; i++ {
+			if err := client.Do("PUT", tc.address, []byte(`{"on":true}`), nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if elapsed := time.Since(start); elapsed < tc.want*9/10 || elapsed > tc.want*2 {
+			t.Errorf("%v commands to %v took %v, want about %v", tc.commands, tc.address, elapsed, tc.want)
+		}
+	}
+	if sent != 32 {
+		t.Errorf("sent %v commands, want 32", sent)
+	}
+}
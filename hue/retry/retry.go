@@ -0,0 +1,168 @@
+// Package retry is a library for retrying commands sent to a Philips Hue bridge that failed for transient reasons,
+// such as network hiccups or the bridge reporting an internal error. Commands that are not idempotent, such as POSTs
+// and PUTs of brightness or color increments, are only retried if they were never sent.
+package retry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue"
+	"github.com/drombosky/disco-dance-party/hue/client"
+	"github.com/drombosky/disco-dance-party/hue/logging"
+	"github.com/drombosky/disco-dance-party/hue/message"
+)
+
+// Policy represents when and how often failed commands are retried.
+type Policy struct {
+	// MaxAttempts is the maximum number of times a command is sent, including the first. Defaults to 3.
+	MaxAttempts int
+	// InitialBackoff is the time to wait before the first retry. Defaults to 100ms.
+	InitialBackoff time.Duration
+	// MaxBackoff is the longest time to wait between retries. Defaults to 2s.
+	MaxBackoff time.Duration
+	// Multiplier is the factor the backoff grows by after each retry. Defaults to 2.
+	Multiplier float64
+	// Jitter is the fraction of the backoff that is randomized, between 0 and 1. Defaults to 0.2.
+	Jitter float64
+	// NoJitter disables the randomization of the backoff, since a zero Jitter means the default.
+	NoJitter bool
+	// Retriable reports whether a command that failed with err may be retried. Defaults to Retriable.
+	Retriable func(err error) bool
+	// Logger is logged to. Defaults to logging nothing.
+	Logger logging.Logger
+}
+
+// Retriable reports whether err is transient: a network error, a 5xx response from the bridge, or Hue error 901.
+func Retriable(err error) bool {
+	switch e := err.(type) {
+	case net.Error:
+		return true
+	case *client.ServiceError:
+		return e.StatusCode >= 500
+	case *message.APIError:
+		return e.Type == message.ErrorTypeInternalError
+	}
+	return false
+}
+
+// Idempotent reports whether a command sent with method and body has the same effect when sent more than once. POST
+// creates a new resource every time and is never idempotent, and neither is a body holding an increment such as
+// bri_inc, which is applied again by every copy of the command.
+func Idempotent(method string, body []byte) bool {
+	switch method {
+	case "GET", "HEAD", "DELETE":
+		return true
+	case "PUT":
+		if len(bytes.TrimSpace(body)) == 0 {
+			return true
+		}
+		fields := map[string]json.RawMessage{}
+		if err := json.Unmarshal(body, &fields); err != nil {
+			// A body that is not a JSON object cannot hold an increment.
+			return true
+		}
+		for key := range fields {
+			if strings.HasSuffix(key, "_inc") {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// NotSent reports whether a command failed before it was sent to the bridge, because a connection could not be
+// opened. Such a command may be retried even if it is not idempotent.
+func NotSent(err error) bool {
+	if e, ok := err.(*url.Error); ok {
+		err = e.Err
+	}
+	e, ok := err.(*net.OpError)
+	return ok && e.Op == "dial"
+}
+
+// Client represents a hue.Client that retries commands that failed for transient reasons, if they are idempotent or
+// were never sent.
+type Client struct {
+	client hue.Client
+	policy Policy
+}
+
+// NewClient takes a hue.Client and returns a client that retries failed commands according to policy.
+func NewClient(hueClient hue.Client, policy Policy) (client *Client, err error) {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 3
+	}
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = 100 * time.Millisecond
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = 2 * time.Second
+	}
+	if policy.Multiplier < 1 {
+		policy.Multiplier = 2
+	}
+	switch {
+	case policy.NoJitter:
+		policy.Jitter = 0
+	case policy.Jitter <= 0 || policy.Jitter > 1:
+		policy.Jitter = 0.2
+	}
+	if policy.Retriable == nil {
+		policy.Retriable = Retriable
+	}
+	policy.Logger = logging.OrNop(policy.Logger)
+	return &Client{client: hueClient, policy: policy}, nil
+}
+
+// Do sends a command to the Philips Hue bridge, retrying it if it fails for a transient reason.
+func (c *Client) Do(method string, address string, message []byte, resp interface{}) (err error) {
+	return c.DoContext(context.Background(), method, address, message, resp)
+}
+
+// DoContext is like Do, but the command and any retries are canceled if ctx is done before they complete.
+func (c *Client) DoContext(ctx context.Context, method string, address string, message []byte,
+	resp interface{}) (err error) {
+	for attempt := 1; ; attempt++ {
+		err = c.client.DoContext(ctx, method, address, message, resp)
+		if err == nil || ctx.Err() != nil || !c.policy.Retriable(err) ||
+			!(Idempotent(method, message) || NotSent(err)) || attempt >= c.policy.MaxAttempts {
+			return err
+		}
+
+		backoff := c.backoff(attempt)
+		c.policy.Logger.Debug(fmt.Sprintf("Retrying %v %v in %v: %v", method, address, backoff, err), logging.Fields{
+			logging.Package:   "github.com/drombosky/disco-dance-party/hue/retry",
+			logging.Operation: "(c *Client) DoContext",
+			logging.Address:   address,
+			logging.Method:    method,
+			logging.Error:     err,
+			logging.Attempt:   attempt,
+		})
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// backoff returns the randomized time to wait after the given attempt.
+func (c *Client) backoff(attempt int) time.Duration {
+	backoff := float64(c.policy.InitialBackoff) * math.Pow(c.policy.Multiplier, float64(attempt-1))
+	if backoff > float64(c.policy.MaxBackoff) {
+		backoff = float64(c.policy.MaxBackoff)
+	}
+	backoff *= 1 - c.policy.Jitter + 2*c.policy.Jitter*rand.Float64()
+	return time.Duration(backoff)
+}
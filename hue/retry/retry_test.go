@@ -0,0 +1,105 @@
+package retry_test
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue/middleware"
+	"github.com/drombosky/disco-dance-party/hue/retry"
+)
+
+func TestIdempotent(t *testing.T) {
+	commands := []struct {
+		method string
+		body   string
+		want   bool
+	}{
+		{"GET", "", true},
+		{"DELETE", "", true},
+		{"PUT", "", true},
+		{"PUT", `{"on":true,"bri":254,"xy":[0.3,0.4]}`, true},
+		{"PUT", `{"name":"Kitchen"}`, true},
+		{"PUT", `{"bri_inc":10}`, false},
+		{"PUT", `{"on":true,"hue_inc":1000}`, false},
+		{"PUT", `{"sat_inc":-10}`, false},
+		{"PUT", `{"ct_inc":20}`, false},
+		{"PUT", `{"xy_inc":[0.1,0]}`, false},
+		{"PUT", `[1,2]`, true},
+		{"POST", `{"name":"Kitchen"}`, false},
+		{"POST", "", false},
+	}
+	for _, c := range commands {
+		if got := retry.Idempotent(c.method, []byte(c.body)); got != c.want {
+			t.Errorf("Idempotent(%v, %v) = %v, want %v", c.method, c.body, got, c.want)
+		}
+	}
+}
+
+// dialError is the error returned by client.Client when the bridge cannot be connected to.
+var dialError = &url.Error{Op: "Put", URL: "http://10.0.0.2/api", Err: &net.OpError{Op: "dial", Net: "tcp",
+	Err: errors.New("connection refused")}}
+
+// readError is the error returned by client.Client when the bridge was sent the request but did not answer.
+var readError = &url.Error{Op: "Put", URL: "http://10.0.0.2/api", Err: &net.OpError{Op: "read", Net: "tcp",
+	Err: errors.New("connection reset by peer")}}
+
+func TestDoContextRetries(t *testing.T) {
+	commands := []struct {
+		name     string
+		method   string
+		body     string
+		err      error
+		attempts int
+	}{
+		{"idempotent after a read error", "PUT", `{"on":true}`, readError, 3},
+		{"increment after a dial error", "PUT", `{"bri_inc":10}`, dialError, 3},
+		{"increment after a read error", "PUT", `{"bri_inc":10}`, readError, 1},
+		{"POST after a dial error", "POST", `{"name":"Kitchen"}`, dialError, 3},
+		{"POST after a read error", "POST", `{"name":"Kitchen"}`, readError, 1},
+		{"permanent error", "PUT", `{"on":true}`, errors.New("invalid"), 1},
+	}
+	for _, c := range commands {
+		attempts := 0
+		next := middleware.RoundTripperFunc(func(req *middleware.Request) *middleware.Response {
+			attempts++
+			return &middleware.Response{Err: c.err}
+		})
+		client, err := retry.NewClient(next, retry.Policy{InitialBackoff: time.Millisecond})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = client.Do(c.method, "/api/<username>/lights/1/state", []byte(c.body), nil); err != c.err {
+			t.Errorf("%v: Do() = %v, want %v", c.name, err, c.err)
+		}
+		if attempts != c.attempts {
+			t.Errorf("%v: sent %v times, want %v", c.name, attempts, c.attempts)
+		}
+	}
+}
+
+func TestNoJitter(t *testing.T) {
+	sent := []time.Time{}
+	next := middleware.RoundTripperFunc(func(req *middleware.Request) *middleware.Response {
+		sent = append(sent, time.Now())
+		return &middleware.Response{Err: readError}
+	})
+	policy := retry.Policy{MaxAttempts: 6, InitialBackoff: 20 * time.Millisecond, MaxBackoff: 20 * time.Millisecond,
+		Jitter: 0.9, NoJitter: true}
+	client, err := retry.NewClient(next, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Do("GET", "/api/<username>/lights", nil, nil)
+	if len(sent) != 6 {
+		t.Fatalf("sent %v times, want 6", len(sent))
+	}
+	// With 90% jitter about half of the backoffs would be shorter than 20ms.
+	for i := 1; i < len(sent); i++ {
+		if backoff := sent[i].Sub(sent[i-1]); backoff < 20*time.Millisecond {
+			t.Errorf("backoff %v = %v, want 20ms", i, backoff)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package rules
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue/message"
+	"github.com/drombosky/disco-dance-party/hue/schedules"
+)
+
+// LocalTime is the address of the local time of the bridge, the attribute compared by In and NotIn.
+const LocalTime = "/config/localtime"
+
+// SensorState returns the address of an attribute of the state of a sensor, e.g. SensorState("2", "buttonevent").
+func SensorState(id, attribute string) string {
+	return fmt.Sprintf("/sensors/%v/state/%v", id, attribute)
+}
+
+// GroupState returns the address of an attribute of the state of a group, "any_on" or "all_on".
+func GroupState(id, attribute string) string {
+	return fmt.Sprintf("/groups/%v/state/%v", id, attribute)
+}
+
+// LightState returns the address of an attribute of the state of a light, e.g. LightState("1", "on").
+func LightState(id, attribute string) string {
+	return fmt.Sprintf("/lights/%v/state/%v", id, attribute)
+}
+
+// Eq returns a condition that is true while the attribute at address equals value, e.g. a bool or an int.
+func Eq(address string, value interface{}) message.Condition {
+	return message.Condition{Address: address, Operator: message.OperatorEq, Value: fmt.Sprint(value)}
+}
+
+// Gt returns a condition that is true while the attribute at address is greater than value.
+func Gt(address string, value int) message.Condition {
+	return message.Condition{Address: address, Operator: message.OperatorGt, Value: fmt.Sprint(value)}
+}
+
+// Lt returns a condition that is true while the attribute at address is less than value.
+func Lt(address string, value int) message.Condition {
+	return message.Condition{Address: address, Operator: message.OperatorLt, Value: fmt.Sprint(value)}
+}
+
+// Dx returns a condition that is true when the attribute at address changes, e.g. each time a button is pressed.
+func Dx(address string) message.Condition {
+	return message.Condition{Address: address, Operator: message.OperatorDx}
+}
+
+// Ddx returns a condition that is true when the attribute at address changed d ago and has not changed since.
+func Ddx(address string, d time.Duration) message.Condition {
+	return message.Condition{Address: address, Operator: message.OperatorDdx, Value: schedules.After(d).String()}
+}
+
+// Stable returns a condition that is true while the attribute at address has not changed for d.
+func Stable(address string, d time.Duration) message.Condition {
+	return message.Condition{Address: address, Operator: message.OperatorStable, Value: schedules.After(d).String()}
+}
+
+// NotStable returns a condition that is true while the attribute at address has changed within d.
+func NotStable(address string, d time.Duration) message.Condition {
+	return message.Condition{Address: address, Operator: message.OperatorNotStable, Value: schedules.After(d).String()}
+}
+
+// In returns a condition that is true while the local time of the bridge is between start and end, given as the time
+// since midnight. The interval may wrap past midnight, e.g. In(20*time.Hour, 8*time.Hour). Check rejects a start or end
+// of 24 hours or more.
+func In(start, end time.Duration) message.Condition {
+	return message.Condition{Address: LocalTime, Operator: message.OperatorIn, Value: interval(start, end)}
+}
+
+// NotIn returns a condition that is true while the local time of the bridge is not between start and end.
+func NotIn(start, end time.Duration) message.Condition {
+	return message.Condition{Address: LocalTime, Operator: message.OperatorNotIn, Value: interval(start, end)}
+}
+
+// interval formats a time interval as Thh:mm:ss/Thh:mm:ss.
+func interval(start, end time.Duration) string {
+	t := func(d time.Duration) string {
+		d = d / time.Second
+		return fmt.Sprintf("T%02d:%02d:%02d", d/3600, d/60%60, d%60)
+	}
+	return t(start) + "/" + t(end)
+}
+
+// Put returns an action that sends body to the resource at address, relative to the user, e.g. /lights/1/state.
+func Put(address string, body interface{}) message.Command {
+	return message.Command{Address: address, Method: "PUT", Body: body}
+}
+
+// SetLight returns an action that sets the state of a light.
+func SetLight(id string, state message.LightAction) message.Command {
+	return Put(fmt.Sprintf("/lights/%v/state", id), state)
+}
+
+// SetGroup returns an action that sets the state of every light in a group.
+func SetGroup(id string, state message.LightAction) message.Command {
+	return Put(fmt.Sprintf("/groups/%v/action", id), state)
+}
+
+// RecallScene returns an action that recalls a scene through the action of a group, "0" for every light.
+func RecallScene(group, scene string) message.Command {
+	return Put(fmt.Sprintf("/groups/%v/action", group), map[string]interface{}{"scene": scene})
+}
+
+// SetSceneLight returns an action that changes the state a scene stores for one of its lights.
+func SetSceneLight(scene, light string, state message.SceneLightState) message.Command {
+	return Put(fmt.Sprintf("/scenes/%v/lightstates/%v", scene, light), state)
+}
+
+// SetFlag returns an action that sets the flag of a CLIPGenericFlag sensor.
+func SetFlag(id string, flag bool) message.Command {
+	return Put(fmt.Sprintf("/sensors/%v/state", id), map[string]interface{}{"flag": flag})
+}
+
+// SetStatus returns an action that sets the status of a CLIPGenericStatus sensor.
+func SetStatus(id string, status int) message.Command {
+	return Put(fmt.Sprintf("/sensors/%v/state", id), map[string]interface{}{"status": status})
+}
+
+// Builder builds a rule from its conditions and actions, e.g.
+//
+//	rule, err := rules.New("Dimmer on").
+//		When(rules.Eq(rules.SensorState("2", "buttonevent"), 1002), rules.Dx(rules.SensorState("2", "lastupdated"))).
+//		Then(rules.RecallScene("1", "party")).
+//		Build()
+type Builder struct {
+	rule message.NewRule
+}
+
+// New returns a builder of a rule with the given name.
+func New(name string) *Builder {
+	return &Builder{rule: message.NewRule{Name: name, Conditions: []message.Condition{}, Actions: []message.Command{}}}
+}
+
+// When adds conditions to the rule. The rule runs its actions when all of its conditions become true.
+func (b *Builder) When(conditions ...message.Condition) *Builder {
+	b.rule.Conditions = append(b.rule.Conditions, conditions...)
+	return b
+}
+
+// Then adds actions to the rule.
+func (b *Builder) Then(actions ...message.Command) *Builder {
+	b.rule.Actions = append(b.rule.Actions, actions...)
+	return b
+}
+
+// Disabled creates the rule disabled.
+func (b *Builder) Disabled() *Builder {
+	b.rule.Status = message.StatusDisabled
+	return b
+}
+
+// Build returns the rule once it has been checked against the limits of the bridge and the grammar of conditions and
+// actions. Addresses are checked against the resources of the bridge when the rule is created by Client.Create.
+func (b *Builder) Build() (rule message.NewRule, err error) {
+	if err = Check(b.rule); err != nil {
+		return rule, err
+	}
+	return b.rule, nil
+}
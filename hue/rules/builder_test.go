@@ -0,0 +1,30 @@
+package rules
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/drombosky/disco-dance-party/hue/message"
+)
+
+func TestActionsOnlySendSetFields(t *testing.T) {
+	on, dim, bri := false, -30, 200
+	for _, tc := range []struct {
+		action message.Command
+		want   string
+	}{
+		{SetLight("1", message.LightAction{BriInc: &dim}),
+			`{"address":"/lights/1/state","method":"PUT","body":{"bri_inc":-30}}`},
+		{SetGroup("0", message.LightAction{On: &on}),
+			`{"address":"/groups/0/action","method":"PUT","body":{"on":false}}`},
+		{SetGroup("2", message.LightAction{Xy: &[2]float64{0.3, 0.4}, Alert: "select"}),
+			`{"address":"/groups/2/action","method":"PUT","body":{"xy":[0.3,0.4],"alert":"select"}}`},
+		{SetSceneLight("abc", "3", message.SceneLightState{Bri: &bri}),
+			`{"address":"/scenes/abc/lightstates/3","method":"PUT","body":{"bri":200}}`},
+	} {
+		encoded, err := json.Marshal(tc.action)
+		if err != nil || string(encoded) != tc.want {
+			t.Errorf("json.Marshal(%+v) = %s, %v, want %v", tc.action, encoded, err, tc.want)
+		}
+	}
+}
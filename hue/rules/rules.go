@@ -0,0 +1,166 @@
+// Package rules is a library for the rules of a Philips Hue bridge, which send commands to the bridge itself when all
+// of their conditions become true, e.g. recall a scene when a button of a dimmer switch is pressed. Rules are built
+// with a Builder and checked against the resources of the bridge before they are created, e.g.
+//
+//	rule, err := rules.New("Motion at night").
+//		When(rules.Eq(rules.SensorState("5", "presence"), true), rules.In(22*time.Hour, 6*time.Hour)).
+//		Then(rules.RecallScene("1", "nightlight")).
+//		Build()
+//	if err != nil {
+//		return err
+//	}
+//	id, err := client.Create(rule)
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue"
+	"github.com/drombosky/disco-dance-party/hue/internal/resource"
+	"github.com/drombosky/disco-dance-party/hue/logging"
+	"github.com/drombosky/disco-dance-party/hue/message"
+)
+
+// Client represents a client to read and update rules via the Philips Hue bridge. It is safe for concurrent use by
+// multiple goroutines if its hue.Client is.
+type Client struct {
+	client *resource.Client
+}
+
+// NewClient takes a *hue.Client and returns a client for interacting with rules.
+func NewClient(hueClient hue.Client, opts ...Option) (client *Client, err error) {
+	o := &resource.Options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &Client{client: resource.NewClient(hueClient, o.Logger, "github.com/drombosky/disco-dance-party/hue/rules",
+		logging.Rule)}, nil
+}
+
+// GetAll gets a list of all rules on the Philips Hue bridge.
+func (c *Client) GetAll() (resp map[string]message.Rule, err error) {
+	return c.GetAllContext(context.Background())
+}
+
+// GetAllContext is like GetAll, but the request is canceled if ctx is done before it completes.
+func (c *Client) GetAllContext(ctx context.Context) (resp map[string]message.Rule, err error) {
+	start := time.Now()
+	resp = map[string]message.Rule{}
+	err = c.client.DoContext(ctx, "GET", "/api/<username>/rules", nil, &resp)
+	c.client.Log("(c *Client) GetAllContext", "", start, err, "Get all")
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Get gets the attributes, conditions and actions of a given rule.
+func (c *Client) Get(id string) (resp *message.Rule, err error) {
+	return c.GetContext(context.Background(), id)
+}
+
+// GetContext is like Get, but the request is canceled if ctx is done before it completes.
+func (c *Client) GetContext(ctx context.Context, id string) (resp *message.Rule, err error) {
+	start := time.Now()
+	resp = &message.Rule{}
+	err = c.client.DoContext(ctx, "GET", fmt.Sprintf("/api/<username>/rules/%v", id), nil, resp)
+	c.client.Log("(c *Client) GetContext", id, start, err, "Get %v", id)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Datastore gets every resource of the Philips Hue bridge, which Create and Update validate rules against.
+func (c *Client) Datastore() (resp *message.Datastore, err error) {
+	return c.DatastoreContext(context.Background())
+}
+
+// DatastoreContext is like Datastore, but the request is canceled if ctx is done before it completes.
+func (c *Client) DatastoreContext(ctx context.Context) (resp *message.Datastore, err error) {
+	start := time.Now()
+	resp = &message.Datastore{}
+	err = c.client.DoContext(ctx, "GET", "/api/<username>", nil, resp)
+	c.client.Log("(c *Client) DatastoreContext", "", start, err, "Get datastore")
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Create creates a rule and returns its ID. The rule is first validated against the datastore of the bridge, and an
+// *InvalidRuleError, *InvalidConditionError or *InvalidActionError is returned instead of a rule the bridge would
+// reject.
+func (c *Client) Create(rule message.NewRule) (id string, err error) {
+	return c.CreateContext(context.Background(), rule)
+}
+
+// CreateContext is like Create, but the requests are canceled if ctx is done before they complete.
+func (c *Client) CreateContext(ctx context.Context, rule message.NewRule) (id string, err error) {
+	datastore, err := c.DatastoreContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	if err = Validate(rule, datastore); err != nil {
+		return "", err
+	}
+
+	return c.client.Create(ctx, "/api/<username>/rules", rule)
+}
+
+// Update changes the attributes of a rule. New conditions and actions replace those of the rule and are validated
+// against the datastore of the bridge like those of Create.
+func (c *Client) Update(id string, attributes message.RuleAttributes) (err error) {
+	return c.UpdateContext(context.Background(), id, attributes)
+}
+
+// UpdateContext is like Update, but the requests are canceled if ctx is done before they complete.
+func (c *Client) UpdateContext(ctx context.Context, id string, attributes message.RuleAttributes) (err error) {
+	if err = checkAttributes(attributes); err != nil {
+		return err
+	}
+	if len(attributes.Conditions) > 0 || len(attributes.Actions) > 0 {
+		var datastore *message.Datastore
+		if datastore, err = c.DatastoreContext(ctx); err != nil {
+			return err
+		}
+		if err = validateConditions(attributes.Conditions, datastore); err != nil {
+			return err
+		}
+		if err = validateActions(attributes.Actions, datastore); err != nil {
+			return err
+		}
+	}
+
+	body, err := json.Marshal(attributes)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err = c.client.DoContext(ctx, "PUT", fmt.Sprintf("/api/<username>/rules/%v", id), body, nil)
+	c.client.Log("(c *Client) UpdateContext", id, start, err, "Update %v to %v", id, string(body))
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Delete deletes a rule from the Philips Hue bridge.
+func (c *Client) Delete(id string) (err error) {
+	return c.DeleteContext(context.Background(), id)
+}
+
+// DeleteContext is like Delete, but the request is canceled if ctx is done before it completes.
+func (c *Client) DeleteContext(ctx context.Context, id string) (err error) {
+	start := time.Now()
+	err = c.client.DoContext(ctx, "DELETE", fmt.Sprintf("/api/<username>/rules/%v", id), nil, nil)
+	c.client.Log("(c *Client) DeleteContext", id, start, err, "Delete %v", id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,313 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/drombosky/disco-dance-party/hue/message"
+	"github.com/drombosky/disco-dance-party/hue/schedules"
+)
+
+// Limits of the bridge.
+const (
+	// MaxConditions is the number of conditions a rule may have.
+	MaxConditions = 8
+	// MaxActions is the number of actions a rule may have.
+	MaxActions = 8
+	// MaxRules is the number of rules the bridge holds.
+	MaxRules = 200
+	// MaxNameLength is the number of characters in the name of a rule.
+	MaxNameLength = 32
+)
+
+// InvalidRuleError represents an error when a rule exceeds the limits of the bridge.
+type InvalidRuleError struct {
+	Name   string
+	Reason string
+}
+
+// Error satisfies the error interface.
+func (e *InvalidRuleError) Error() string {
+	return fmt.Sprintf("Invalid rule %v: %v", e.Name, e.Reason)
+}
+
+// InvalidConditionError represents an error when a condition of a rule would be rejected by the bridge.
+type InvalidConditionError struct {
+	Condition message.Condition
+	Reason    string
+}
+
+// Error satisfies the error interface.
+func (e *InvalidConditionError) Error() string {
+	return fmt.Sprintf("Invalid condition %v %v %v: %v", e.Condition.Address, e.Condition.Operator, e.Condition.Value,
+		e.Reason)
+}
+
+// InvalidActionError represents an error when an action of a rule would be rejected by the bridge.
+type InvalidActionError struct {
+	Action message.Command
+	Reason string
+}
+
+// Error satisfies the error interface.
+func (e *InvalidActionError) Error() string {
+	return fmt.Sprintf("Invalid action %v %v: %v", e.Action.Method, e.Action.Address, e.Reason)
+}
+
+var (
+	// intervalRegexp matches the value of in and not in conditions, capturing the start and end times.
+	intervalRegexp = regexp.MustCompile(`^(?:W\d{1,3}/)?T(\d{2}:\d{2}:\d{2})/T(\d{2}:\d{2}:\d{2})$`)
+
+	// Addresses of conditions.
+	sensorAttributeRegexp = regexp.MustCompile(`^/sensors/([^/]+)/(state|config)/([^/]+)$`)
+	groupAttributeRegexp  = regexp.MustCompile(`^/groups/([^/]+)/state/(any_on|all_on)$`)
+	lightAttributeRegexp  = regexp.MustCompile(`^/lights/([^/]+)/state/([^/]+)$`)
+
+	// Addresses of actions.
+	lightStateRegexp  = regexp.MustCompile(`^/lights/([^/]+)/state$`)
+	groupActionRegexp = regexp.MustCompile(`^/groups/([^/]+)/action$`)
+	sceneRegexp       = regexp.MustCompile(`^/scenes/([^/]+)(?:/lightstates/([^/]+))?$`)
+	sensorRegexp      = regexp.MustCompile(`^/sensors/([^/]+)/(state|config)$`)
+	scheduleRegexp    = regexp.MustCompile(`^/schedules/([^/]+)$`)
+)
+
+// Check checks a rule against the limits of the bridge and the grammar of conditions and actions, without checking
+// that the resources they address exist.
+func Check(rule message.NewRule) (err error) {
+	switch {
+	case utf8.RuneCountInString(rule.Name) > MaxNameLength:
+		return &InvalidRuleError{Name: rule.Name, Reason: fmt.Sprintf("name is longer than %v characters",
+			MaxNameLength)}
+	case len(rule.Conditions) == 0:
+		return &InvalidRuleError{Name: rule.Name, Reason: "no conditions"}
+	case len(rule.Conditions) > MaxConditions:
+		return &InvalidRuleError{Name: rule.Name, Reason: fmt.Sprintf("%v conditions, at most %v are allowed",
+			len(rule.Conditions), MaxConditions)}
+	case len(rule.Actions) == 0:
+		return &InvalidRuleError{Name: rule.Name, Reason: "no actions"}
+	case len(rule.Actions) > MaxActions:
+		return &InvalidRuleError{Name: rule.Name, Reason: fmt.Sprintf("%v actions, at most %v are allowed",
+			len(rule.Actions), MaxActions)}
+	}
+	for _, condition := range rule.Conditions {
+		if err = checkCondition(condition); err != nil {
+			return err
+		}
+	}
+	for _, action := range rule.Actions {
+		if err = checkAction(action); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkAttributes checks the conditions and actions that replace those of a rule, if they are set.
+func checkAttributes(attributes message.RuleAttributes) (err error) {
+	switch {
+	case utf8.RuneCountInString(attributes.Name) > MaxNameLength:
+		return &InvalidRuleError{Name: attributes.Name, Reason: fmt.Sprintf("name is longer than %v characters",
+			MaxNameLength)}
+	case len(attributes.Conditions) > MaxConditions:
+		return &InvalidRuleError{Name: attributes.Name, Reason: fmt.Sprintf("%v conditions, at most %v are allowed",
+			len(attributes.Conditions), MaxConditions)}
+	case len(attributes.Actions) > MaxActions:
+		return &InvalidRuleError{Name: attributes.Name, Reason: fmt.Sprintf("%v actions, at most %v are allowed",
+			len(attributes.Actions), MaxActions)}
+	}
+	for _, condition := range attributes.Conditions {
+		if err = checkCondition(condition); err != nil {
+			return err
+		}
+	}
+	for _, action := range attributes.Actions {
+		if err = checkAction(action); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkCondition checks that the operator of a condition is known and its value fits the operator.
+func checkCondition(condition message.Condition) (err error) {
+	invalid := func(reason string) error {
+		return &InvalidConditionError{Condition: condition, Reason: reason}
+	}
+	timeOperator := condition.Operator == message.OperatorIn || condition.Operator == message.OperatorNotIn
+	if timeOperator != (condition.Address == LocalTime) {
+		return invalid(fmt.Sprintf("%v is compared with in or not in, and only it", LocalTime))
+	}
+
+	switch condition.Operator {
+	case message.OperatorEq:
+		if condition.Value == "" {
+			return invalid("eq needs a value")
+		}
+	case message.OperatorGt, message.OperatorLt:
+		if _, err := strconv.Atoi(condition.Value); err != nil {
+			return invalid(fmt.Sprintf("%v needs an integer value", condition.Operator))
+		}
+	case message.OperatorDx:
+		if condition.Value != "" {
+			return invalid("dx has no value")
+		}
+	case message.OperatorDdx, message.OperatorStable, message.OperatorNotStable:
+		if p, err := schedules.ParsePattern(condition.Value); err != nil || p.Kind != schedules.Timer {
+			return invalid(fmt.Sprintf("%v needs a duration, e.g. PT00:00:30", condition.Operator))
+		}
+	case message.OperatorIn, message.OperatorNotIn:
+		match := intervalRegexp.FindStringSubmatch(condition.Value)
+		if match == nil || !withinDay(match[1]) || !withinDay(match[2]) {
+			return invalid(fmt.Sprintf("%v needs a time interval, e.g. T20:00:00/T08:00:00", condition.Operator))
+		}
+	default:
+		return invalid("unknown operator")
+	}
+	return nil
+}
+
+// withinDay returns whether hms, formatted as hh:mm:ss, is a time of day.
+func withinDay(hms string) bool {
+	h, _ := strconv.Atoi(hms[0:2])
+	m, _ := strconv.Atoi(hms[3:5])
+	s, _ := strconv.Atoi(hms[6:8])
+	return h < 24 && m < 60 && s < 60
+}
+
+// checkAction checks that an action uses a method the bridge accepts.
+func checkAction(action message.Command) (err error) {
+	if action.Method != "PUT" && action.Method != "POST" && action.Method != "DELETE" {
+		return &InvalidActionError{Action: action, Reason: "method must be PUT, POST or DELETE"}
+	}
+	return nil
+}
+
+// Validate checks a rule with Check and checks that the resources its conditions and actions address exist in
+// datastore, and that the bridge has room for another rule.
+func Validate(rule message.NewRule, datastore *message.Datastore) (err error) {
+	if err = Check(rule); err != nil {
+		return err
+	}
+	if len(datastore.Rules) >= MaxRules {
+		return &InvalidRuleError{Name: rule.Name, Reason: fmt.Sprintf("the bridge already holds %v rules", MaxRules)}
+	}
+	if err = validateConditions(rule.Conditions, datastore); err != nil {
+		return err
+	}
+	return validateActions(rule.Actions, datastore)
+}
+
+// validateConditions checks that the attributes the conditions address exist in datastore.
+func validateConditions(conditions []message.Condition, datastore *message.Datastore) (err error) {
+	for _, condition := range conditions {
+		invalid := func(reason string) error {
+			return &InvalidConditionError{Condition: condition, Reason: reason}
+		}
+		switch address := condition.Address; {
+		case address == LocalTime:
+		case sensorAttributeRegexp.MatchString(address):
+			match := sensorAttributeRegexp.FindStringSubmatch(address)
+			sensor, ok := datastore.Sensors[match[1]]
+			if !ok {
+				return invalid(fmt.Sprintf("sensor %v does not exist", match[1]))
+			}
+			if match[2] == "state" {
+				state := map[string]interface{}{}
+				if err := json.Unmarshal(sensor.State, &state); err == nil {
+					if _, ok := state[match[3]]; !ok {
+						return invalid(fmt.Sprintf("a %v sensor has no state attribute %v", sensor.Type, match[3]))
+					}
+				}
+			}
+		case groupAttributeRegexp.MatchString(address):
+			id := groupAttributeRegexp.FindStringSubmatch(address)[1]
+			if _, ok := datastore.Groups[id]; !ok && id != "0" {
+				return invalid(fmt.Sprintf("group %v does not exist", id))
+			}
+		case lightAttributeRegexp.MatchString(address):
+			id := lightAttributeRegexp.FindStringSubmatch(address)[1]
+			if _, ok := datastore.Lights[id]; !ok {
+				return invalid(fmt.Sprintf("light %v does not exist", id))
+			}
+		default:
+			return invalid("not the address of a sensor, group or light attribute, or " + LocalTime)
+		}
+	}
+	return nil
+}
+
+// validateActions checks that the resources the actions address exist in datastore.
+func validateActions(actions []message.Command, datastore *message.Datastore) (err error) {
+	for _, action := range actions {
+		invalid := func(reason string) error {
+			return &InvalidActionError{Action: action, Reason: reason}
+		}
+		switch address := action.Address; {
+		case lightStateRegexp.MatchString(address):
+			id := lightStateRegexp.FindStringSubmatch(address)[1]
+			if _, ok := datastore.Lights[id]; !ok {
+				return invalid(fmt.Sprintf("light %v does not exist", id))
+			}
+		case groupActionRegexp.MatchString(address):
+			id := groupActionRegexp.FindStringSubmatch(address)[1]
+			if _, ok := datastore.Groups[id]; !ok && id != "0" {
+				return invalid(fmt.Sprintf("group %v does not exist", id))
+			}
+			if scene, ok := sceneOf(action.Body); ok {
+				if _, ok := datastore.Scenes[scene]; !ok {
+					return invalid(fmt.Sprintf("scene %v does not exist", scene))
+				}
+			}
+		case sceneRegexp.MatchString(address):
+			match := sceneRegexp.FindStringSubmatch(address)
+			scene, ok := datastore.Scenes[match[1]]
+			if !ok {
+				return invalid(fmt.Sprintf("scene %v does not exist", match[1]))
+			}
+			if match[2] != "" && !contains(scene.Lights, match[2]) {
+				return invalid(fmt.Sprintf("light %v is not in scene %v", match[2], match[1]))
+			}
+		case sensorRegexp.MatchString(address):
+			id := sensorRegexp.FindStringSubmatch(address)[1]
+			if _, ok := datastore.Sensors[id]; !ok {
+				return invalid(fmt.Sprintf("sensor %v does not exist", id))
+			}
+		case scheduleRegexp.MatchString(address):
+			id := scheduleRegexp.FindStringSubmatch(address)[1]
+			if _, ok := datastore.Schedules[id]; !ok {
+				return invalid(fmt.Sprintf("schedule %v does not exist", id))
+			}
+		default:
+			return invalid("not the address of a light, group, scene, sensor or schedule")
+		}
+	}
+	return nil
+}
+
+// sceneOf returns the scene a group action recalls, if any.
+func sceneOf(body interface{}) (scene string, ok bool) {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return "", false
+	}
+	action := struct {
+		Scene string `json:"scene"`
+	}{}
+	if json.Unmarshal(encoded, &action) != nil || action.Scene == "" {
+		return "", false
+	}
+	return action.Scene, true
+}
+
+// contains returns whether ids holds id.
+func contains(ids []string, id string) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
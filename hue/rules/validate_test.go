@@ -0,0 +1,152 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue/message"
+)
+
+// conditions returns n conditions that are each true when a button is pressed.
+func conditions(n int) (c []message.Condition) {
+	for i := 0; i < n; i++ {
+		c = append(c, Dx(SensorState("2", "lastupdated")))
+	}
+	return c
+}
+
+// actions returns n actions that each turn on a light.
+func actions(n int) (a []message.Command) {
+	on := true
+	for i := 0; i < n; i++ {
+		a = append(a, SetLight("1", message.LightAction{On: &on}))
+	}
+	return a
+}
+
+func TestCheckLimits(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		rule message.NewRule
+		ok   bool
+	}{
+		{"limits", message.NewRule{Name: strings.Repeat("a", MaxNameLength), Conditions: conditions(MaxConditions),
+			Actions: actions(MaxActions)}, true},
+		{"long name", message.NewRule{Name: strings.Repeat("a", MaxNameLength+1), Conditions: conditions(1),
+			Actions: actions(1)}, false},
+		// The limit counts characters, not bytes.
+		{"multibyte name", message.NewRule{Name: strings.Repeat("é", MaxNameLength), Conditions: conditions(1),
+			Actions: actions(1)}, true},
+		{"no conditions", message.NewRule{Name: "a", Actions: actions(1)}, false},
+		{"too many conditions", message.NewRule{Name: "a", Conditions: conditions(MaxConditions + 1),
+			Actions: actions(1)}, false},
+		{"no actions", message.NewRule{Name: "a", Conditions: conditions(1)}, false},
+		{"too many actions", message.NewRule{Name: "a", Conditions: conditions(1),
+			Actions: actions(MaxActions + 1)}, false},
+	} {
+		err := Check(tc.rule)
+		if _, invalid := err.(*InvalidRuleError); tc.ok && err != nil || !tc.ok && !invalid {
+			t.Errorf("%v: Check() = %v, want ok %v", tc.name, err, tc.ok)
+		}
+	}
+}
+
+func TestCheckCondition(t *testing.T) {
+	button := SensorState("2", "buttonevent")
+	for _, tc := range []struct {
+		condition message.Condition
+		ok        bool
+	}{
+		{Eq(button, 1002), true},
+		{Eq(button, ""), false},
+		{Gt(button, -1), true},
+		{message.Condition{Address: button, Operator: message.OperatorLt, Value: "1.5"}, false},
+		{Dx(button), true},
+		{message.Condition{Address: button, Operator: message.OperatorDx, Value: "1"}, false},
+		{Ddx(button, 30*time.Second), true},
+		{Stable(button, 23*time.Hour+59*time.Minute+59*time.Second), true},
+		{NotStable(button, time.Minute), true},
+		{message.Condition{Address: button, Operator: message.OperatorDdx, Value: "R/PT00:00:30"}, false},
+		// Durations of a day or more cannot be written, so their value is empty.
+		{Ddx(button, 24*time.Hour), false},
+		{Stable(button, 25*time.Hour), false},
+		{In(20*time.Hour, 8*time.Hour), true},
+		{NotIn(0, 23*time.Hour+59*time.Minute+59*time.Second), true},
+		{message.Condition{Address: LocalTime, Operator: message.OperatorIn, Value: "W124/T08:00:00/T17:00:00"}, true},
+		{message.Condition{Address: LocalTime, Operator: message.OperatorIn, Value: "T08:00/T17:00"}, false},
+		{In(25*time.Hour, 8*time.Hour), false},
+		{NotIn(20*time.Hour, 24*time.Hour), false},
+		{message.Condition{Address: LocalTime, Operator: message.OperatorNotIn, Value: "T08:60:00/T17:00:00"}, false},
+		// Only the local time is compared with in and not in.
+		{Eq(LocalTime, "T08:00:00"), false},
+		{message.Condition{Address: button, Operator: message.OperatorIn, Value: "T08:00:00/T17:00:00"}, false},
+		{message.Condition{Address: button, Operator: "ne", Value: "1"}, false},
+	} {
+		err := checkCondition(tc.condition)
+		if _, invalid := err.(*InvalidConditionError); tc.ok && err != nil || !tc.ok && !invalid {
+			t.Errorf("checkCondition(%+v) = %v, want ok %v", tc.condition, err, tc.ok)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	datastore := &message.Datastore{
+		Lights: map[string]message.Light{"1": {}, "2": {}},
+		Groups: map[string]message.Group{"1": {}},
+		Scenes: map[string]message.Scene{"abc": {Lights: []string{"1"}}},
+		Sensors: map[string]message.Sensor{
+			"2": {Type: "ZLLSwitch", State: json.RawMessage(`{"buttonevent":1002,"lastupdated":"none"}`)},
+		},
+		Schedules: map[string]message.Schedule{"1": {}},
+		Rules:     map[string]message.Rule{},
+	}
+	on := true
+	for _, tc := range []struct {
+		name      string
+		condition message.Condition
+		action    message.Command
+		ok        bool
+	}{
+		{"existing resources", Eq(SensorState("2", "buttonevent"), 1002), RecallScene("1", "abc"), true},
+		// Group 0 holds every light and is not listed in the datastore.
+		{"group 0", Eq(GroupState("0", "any_on"), true), SetGroup("0", message.LightAction{On: &on}), true},
+		{"local time", In(20*time.Hour, 8*time.Hour), SetSceneLight("abc", "1", message.SceneLightState{}), true},
+		{"missing sensor", Dx(SensorState("3", "lastupdated")), SetLight("1", message.LightAction{}), false},
+		{"missing state attribute", Eq(SensorState("2", "presence"), true), SetLight("1", message.LightAction{}),
+			false},
+		{"missing group", Eq(GroupState("2", "all_on"), true), SetLight("1", message.LightAction{}), false},
+		{"missing light", Eq(LightState("3", "on"), true), SetLight("1", message.LightAction{}), false},
+		{"unknown address", Eq("/config/name", "bridge"), SetLight("1", message.LightAction{}), false},
+		{"missing light action", Dx(SensorState("2", "lastupdated")), SetLight("3", message.LightAction{}), false},
+		{"missing group action", Dx(SensorState("2", "lastupdated")), SetGroup("2", message.LightAction{}), false},
+		{"missing scene", Dx(SensorState("2", "lastupdated")), RecallScene("0", "def"), false},
+		{"light not in scene", Dx(SensorState("2", "lastupdated")),
+			SetSceneLight("abc", "2", message.SceneLightState{}), false},
+		{"sensor action", Dx(SensorState("2", "lastupdated")), SetFlag("2", true), true},
+		{"missing sensor action", Dx(SensorState("2", "lastupdated")), SetFlag("3", true), false},
+		{"missing schedule", Dx(SensorState("2", "lastupdated")), Put("/schedules/2", nil), false},
+	} {
+		rule := message.NewRule{Name: tc.name, Conditions: []message.Condition{tc.condition},
+			Actions: []message.Command{tc.action}}
+		err := Validate(rule, datastore)
+		_, invalidCondition := err.(*InvalidConditionError)
+		_, invalidAction := err.(*InvalidActionError)
+		if tc.ok && err != nil || !tc.ok && !invalidCondition && !invalidAction {
+			t.Errorf("%v: Validate() = %v, want ok %v", tc.name, err, tc.ok)
+		}
+	}
+
+	// The bridge has no room for another rule.
+	for i := 0; i < MaxRules; i++ {
+		datastore.Rules[fmt.Sprint(i)] = message.Rule{}
+	}
+	rule := message.NewRule{Name: "full", Conditions: conditions(1), Actions: actions(1)}
+	if err := Validate(rule, datastore); err == nil {
+		t.Errorf("Validate() with %v rules = nil error, want an *InvalidRuleError", MaxRules)
+	} else if _, ok := err.(*InvalidRuleError); !ok {
+		t.Errorf("Validate() with %v rules = %v, want an *InvalidRuleError", MaxRules, err)
+	}
+}
@@ -0,0 +1,170 @@
+// Package scenes is a library for storing and recalling scenes on a Philips Hue bridge. A scene stores a state for
+// each of its lights, and recalling it through the action of a group changes every light with a single command, the
+// fastest way to make a whole room change at once.
+package scenes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue"
+	"github.com/drombosky/disco-dance-party/hue/internal/resource"
+	"github.com/drombosky/disco-dance-party/hue/logging"
+	"github.com/drombosky/disco-dance-party/hue/message"
+)
+
+// Client represents a client to store and recall scenes via the Philips Hue bridge. It is safe for concurrent use by
+// multiple goroutines if its hue.Client is.
+type Client struct {
+	client *resource.Client
+}
+
+// NewClient takes a *hue.Client and returns a client for interacting with scenes.
+func NewClient(hueClient hue.Client, opts ...Option) (client *Client, err error) {
+	o := &resource.Options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &Client{client: resource.NewClient(hueClient, o.Logger, "github.com/drombosky/disco-dance-party/hue/scenes",
+		logging.Scene)}, nil
+}
+
+// GetAll gets a list of all scenes stored on the Philips Hue bridge. The light states of the scenes are not included;
+// use Get to read them.
+func (c *Client) GetAll() (resp map[string]message.Scene, err error) {
+	return c.GetAllContext(context.Background())
+}
+
+// GetAllContext is like GetAll, but the request is canceled if ctx is done before it completes.
+func (c *Client) GetAllContext(ctx context.Context) (resp map[string]message.Scene, err error) {
+	start := time.Now()
+	resp = map[string]message.Scene{}
+	err = c.client.DoContext(ctx, "GET", "/api/<username>/scenes", nil, &resp)
+	c.client.Log("(c *Client) GetAllContext", "", start, err, "Get all")
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Get gets the attributes of a given scene and the state it stores for each of its lights.
+func (c *Client) Get(id string) (resp *message.Scene, err error) {
+	return c.GetContext(context.Background(), id)
+}
+
+// GetContext is like Get, but the request is canceled if ctx is done before it completes.
+func (c *Client) GetContext(ctx context.Context, id string) (resp *message.Scene, err error) {
+	start := time.Now()
+	resp = &message.Scene{}
+	err = c.client.DoContext(ctx, "GET", fmt.Sprintf("/api/<username>/scenes/%v", id), nil, resp)
+	c.client.Log("(c *Client) GetContext", id, start, err, "Get %v", id)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Create creates a scene and returns its ID. The scene stores the given light states, or the current state of each of
+// its lights if none are given.
+func (c *Client) Create(scene message.NewScene) (id string, err error) {
+	return c.CreateContext(context.Background(), scene)
+}
+
+// CreateContext is like Create, but the request is canceled if ctx is done before it completes.
+func (c *Client) CreateContext(ctx context.Context, scene message.NewScene) (id string, err error) {
+	return c.client.Create(ctx, "/api/<username>/scenes", scene)
+}
+
+// Update changes the name or lights of a scene. If StoreLightState is set the current state of each light in the scene
+// is stored, replacing the stored states.
+func (c *Client) Update(id string, attributes message.SceneAttributes) (err error) {
+	return c.UpdateContext(context.Background(), id, attributes)
+}
+
+// UpdateContext is like Update, but the request is canceled if ctx is done before it completes.
+func (c *Client) UpdateContext(ctx context.Context, id string, attributes message.SceneAttributes) (err error) {
+	body, err := json.Marshal(attributes)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err = c.client.DoContext(ctx, "PUT", fmt.Sprintf("/api/<username>/scenes/%v", id), body, nil)
+	c.client.Log("(c *Client) UpdateContext", id, start, err, "Update %v to %v", id, string(body))
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetLightState changes the state a scene stores for one of its lights. The light does not change until the scene is
+// recalled.
+func (c *Client) SetLightState(id, lightID string, state message.SceneLightState) (err error) {
+	return c.SetLightStateContext(context.Background(), id, lightID, state)
+}
+
+// SetLightStateContext is like SetLightState, but the request is canceled if ctx is done before it completes.
+func (c *Client) SetLightStateContext(ctx context.Context, id, lightID string,
+	state message.SceneLightState) (err error) {
+	body, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	address := fmt.Sprintf("/api/<username>/scenes/%v/lightstates/%v", id, lightID)
+	err = c.client.DoContext(ctx, "PUT", address, body, nil)
+	c.client.Log("(c *Client) SetLightStateContext", id, start, err, "Set state of %v in %v to %v", lightID, id,
+		string(body))
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Delete deletes a scene from the Philips Hue bridge. Scenes used by a rule or schedule are locked and cannot be
+// deleted.
+func (c *Client) Delete(id string) (err error) {
+	return c.DeleteContext(context.Background(), id)
+}
+
+// DeleteContext is like Delete, but the request is canceled if ctx is done before it completes.
+func (c *Client) DeleteContext(ctx context.Context, id string) (err error) {
+	start := time.Now()
+	err = c.client.DoContext(ctx, "DELETE", fmt.Sprintf("/api/<username>/scenes/%v", id), nil, nil)
+	c.client.Log("(c *Client) DeleteContext", id, start, err, "Delete %v", id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Recall sets the lights of a scene to their stored states with a single command to the action of group. Only the
+// lights of the scene that are also in the group change. If group is empty group 0 is used, which holds every light.
+func (c *Client) Recall(id, group string) (err error) {
+	return c.RecallContext(context.Background(), id, group)
+}
+
+// RecallContext is like Recall, but the request is canceled if ctx is done before it completes.
+func (c *Client) RecallContext(ctx context.Context, id, group string) (err error) {
+	if group == "" {
+		group = "0"
+	}
+	type Body struct {
+		Scene string `json:"scene"`
+	}
+	body, err := json.Marshal(Body{Scene: id})
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err = c.client.DoContext(ctx, "PUT", fmt.Sprintf("/api/<username>/groups/%v/action", group), body, nil)
+	c.client.Log("(c *Client) RecallContext", id, start, err, "Recall %v in group %v", id, group)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package scenes
+
+import (
+	"testing"
+
+	"github.com/drombosky/disco-dance-party/hue/dryrun"
+	"github.com/drombosky/disco-dance-party/hue/message"
+	"github.com/drombosky/disco-dance-party/hue/middleware"
+)
+
+func TestLightStatesOnlySendSetFields(t *testing.T) {
+	bridge, err := dryrun.NewClient(map[string]message.Light{"1": {Name: "Lamp"}, "2": {Name: "Strip"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bodies := []string{}
+	recorder := middleware.RoundTripperFunc(func(req *middleware.Request) *middleware.Response {
+		bodies = append(bodies, string(req.Message))
+		return &middleware.Response{Err: bridge.DoContext(req.Context, req.Method, req.Address, req.Message, req.Resp)}
+	})
+	c, err := NewClient(recorder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	on, bri := true, 100
+	id, err := c.Create(message.NewScene{Name: "Relax", Lights: []string{"1", "2"},
+		LightStates: map[string]message.SceneLightState{"1": {On: &on, Bri: &bri}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = c.SetLightState(id, "2", message.SceneLightState{Xy: &[2]float64{0.3, 0.4}}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`{"name":"Relax","lights":["1","2"],"recycle":false,"lightstates":{"1":{"on":true,"bri":100}}}`,
+		`{"xy":[0.3,0.4]}`,
+	}
+	for i, body := range want {
+		if i >= len(bodies) || bodies[i] != body {
+			t.Errorf("request %v = %v, want %v", i, bodies, body)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package schedules
+
+import (
+	"github.com/drombosky/disco-dance-party/hue/internal/resource"
+	"github.com/drombosky/disco-dance-party/hue/logging"
+)
+
+// Option represents a setting of a client created by NewClient.
+type Option func(o *resource.Options)
+
+// WithLogger sets the logger the client logs to. Defaults to logging nothing.
+func WithLogger(logger logging.Logger) Option {
+	return resource.WithLogger(logger)
+}
+
+// WithUsername sets the username that replaces the placeholder <username> in the address of the command of a schedule,
+// e.g. /api/<username>/groups/1/action. Defaults to the username of the hue.Client if it is a *client.Client; it must
+// be set if the client is wrapped, e.g. by middleware.
+func WithUsername(username string) Option {
+	return resource.WithUsername(username)
+}
@@ -0,0 +1,279 @@
+package schedules
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// InvalidPatternError represents an error when a string is not a time pattern the bridge understands.
+type InvalidPatternError struct {
+	Pattern string
+	Reason  string
+}
+
+// Error satisfies the error interface.
+func (e *InvalidPatternError) Error() string {
+	return fmt.Sprintf("Invalid time pattern %v: %v", e.Pattern, e.Reason)
+}
+
+// Weekdays represents a set of days of the week, encoded the way the bridge does as the bits 0MTWTFSS.
+type Weekdays uint8
+
+// Days of the week.
+const (
+	Sunday Weekdays = 1 << iota
+	Saturday
+	Friday
+	Thursday
+	Wednesday
+	Tuesday
+	Monday
+)
+
+// Common sets of days.
+const (
+	Workdays = Monday | Tuesday | Wednesday | Thursday | Friday
+	Weekend  = Saturday | Sunday
+	Everyday = Workdays | Weekend
+)
+
+// WeekdaysOf returns the set holding the given days.
+func WeekdaysOf(days ...time.Weekday) (w Weekdays) {
+	for _, day := range days {
+		w |= weekday(day)
+	}
+	return w
+}
+
+// Contains returns whether day is in the set.
+func (w Weekdays) Contains(day time.Weekday) bool {
+	return w&weekday(day) != 0
+}
+
+// Days returns the days in the set, starting with Sunday like time.Weekday.
+func (w Weekdays) Days() (days []time.Weekday) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if w.Contains(day) {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// weekday returns the bit of a day.
+func weekday(day time.Weekday) Weekdays {
+	return 1 << uint((7-day)%7)
+}
+
+// Kind represents when a time pattern fires.
+type Kind int
+
+const (
+	// Absolute patterns fire once at a date and time, e.g. 2017-06-01T20:00:00.
+	Absolute Kind = iota
+	// Recurring patterns fire at a time of day on the given days of every week, e.g. W124/T20:00:00.
+	Recurring
+	// Timer patterns fire once a duration after the schedule is created or enabled, e.g. PT00:10:00.
+	Timer
+	// RecurringTimer patterns fire every duration, a number of times or forever, e.g. R05/PT00:10:00.
+	RecurringTimer
+)
+
+// TimePattern represents when a schedule fires. Any kind of pattern can be randomized, in which case it fires at a
+// random moment up to Random after the time it would otherwise fire.
+type TimePattern struct {
+	// Kind of pattern.
+	Kind Kind
+	// Time is the date and time of an Absolute pattern. Its wall clock is used as the time in the time zone of the
+	// bridge; its location is ignored.
+	Time time.Time
+	// Weekdays are the days of the week a Recurring pattern fires on.
+	Weekdays Weekdays
+	// TimeOfDay is the time since midnight a Recurring pattern fires at.
+	TimeOfDay time.Duration
+	// Duration is the time a Timer or RecurringTimer pattern waits.
+	Duration time.Duration
+	// Repeat is the number of times a RecurringTimer pattern fires, at most 99. Zero means forever.
+	Repeat int
+	// Random is the length of the window the pattern fires in at random. Zero means the pattern is not randomized.
+	Random time.Duration
+}
+
+// At returns a pattern that fires once at the wall clock time of t, e.g. 2017-06-01T20:00:00.
+func At(t time.Time) TimePattern {
+	return TimePattern{Kind: Absolute, Time: t}
+}
+
+// Weekly returns a pattern that fires on the given days at timeOfDay since midnight, e.g. Weekly(Workdays,
+// 20*time.Hour) is W124/T20:00:00.
+func Weekly(days Weekdays, timeOfDay time.Duration) TimePattern {
+	return TimePattern{Kind: Recurring, Weekdays: days, TimeOfDay: timeOfDay}
+}
+
+// After returns a pattern that fires once d after the schedule is created or enabled, e.g. PT00:10:00.
+func After(d time.Duration) TimePattern {
+	return TimePattern{Kind: Timer, Duration: d}
+}
+
+// Every returns a pattern that fires every d, times times or forever if times is zero, e.g. R05/PT00:10:00.
+func Every(d time.Duration, times int) TimePattern {
+	return TimePattern{Kind: RecurringTimer, Duration: d, Repeat: times}
+}
+
+// Randomized returns a copy of p that fires at a random moment up to window after the time p fires.
+func (p TimePattern) Randomized(window time.Duration) TimePattern {
+	p.Random = window
+	return p
+}
+
+// String returns the pattern as the bridge writes it, e.g. W124/T20:00:00, or an empty string if the bridge would not
+// accept it; MarshalText reports why.
+func (p TimePattern) String() string {
+	text, err := p.MarshalText()
+	if err != nil {
+		return ""
+	}
+	return string(text)
+}
+
+// MarshalText satisfies the encoding.TextMarshaler interface. It returns an *InvalidPatternError if the pattern has no
+// valid kind, a Recurring pattern has no weekdays, a RecurringTimer pattern repeats more than 99 times, or a time of
+// day or duration is negative or not less than 24 hours, which the bridge cannot express.
+func (p TimePattern) MarshalText() ([]byte, error) {
+	var s string
+	switch p.Kind {
+	case Absolute:
+		s = p.Time.Format(dateTimeLayout)
+	case Recurring:
+		if p.Weekdays < 1 || p.Weekdays > Everyday {
+			return nil, p.invalid("weekdays must be between 1 and 127")
+		}
+		if !withinDay(p.TimeOfDay) {
+			return nil, p.invalid(fmt.Sprintf("time of day %v is not within a day", p.TimeOfDay))
+		}
+		s = fmt.Sprintf("W%d/T%v", p.Weekdays, clock(p.TimeOfDay))
+	case Timer, RecurringTimer:
+		if !withinDay(p.Duration) {
+			return nil, p.invalid(fmt.Sprintf("duration %v is not within a day", p.Duration))
+		}
+		s = "PT" + clock(p.Duration)
+		if p.Kind == RecurringTimer {
+			if p.Repeat < 0 || p.Repeat > 99 {
+				return nil, p.invalid("repeat must be between 1 and 99, or 0 for forever")
+			}
+			if p.Repeat > 0 {
+				s = fmt.Sprintf("R%02d/%v", p.Repeat, s)
+			} else {
+				s = "R/" + s
+			}
+		}
+	default:
+		return nil, p.invalid(fmt.Sprintf("unknown kind %v", p.Kind))
+	}
+	if p.Random != 0 {
+		if !withinDay(p.Random) {
+			return nil, p.invalid(fmt.Sprintf("random window %v is not within a day", p.Random))
+		}
+		s += "A" + clock(p.Random)
+	}
+	return []byte(s), nil
+}
+
+// invalid returns an *InvalidPatternError for p, which is described by its fields since it has no text.
+func (p TimePattern) invalid(reason string) *InvalidPatternError {
+	type fields TimePattern
+	return &InvalidPatternError{Pattern: fmt.Sprintf("%+v", fields(p)), Reason: reason}
+}
+
+// withinDay returns whether d can be written as hh:mm:ss.
+func withinDay(d time.Duration) bool {
+	return d >= 0 && d < 24*time.Hour
+}
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface.
+func (p *TimePattern) UnmarshalText(text []byte) (err error) {
+	*p, err = ParsePattern(string(text))
+	return err
+}
+
+// dateTimeLayout is the layout of the date and time of absolute patterns.
+const dateTimeLayout = "2006-01-02T15:04:05"
+
+var (
+	// absoluteRegexp matches absolute patterns.
+	absoluteRegexp = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2})(?:A(\d{2}:\d{2}:\d{2}))?$`)
+	// recurringRegexp matches recurring patterns.
+	recurringRegexp = regexp.MustCompile(`^W(\d{1,3})/T(\d{2}:\d{2}:\d{2})(?:A(\d{2}:\d{2}:\d{2}))?$`)
+	// timerRegexp matches timer and recurring timer patterns.
+	timerRegexp = regexp.MustCompile(`^(R(\d{2})?/)?PT(\d{2}:\d{2}:\d{2})(?:A(\d{2}:\d{2}:\d{2}))?$`)
+)
+
+// ParsePattern parses a time pattern as the bridge writes it. The date and time of absolute patterns are returned in
+// the local time zone.
+func ParsePattern(s string) (p TimePattern, err error) {
+	var random string
+	switch {
+	case absoluteRegexp.MatchString(s):
+		match := absoluteRegexp.FindStringSubmatch(s)
+		p.Kind = Absolute
+		if p.Time, err = time.ParseInLocation(dateTimeLayout, match[1], time.Local); err != nil {
+			return p, &InvalidPatternError{Pattern: s, Reason: err.Error()}
+		}
+		random = match[2]
+	case recurringRegexp.MatchString(s):
+		match := recurringRegexp.FindStringSubmatch(s)
+		p.Kind = Recurring
+		days, _ := strconv.Atoi(match[1])
+		if days < 1 || days > int(Everyday) {
+			return p, &InvalidPatternError{Pattern: s, Reason: "weekdays must be between 1 and 127"}
+		}
+		p.Weekdays = Weekdays(days)
+		if p.TimeOfDay, err = parseClock(s, match[2]); err != nil {
+			return p, err
+		}
+		random = match[3]
+	case timerRegexp.MatchString(s):
+		match := timerRegexp.FindStringSubmatch(s)
+		p.Kind = Timer
+		if match[1] != "" {
+			p.Kind = RecurringTimer
+			if match[2] != "" {
+				p.Repeat, _ = strconv.Atoi(match[2])
+				if p.Repeat == 0 {
+					return p, &InvalidPatternError{Pattern: s, Reason: "repeat must be between 1 and 99"}
+				}
+			}
+		}
+		if p.Duration, err = parseClock(s, match[3]); err != nil {
+			return p, err
+		}
+		random = match[4]
+	default:
+		return p, &InvalidPatternError{Pattern: s, Reason: "not an absolute, recurring, timer or recurring timer pattern"}
+	}
+	if random != "" {
+		if p.Random, err = parseClock(s, random); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+// clock formats d as hh:mm:ss, dropping fractions of a second.
+func clock(d time.Duration) string {
+	d = d / time.Second
+	return fmt.Sprintf("%02d:%02d:%02d", d/3600, d/60%60, d%60)
+}
+
+// parseClock parses hh:mm:ss in pattern s as a duration.
+func parseClock(s, hms string) (d time.Duration, err error) {
+	h, _ := strconv.Atoi(hms[0:2])
+	m, _ := strconv.Atoi(hms[3:5])
+	sec, _ := strconv.Atoi(hms[6:8])
+	if h > 23 || m > 59 || sec > 59 {
+		return 0, &InvalidPatternError{Pattern: s, Reason: fmt.Sprintf("%v is not a valid time", hms)}
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(sec)*time.Second, nil
+}
@@ -0,0 +1,72 @@
+package schedules
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestPatternRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		pattern TimePattern
+		text    string
+	}{
+		{At(time.Date(2017, 6, 1, 20, 0, 0, 0, time.Local)), "2017-06-01T20:00:00"},
+		{At(time.Date(2017, 6, 1, 20, 0, 0, 0, time.Local)).Randomized(15 * time.Minute), "2017-06-01T20:00:00A00:15:00"},
+		{Weekly(Workdays, 20*time.Hour), "W124/T20:00:00"},
+		{Weekly(Weekend, 9*time.Hour+30*time.Minute).Randomized(time.Hour), "W3/T09:30:00A01:00:00"},
+		{After(10 * time.Minute), "PT00:10:00"},
+		{Every(10*time.Minute, 5), "R05/PT00:10:00"},
+		{Every(time.Hour, 0), "R/PT01:00:00"},
+		{Every(30*time.Second, 99).Randomized(5 * time.Second), "R99/PT00:00:30A00:00:05"},
+	} {
+		if s := tc.pattern.String(); s != tc.text {
+			t.Errorf("%+v.String() = %q, want %q", tc.pattern, s, tc.text)
+		}
+		p, err := ParsePattern(tc.text)
+		if err != nil {
+			t.Errorf("ParsePattern(%q) = %v", tc.text, err)
+			continue
+		}
+		if !p.Time.Equal(tc.pattern.Time) {
+			t.Errorf("ParsePattern(%q).Time = %v, want %v", tc.text, p.Time, tc.pattern.Time)
+		}
+		p.Time = tc.pattern.Time
+		if p != tc.pattern {
+			t.Errorf("ParsePattern(%q) = %+v, want %+v", tc.text, p, tc.pattern)
+		}
+		encoded, err := json.Marshal(p)
+		if err != nil || string(encoded) != `"`+tc.text+`"` {
+			t.Errorf("json.Marshal(%q) = %s, %v", tc.text, encoded, err)
+		}
+	}
+}
+
+func TestInvalidPattern(t *testing.T) {
+	for _, p := range []TimePattern{
+		Every(time.Minute, 100),
+		Every(time.Minute, -1),
+		Weekly(0, 20*time.Hour),
+		Weekly(Workdays, 24*time.Hour),
+		After(24 * time.Hour),
+		After(-time.Second),
+		Every(25*time.Hour, 0),
+		After(time.Minute).Randomized(24 * time.Hour),
+		{Kind: Kind(7)},
+	} {
+		if s := p.String(); s != "" {
+			t.Errorf("%+v.String() = %q, want an empty string", p, s)
+		}
+		if _, err := p.MarshalText(); err == nil {
+			t.Errorf("%+v.MarshalText() = nil error, want *InvalidPatternError", p)
+		} else if _, ok := err.(*InvalidPatternError); !ok {
+			t.Errorf("%+v.MarshalText() = %T, want *InvalidPatternError", p, err)
+		}
+	}
+
+	for _, s := range []string{"R00/PT00:10:00", "W0/T20:00:00", "W128/T20:00:00", "PT24:00:00", "T20:00:00", ""} {
+		if _, err := ParsePattern(s); err == nil {
+			t.Errorf("ParsePattern(%q) = nil error, want *InvalidPatternError", s)
+		}
+	}
+}
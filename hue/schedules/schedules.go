@@ -0,0 +1,132 @@
+// Package schedules is a library for the schedules of a Philips Hue bridge, commands the bridge sends at a given time
+// whether or not the application that created them is running. When a schedule fires is given by a time pattern in the
+// time zone of the bridge: once at a date and time, weekly on some days, or after a timer. TimePattern converts
+// between Go types and the patterns the bridge uses, e.g.
+//
+//	schedules.Weekly(schedules.Workdays, 20*time.Hour).String() == "W124/T20:00:00"
+package schedules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue"
+	"github.com/drombosky/disco-dance-party/hue/internal/resource"
+	"github.com/drombosky/disco-dance-party/hue/logging"
+	"github.com/drombosky/disco-dance-party/hue/message"
+)
+
+// Client represents a client to manage schedules via the Philips Hue bridge. It is safe for concurrent use by multiple
+// goroutines if its hue.Client is.
+type Client struct {
+	client   *resource.Client
+	username string
+}
+
+// NewClient takes a *hue.Client and returns a client for interacting with schedules.
+func NewClient(hueClient hue.Client, opts ...Option) (client *Client, err error) {
+	o := &resource.Options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &Client{client: resource.NewClient(hueClient, o.Logger, "github.com/drombosky/disco-dance-party/hue/schedules",
+		logging.Schedule), username: o.UsernameOf(hueClient)}, nil
+}
+
+// GetAll gets a list of all schedules on the Philips Hue bridge.
+func (c *Client) GetAll() (resp map[string]message.Schedule, err error) {
+	return c.GetAllContext(context.Background())
+}
+
+// GetAllContext is like GetAll, but the request is canceled if ctx is done before it completes.
+func (c *Client) GetAllContext(ctx context.Context) (resp map[string]message.Schedule, err error) {
+	start := time.Now()
+	resp = map[string]message.Schedule{}
+	err = c.client.DoContext(ctx, "GET", "/api/<username>/schedules", nil, &resp)
+	c.client.Log("(c *Client) GetAllContext", "", start, err, "Get all")
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Get gets the attributes of a given schedule.
+func (c *Client) Get(id string) (resp *message.Schedule, err error) {
+	return c.GetContext(context.Background(), id)
+}
+
+// GetContext is like Get, but the request is canceled if ctx is done before it completes.
+func (c *Client) GetContext(ctx context.Context, id string) (resp *message.Schedule, err error) {
+	start := time.Now()
+	resp = &message.Schedule{}
+	err = c.client.DoContext(ctx, "GET", fmt.Sprintf("/api/<username>/schedules/%v", id), nil, resp)
+	c.client.Log("(c *Client) GetContext", id, start, err, "Get %v", id)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Create creates a schedule and returns its ID. The bridge rejects schedules with an absolute time pattern in the past.
+// The placeholder <username> in the address of the command is replaced; see WithUsername.
+func (c *Client) Create(schedule message.NewSchedule) (id string, err error) {
+	return c.CreateContext(context.Background(), schedule)
+}
+
+// CreateContext is like Create, but the request is canceled if ctx is done before it completes.
+func (c *Client) CreateContext(ctx context.Context, schedule message.NewSchedule) (id string, err error) {
+	schedule.Command = c.command(schedule.Command)
+	return c.client.Create(ctx, "/api/<username>/schedules", schedule)
+}
+
+// Update changes the attributes of a schedule. Attributes left empty are not changed. Changing the time pattern or
+// enabling a timer restarts it.
+func (c *Client) Update(id string, attributes message.ScheduleAttributes) (err error) {
+	return c.UpdateContext(context.Background(), id, attributes)
+}
+
+// UpdateContext is like Update, but the request is canceled if ctx is done before it completes.
+func (c *Client) UpdateContext(ctx context.Context, id string, attributes message.ScheduleAttributes) (err error) {
+	if attributes.Command != nil {
+		command := c.command(*attributes.Command)
+		attributes.Command = &command
+	}
+	body, err := json.Marshal(attributes)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err = c.client.DoContext(ctx, "PUT", fmt.Sprintf("/api/<username>/schedules/%v", id), body, nil)
+	c.client.Log("(c *Client) UpdateContext", id, start, err, "Update %v to %v", id, string(body))
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Delete deletes a schedule from the Philips Hue bridge.
+func (c *Client) Delete(id string) (err error) {
+	return c.DeleteContext(context.Background(), id)
+}
+
+// DeleteContext is like Delete, but the request is canceled if ctx is done before it completes.
+func (c *Client) DeleteContext(ctx context.Context, id string) (err error) {
+	start := time.Now()
+	err = c.client.DoContext(ctx, "DELETE", fmt.Sprintf("/api/<username>/schedules/%v", id), nil, nil)
+	c.client.Log("(c *Client) DeleteContext", id, start, err, "Delete %v", id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// command returns command with the placeholder <username> in its address replaced, if the username is known.
+func (c *Client) command(command message.Command) message.Command {
+	if c.username == "" {
+		return command
+	}
+	return command.WithUsername(c.username)
+}
@@ -0,0 +1,75 @@
+package schedules
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/drombosky/disco-dance-party/hue/message"
+	"github.com/drombosky/disco-dance-party/hue/middleware"
+)
+
+// userClient is a hue.Client that reports its username like client.Client and records the bodies it is sent.
+type userClient struct {
+	middleware.RoundTripperFunc
+	username string
+}
+
+func (c userClient) Username() string {
+	return c.username
+}
+
+// recorder returns a hue.Client that accepts every request and appends its body to bodies.
+func recorder(bodies *[]string) middleware.RoundTripperFunc {
+	return func(req *middleware.Request) *middleware.Response {
+		*bodies = append(*bodies, string(req.Message))
+		if results, ok := req.Resp.(*[]message.Result); ok {
+			*results = []message.Result{{Success: map[string]json.RawMessage{"id": json.RawMessage(`"1"`)}}}
+		}
+		return &middleware.Response{}
+	}
+}
+
+func TestCommandUsername(t *testing.T) {
+	schedule := message.NewSchedule{
+		Name:        "Lights out",
+		Description: "Sent as <username>",
+		Command: message.Command{Address: "/api/<username>/groups/0/action", Method: "PUT",
+			Body: map[string]interface{}{"on": false}},
+		LocalTime: "W124/T23:00:00",
+	}
+	want := `{"name":"Lights out","description":"Sent as \u003cusername\u003e","command":` +
+		`{"address":"/api/user/groups/0/action","method":"PUT","body":{"on":false}},"localtime":"W124/T23:00:00"}`
+
+	for _, tc := range []struct {
+		name   string
+		client func(bodies *[]string) (*Client, error)
+	}{
+		{"client username", func(bodies *[]string) (*Client, error) {
+			return NewClient(userClient{RoundTripperFunc: recorder(bodies), username: "user"})
+		}},
+		{"WithUsername", func(bodies *[]string) (*Client, error) {
+			return NewClient(recorder(bodies), WithUsername("user"))
+		}},
+	} {
+		bodies := []string{}
+		c, err := tc.client(&bodies)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = c.Create(schedule); err != nil {
+			t.Fatalf("%v: Create() = %v", tc.name, err)
+		}
+		command := schedule.Command
+		if err = c.UpdateContext(context.Background(), "1", message.ScheduleAttributes{Command: &command}); err != nil {
+			t.Fatalf("%v: Update() = %v", tc.name, err)
+		}
+		if len(bodies) != 2 || bodies[0] != want ||
+			bodies[1] != `{"command":{"address":"/api/user/groups/0/action","method":"PUT","body":{"on":false}}}` {
+			t.Errorf("%v: sent %v, want the username in the command address only", tc.name, bodies)
+		}
+		if command.Address != "/api/<username>/groups/0/action" {
+			t.Errorf("%v: Update changed the command of the caller to %v", tc.name, command.Address)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// Package sensors is a library for the sensors of a Philips Hue bridge, such as the Hue dimmer switch and motion
+// sensor, and for CLIP sensors, which applications create to store a flag or status that rules can react to. The state
+// of a sensor depends on its type and is decoded with message.Sensor.DecodeState, e.g.
+//
+//	state := message.SwitchState{}
+//	if sensor.Type == message.SensorTypeZLLSwitch && sensor.DecodeState(&state) == nil {
+//		fmt.Println(state.Button(), state.Event(), state.LastUpdated)
+//	}
+package sensors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/drombosky/disco-dance-party/hue"
+	"github.com/drombosky/disco-dance-party/hue/internal/resource"
+	"github.com/drombosky/disco-dance-party/hue/logging"
+	"github.com/drombosky/disco-dance-party/hue/message"
+)
+
+// GenericFlag returns a CLIPGenericFlag sensor to be created, a boolean that applications and rules can set.
+func GenericFlag(name, uniqueID string) message.NewSensor {
+	return newCLIPSensor(name, uniqueID, message.SensorTypeCLIPGenericFlag)
+}
+
+// GenericStatus returns a CLIPGenericStatus sensor to be created, an integer that applications and rules can set.
+func GenericStatus(name, uniqueID string) message.NewSensor {
+	return newCLIPSensor(name, uniqueID, message.SensorTypeCLIPGenericStatus)
+}
+
+// newCLIPSensor returns a CLIP sensor of the given type to be created.
+func newCLIPSensor(name, uniqueID, sensorType string) message.NewSensor {
+	return message.NewSensor{
+		Name:             name,
+		Type:             sensorType,
+		ModelID:          sensorType,
+		ManufacturerName: "disco-dance-party",
+		SwVersion:        "1.0",
+		UniqueID:         uniqueID,
+	}
+}
+
+// Client represents a client to read and update sensors via the Philips Hue bridge. It is safe for concurrent use by
+// multiple goroutines if its hue.Client is.
+type Client struct {
+	client *resource.Client
+}
+
+// NewClient takes a *hue.Client and returns a client for interacting with sensors.
+func NewClient(hueClient hue.Client, opts ...Option) (client *Client, err error) {
+	o := &resource.Options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &Client{client: resource.NewClient(hueClient, o.Logger, "github.com/drombosky/disco-dance-party/hue/sensors",
+		logging.Sensor)}, nil
+}
+
+// GetAll gets a list of all sensors known to the Philips Hue bridge.
+func (c *Client) GetAll() (resp map[string]message.Sensor, err error) {
+	return c.GetAllContext(context.Background())
+}
+
+// GetAllContext is like GetAll, but the request is canceled if ctx is done before it completes.
+func (c *Client) GetAllContext(ctx context.Context) (resp map[string]message.Sensor, err error) {
+	start := time.Now()
+	resp = map[string]message.Sensor{}
+	err = c.client.DoContext(ctx, "GET", "/api/<username>/sensors", nil, &resp)
+	c.client.Log("(c *Client) GetAllContext", "", start, err, "Get all")
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetNew gets the status of the last search for new sensors. The sensors found are added to GetAll.
+func (c *Client) GetNew() (resp *message.GetNewResp, err error) {
+	return c.GetNewContext(context.Background())
+}
+
+// GetNewContext is like GetNew, but the request is canceled if ctx is done before it completes.
+func (c *Client) GetNewContext(ctx context.Context) (resp *message.GetNewResp, err error) {
+	start := time.Now()
+	resp = &message.GetNewResp{}
+	err = c.client.DoContext(ctx, "GET", "/api/<username>/sensors/new", nil, resp)
+	c.client.Log("(c *Client) GetNewContext", "", start, err, "Get new")
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Search starts a search for new Zigbee sensors, which lasts about 40 seconds. Use GetNew to tell when it is done.
+func (c *Client) Search() (err error) {
+	return c.SearchContext(context.Background())
+}
+
+// SearchContext is like Search, but the request is canceled if ctx is done before it completes.
+func (c *Client) SearchContext(ctx context.Context) (err error) {
+	start := time.Now()
+	err = c.client.DoContext(ctx, "POST", "/api/<username>/sensors", nil, nil)
+	c.client.Log("(c *Client) SearchContext", "", start, err, "Search")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Get gets the attributes, state and configuration of a given sensor.
+func (c *Client) Get(id string) (resp *message.Sensor, err error) {
+	return c.GetContext(context.Background(), id)
+}
+
+// GetContext is like Get, but the request is canceled if ctx is done before it completes.
+func (c *Client) GetContext(ctx context.Context, id string) (resp *message.Sensor, err error) {
+	start := time.Now()
+	resp = &message.Sensor{}
+	err = c.client.DoContext(ctx, "GET", fmt.Sprintf("/api/<username>/sensors/%v", id), nil, resp)
+	c.client.Log("(c *Client) GetContext", id, start, err, "Get %v", id)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Create creates a CLIP sensor, e.g. one returned by GenericFlag, and returns its ID.
+func (c *Client) Create(sensor message.NewSensor) (id string, err error) {
+	return c.CreateContext(context.Background(), sensor)
+}
+
+// CreateContext is like Create, but the request is canceled if ctx is done before it completes.
+func (c *Client) CreateContext(ctx context.Context, sensor message.NewSensor) (id string, err error) {
+	return c.client.Create(ctx, "/api/<username>/sensors", sensor)
+}
+
+// Rename changes the name of a sensor.
+func (c *Client) Rename(id, name string) (err error) {
+	return c.RenameContext(context.Background(), id, name)
+}
+
+// RenameContext is like Rename, but the request is canceled if ctx is done before it completes.
+func (c *Client) RenameContext(ctx context.Context, id, name string) (err error) {
+	type Body struct {
+		Name string `json:"name"`
+	}
+	body, err := json.Marshal(Body{Name: name})
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err = c.client.DoContext(ctx, "PUT", fmt.Sprintf("/api/<username>/sensors/%v", id), body, nil)
+	c.client.Log("(c *Client) RenameContext", id, start, err, "Rename %v to %v", id, name)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetFlag sets the flag of a CLIPGenericFlag sensor.
+func (c *Client) SetFlag(id string, flag bool) (err error) {
+	return c.SetFlagContext(context.Background(), id, flag)
+}
+
+// SetFlagContext is like SetFlag, but the request is canceled if ctx is done before it completes.
+func (c *Client) SetFlagContext(ctx context.Context, id string, flag bool) (err error) {
+	type Body struct {
+		Flag bool `json:"flag"`
+	}
+	return c.setState(ctx, "(c *Client) SetFlagContext", id, Body{Flag: flag})
+}
+
+// SetStatus sets the status of a CLIPGenericStatus sensor.
+func (c *Client) SetStatus(id string, status int) (err error) {
+	return c.SetStatusContext(context.Background(), id, status)
+}
+
+// SetStatusContext is like SetStatus, but the request is canceled if ctx is done before it completes.
+func (c *Client) SetStatusContext(ctx context.Context, id string, status int) (err error) {
+	type Body struct {
+		Status int `json:"status"`
+	}
+	return c.setState(ctx, "(c *Client) SetStatusContext", id, Body{Status: status})
+}
+
+// setState changes the state of a CLIP sensor.
+func (c *Client) setState(ctx context.Context, operation, id string, state interface{}) (err error) {
+	body, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err = c.client.DoContext(ctx, "PUT", fmt.Sprintf("/api/<username>/sensors/%v/state", id), body, nil)
+	c.client.Log(operation, id, start, err, "Set state for %v to %v", id, string(body))
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Delete deletes a sensor from the Philips Hue bridge.
+func (c *Client) Delete(id string) (err error) {
+	return c.DeleteContext(context.Background(), id)
+}
+
+// DeleteContext is like Delete, but the request is canceled if ctx is done before it completes.
+func (c *Client) DeleteContext(ctx context.Context, id string) (err error) {
+	start := time.Now()
+	err = c.client.DoContext(ctx, "DELETE", fmt.Sprintf("/api/<username>/sensors/%v", id), nil, nil)
+	c.client.Log("(c *Client) DeleteContext", id, start, err, "Delete %v", id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package sensors
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/drombosky/disco-dance-party/hue/message"
+	"github.com/drombosky/disco-dance-party/hue/middleware"
+)
+
+// recorder returns a hue.Client that accepts every request and appends its method, address and body to requests.
+func recorder(requests *[]string) middleware.RoundTripperFunc {
+	return func(req *middleware.Request) *middleware.Response {
+		*requests = append(*requests, req.Method+" "+req.Address+" "+string(req.Message))
+		if results, ok := req.Resp.(*[]message.Result); ok {
+			*results = []message.Result{{Success: map[string]json.RawMessage{"id": json.RawMessage(`"1"`)}}}
+		}
+		return &middleware.Response{}
+	}
+}
+
+func TestRequests(t *testing.T) {
+	requests := []string{}
+	c, err := NewClient(recorder(&requests))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name string
+		do   func() error
+	}{
+		{"SetFlag", func() error { return c.SetFlag("1", true) }},
+		{"SetStatus", func() error { return c.SetStatus("2", 3) }},
+		{"Search", c.Search},
+		{"Rename", func() error { return c.Rename("1", "Away") }},
+		{"Create", func() error {
+			id, err := c.Create(GenericFlag("Away", "away-1"))
+			if err == nil && id != "1" {
+				t.Errorf("Create() = %v, want 1", id)
+			}
+			return err
+		}},
+		{"Delete", func() error { return c.Delete("1") }},
+	} {
+		if err = tc.do(); err != nil {
+			t.Errorf("%v() = %v", tc.name, err)
+		}
+	}
+	want := []string{
+		`PUT /api/<username>/sensors/1/state {"flag":true}`,
+		`PUT /api/<username>/sensors/2/state {"status":3}`,
+		`POST /api/<username>/sensors `,
+		`PUT /api/<username>/sensors/1 {"name":"Away"}`,
+		`POST /api/<username>/sensors {"name":"Away","type":"CLIPGenericFlag","modelid":"CLIPGenericFlag",` +
+			`"manufacturername":"disco-dance-party","swversion":"1.0","uniqueid":"away-1"}`,
+		`DELETE /api/<username>/sensors/1 `,
+	}
+	if !reflect.DeepEqual(requests, want) {
+		t.Errorf("sent %q, want %q", requests, want)
+	}
+}